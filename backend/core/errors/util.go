@@ -26,7 +26,7 @@ func Is(err, target error) bool {
 
 // As convenience passthrough for the native errors.As method
 func As(err error, target any) bool {
-	return errors.As(err, &target)
+	return errors.As(err, target)
 }
 
 func Must(err error) {
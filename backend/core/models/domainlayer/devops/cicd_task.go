@@ -46,6 +46,7 @@ type CICDTask struct {
 	OriginalResult    string `gorm:"type:varchar(100)"`
 	Type              string `gorm:"type:varchar(100);comment: to indicate this is CI or CD"`
 	Environment       string `gorm:"type:varchar(255)"`
+	Url               string `gorm:"type:varchar(255)"`
 	DurationSec       float64
 	QueuedDurationSec *float64
 	TaskDatesInfo
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addSkipOnFailToTasks)(nil)
+
+type addSkipOnFailToTasks struct{}
+
+type task20261017 struct {
+	SkipOnFail json.RawMessage `gorm:"type:json"`
+}
+
+func (task20261017) TableName() string {
+	return "_devlake_tasks"
+}
+
+func (script *addSkipOnFailToTasks) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, new(task20261017))
+}
+
+func (*addSkipOnFailToTasks) Version() uint64 {
+	return 20261017094000
+}
+
+func (*addSkipOnFailToTasks) Name() string {
+	return "add skip_on_fail to _devlake_tasks"
+}
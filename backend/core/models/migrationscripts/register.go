@@ -140,5 +140,7 @@ func All() []plugin.MigrationScript {
 		new(extendFieldSizeForCq),
 		new(addIssueFixVerion),
 		new(addPipelinePriority),
+		new(addUrlToCicdTasks),
+		new(addSkipOnFailToTasks),
 	}
 }
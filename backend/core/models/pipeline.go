@@ -27,6 +27,8 @@ type GenericPipelineTask[T any] struct {
 	Plugin   string   `json:"plugin" binding:"required"`
 	Subtasks []string `json:"subtasks"`
 	Options  T        `json:"options"`
+	// SkipOnFail overrides the SkipOnFail of the subtasks by name, the subtasks not listed keep the one of their meta
+	SkipOnFail map[string]bool `json:"skipOnFail,omitempty"`
 }
 
 // PipelineTask represents a smallest unit of execution inside a PipelinePlan
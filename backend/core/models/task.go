@@ -64,6 +64,7 @@ type Task struct {
 	Plugin         string                 `json:"plugin" gorm:"index"`
 	Subtasks       []string               `json:"subtasks" gorm:"type:json;serializer:json"`
 	Options        map[string]interface{} `json:"options" gorm:"serializer:encdec"`
+	SkipOnFail     map[string]bool        `json:"skipOnFail" gorm:"type:json;serializer:json"`
 	Status         string                 `json:"status"`
 	Message        string                 `json:"message"`
 	ErrorName      string                 `json:"errorName"`
@@ -356,7 +356,7 @@ func RunPluginSubTasks(
 				subtaskErrors = append(subtaskErrors, subtaskMeta.Name)
 				
 				// Check if this subtask should cause the entire task to fail
-				if !subtaskMeta.SkipOnFail {
+				if !subtaskSkipOnFail(task, &subtaskMeta) {
 					return err
 				}
 				// Log that we're continuing despite the failure
@@ -376,6 +376,15 @@ func RunPluginSubTasks(
 	return nil
 }
 
+// subtaskSkipOnFail tells if the other subtasks should continue when the subtask fails, the SkipOnFail of the
+// task, i.e. set in the pipeline plan, takes precedence over the one of the meta
+func subtaskSkipOnFail(task *models.Task, subtaskMeta *plugin.SubTaskMeta) bool {
+	if skipOnFail, ok := task.SkipOnFail[subtaskMeta.Name]; ok {
+		return skipOnFail
+	}
+	return subtaskMeta.SkipOnFail
+}
+
 // UpdateProgressDetail FIXME ...
 func UpdateProgressDetail(basicRes context.BasicRes, taskId uint64, progressDetail *models.TaskProgressDetail, p *plugin.RunningProgress) {
 	cfg := basicRes.GetConfigReader()
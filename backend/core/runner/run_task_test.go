@@ -0,0 +1,41 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtaskSkipOnFail(t *testing.T) {
+	collectJobs := &plugin.SubTaskMeta{Name: "Collect Job Runs", SkipOnFail: true}
+	convertJobs := &plugin.SubTaskMeta{Name: "Convert Jobs"}
+
+	// defaults to the meta
+	task := &models.Task{}
+	assert.True(t, subtaskSkipOnFail(task, collectJobs))
+	assert.False(t, subtaskSkipOnFail(task, convertJobs))
+
+	// overridden by the task
+	task.SkipOnFail = map[string]bool{"Collect Job Runs": false, "Convert Jobs": true}
+	assert.False(t, subtaskSkipOnFail(task, collectJobs))
+	assert.True(t, subtaskSkipOnFail(task, convertJobs))
+}
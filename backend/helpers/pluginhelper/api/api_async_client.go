@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"time"
@@ -35,6 +36,23 @@ import (
 // HttpMinStatusRetryCode is which status will retry
 var HttpMinStatusRetryCode = http.StatusBadRequest
 
+// RetryExceededError is the root cause of the error returned by ApiAsyncClient when a request still fails
+// after all retry attempts were used up, so callers can find out which request failed with `errors.As`
+// instead of parsing the error message
+type RetryExceededError struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Retry  int
+	// StatusCode is the status code of the last response, 0 if no response was received
+	StatusCode int
+	LastError  string
+}
+
+func (e *RetryExceededError) Error() string {
+	return fmt.Sprintf("Retry exceeded %d times calling %s. The last error was: %s", e.Retry, e.Path, e.LastError)
+}
+
 // ApiAsyncClient is built on top of ApiClient, to provide a asynchronous semantic
 // You may submit multiple requests at once by calling `DoGetAsync`, and those requests
 // will be performed in parallel with rate-limit support
@@ -44,6 +62,9 @@ type ApiAsyncClient struct {
 	maxRetry     int
 	numOfWorkers int
 	logger       log.Logger
+	// retryJitter is the upper bound of the random delay before a request is retried, so the requests failing
+	// at the same time are not retried all at once. Retries are not delayed when it is 0
+	retryJitter time.Duration
 }
 
 const defaultTimeout = 120 * time.Second
@@ -129,6 +150,7 @@ func CreateAsyncApiClient(
 		retry,
 		numOfWorkers,
 		logger,
+		0,
 	}, nil
 }
 
@@ -144,6 +166,24 @@ func (apiClient *ApiAsyncClient) SetMaxRetry(
 	apiClient.maxRetry = maxRetry
 }
 
+// GetRetryJitter returns the upper bound of the random delay before a request is retried
+func (apiClient *ApiAsyncClient) GetRetryJitter() time.Duration {
+	return apiClient.retryJitter
+}
+
+// SetRetryJitter sets the upper bound of the random delay before a request is retried, 0 disables the delay
+func (apiClient *ApiAsyncClient) SetRetryJitter(retryJitter time.Duration) {
+	apiClient.retryJitter = retryJitter
+}
+
+// jitterDelay returns a random delay in [0, maxJitter), or 0 if maxJitter is not positive
+func jitterDelay(maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(maxJitter)))
+}
+
 // DoAsync would carry out an asynchronous request
 func (apiClient *ApiAsyncClient) DoAsync(
 	method string,
@@ -182,11 +222,13 @@ func (apiClient *ApiAsyncClient) DoAsync(
 		// check
 		needRetry := false
 		errMessage := "unknown"
+		statusCode := 0
 		if err != nil {
 			needRetry = true
 			errMessage = err.Error()
 		} else if res.StatusCode >= HttpMinStatusRetryCode {
 			needRetry = true
+			statusCode = res.StatusCode
 			errMessage = fmt.Sprintf("Http DoAsync error calling [method:%s path:%s query:%s]. Response: %s", method, path, query, string(respBody))
 			err = errors.HttpStatus(res.StatusCode).New(errMessage)
 		}
@@ -197,7 +239,15 @@ func (apiClient *ApiAsyncClient) DoAsync(
 			if retry < apiClient.maxRetry && err != context.Canceled {
 				apiClient.logger.Warn(err, "retry #%d calling %s", retry, path)
 				retry++
+				delay := jitterDelay(apiClient.retryJitter)
 				apiClient.NextTick(func() errors.Error {
+					if delay > 0 {
+						apiClient.logger.Debug("delay retry #%d calling %s by %s", retry, path, delay)
+						select {
+						case <-time.After(delay):
+						case <-apiClient.WorkerScheduler.ctx.Done():
+						}
+					}
 					apiClient.SubmitBlocking(request)
 					return nil
 				})
@@ -205,10 +255,24 @@ func (apiClient *ApiAsyncClient) DoAsync(
 			}
 		}
 
+		if err != nil && errors.Is(err, context.Canceled) {
+			return errors.Default.Wrap(err, fmt.Sprintf("Retry exceeded %d times calling %s. The last error was: %s", retry, path, errMessage))
+		}
 		if err != nil {
-			err = errors.Default.Wrap(err, fmt.Sprintf("Retry exceeded %d times calling %s. The last error was: %s", retry, path, errMessage))
-			apiClient.logger.Error(err, "")
-			return errors.Convert(err)
+			errType := errors.Default
+			if statusCode > 0 {
+				errType = errors.HttpStatus(statusCode)
+			}
+			retryErr := errType.WrapRaw(&RetryExceededError{
+				Method:     method,
+				Path:       path,
+				Query:      query,
+				Retry:      retry,
+				StatusCode: statusCode,
+				LastError:  errMessage,
+			})
+			apiClient.logger.Error(retryErr, "")
+			return retryErr
 		}
 
 		// it is important to let handler have a chance to handle error, or it can hang indefinitely
@@ -244,6 +308,12 @@ func (apiClient *ApiAsyncClient) GetNumOfWorkers() int {
 	return apiClient.numOfWorkers
 }
 
+// SetNumOfWorkers changes the number of requests the scheduler runs in parallel
+func (apiClient *ApiAsyncClient) SetNumOfWorkers(numOfWorkers int) {
+	apiClient.numOfWorkers = numOfWorkers
+	apiClient.pool.Tune(numOfWorkers)
+}
+
 // RateLimitedApiClient FIXME ...
 type RateLimitedApiClient interface {
 	DoGetAsync(path string, query url.Values, header http.Header, handler plugin.ApiAsyncCallback)
@@ -256,6 +326,8 @@ type RateLimitedApiClient interface {
 	SetAfterFunction(callback plugin.ApiClientAfterResponse)
 	Reset(d time.Duration)
 	GetTickInterval() time.Duration
+	GetMaxRetry() int
+	SetMaxRetry(maxRetry int)
 	Release()
 }
 
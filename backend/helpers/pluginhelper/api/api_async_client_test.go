@@ -0,0 +1,76 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), jitterDelay(0))
+	assert.Equal(t, time.Duration(0), jitterDelay(-time.Second))
+
+	maxJitter := 5 * time.Second
+	for i := 0; i < 1000; i++ {
+		delay := jitterDelay(maxJitter)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, maxJitter)
+	}
+}
+
+func TestApiAsyncClientCountsRetries(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first two attempts fail
+		if hits.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	logger := unithelper.DummyLogger()
+	scheduler, err := NewWorkerScheduler(context.Background(), 1, time.Millisecond, logger)
+	assert.Nil(t, err)
+	defer scheduler.Release()
+	apiClient := &ApiAsyncClient{
+		ApiClient:       &ApiClient{client: server.Client(), endpoint: server.URL + "/", ctx: context.Background()},
+		WorkerScheduler: scheduler,
+		maxRetry:        3,
+		logger:          logger,
+	}
+
+	statusCode := 0
+	apiClient.DoGetAsync("jobs", nil, nil, func(res *http.Response) errors.Error {
+		statusCode = res.StatusCode
+		return nil
+	})
+	assert.Nil(t, apiClient.WaitAsync())
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, int64(3), apiClient.RequestCount())
+}
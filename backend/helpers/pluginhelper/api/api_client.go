@@ -32,6 +32,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -67,6 +68,8 @@ type ApiClient struct {
 	afterResponse plugin.ApiClientAfterResponse
 	ctx           gocontext.Context
 	logger        log.Logger
+	// requestCount is the number of requests sent, see RequestCount
+	requestCount atomic.Int64
 }
 
 // NewApiClientFromConnection creates ApiClient based on given connection.
@@ -208,6 +211,12 @@ func (apiClient *ApiClient) GetEndpoint() string {
 	return apiClient.endpoint
 }
 
+// RequestCount returns the number of requests sent by the client so far, every attempt of a retried request is
+// counted. The requests rejected by the beforeRequest hook are not, since they are never sent
+func (apiClient *ApiClient) RequestCount() int64 {
+	return apiClient.requestCount.Load()
+}
+
 // SetTimeout FIXME ...
 func (apiClient *ApiClient) SetTimeout(timeout time.Duration) {
 	apiClient.client.Timeout = timeout
@@ -218,6 +227,24 @@ func (apiClient *ApiClient) GetTimeout() time.Duration {
 	return apiClient.client.Timeout
 }
 
+// GetHttpClient returns a copy of the underlying http.Client, it shares the transport and timeout with the
+// ApiClient and is meant for requests that need a different redirect policy or must skip the authentication
+func (apiClient *ApiClient) GetHttpClient() *http.Client {
+	client := *apiClient.client
+	return &client
+}
+
+// WrapTransport replaces the transport the requests of the client are sent with by the one wrap returns for it, i.e.
+// to observe or delay all the requests whatever the subtask sending them. The default transport is passed when the
+// client has none. It is meant to be called before the client is used, SetProxy can't be called after it
+func (apiClient *ApiClient) WrapTransport(wrap func(next http.RoundTripper) http.RoundTripper) {
+	next := apiClient.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	apiClient.client.Transport = wrap(next)
+}
+
 // SetData FIXME ...
 func (apiClient *ApiClient) SetData(name string, data interface{}) {
 	apiClient.data_mutex.Lock()
@@ -373,6 +400,7 @@ func (apiClient *ApiClient) Do(
 		}
 	}
 	apiClient.logDebug("[api-client] %v %v", method, *uri)
+	apiClient.requestCount.Add(1)
 	res, err = errors.Convert01(apiClient.client.Do(req))
 	if err != nil {
 		apiClient.logError(err, "[api-client] failed to request %s with error", req.URL.String())
@@ -24,6 +24,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
@@ -78,9 +80,15 @@ type ApiCollectorArgs struct {
 	GetNextPageCustomData func(prevReqData *RequestData, prevPageResponse *http.Response) (interface{}, errors.Error)
 	// Incremental indicate if this is an incremental collection, the existing data won't get deleted if it was true
 	Incremental bool `comment:"indicate if this collection is incremental update"`
+	// KeepRawData keeps the existing data even if this is not an incremental collection, i.e. when resuming
+	// an interrupted collection from a checkpoint
+	KeepRawData bool
 	// ApiClient is a asynchronize api request client with qps
 	ApiClient       RateLimitedApiClient
 	MinTickInterval *time.Duration
+	// MaxRetry overrides the retry attempts of ApiClient during the collection, so endpoints with
+	// a different failure profile can use their own retry policy. Leave it nil to keep the client setting
+	MaxRetry *int
 	// Input helps us collect data based on previous collected data, like collecting changelogs based on jira
 	// issue ids
 	Input Iterator
@@ -91,6 +99,13 @@ type ApiCollectorArgs struct {
 	AfterResponse  plugin.ApiClientAfterResponse
 	RequestBody    func(reqData *RequestData) map[string]interface{}
 	Method         string
+	// SkipInputOnStatus lists the http status codes which skip the input of the request instead of failing the
+	// collection, i.e. a 404 for an item deleted after it was listed. The skipped requests are not retried, and
+	// AfterResponse is not called for them
+	SkipInputOnStatus []int
+	// OnInputSkipped is called with the input of every request skipped by SkipInputOnStatus, so the collector
+	// can record it. The input is nil if it could not be resolved from the response
+	OnInputSkipped func(input interface{}, res *http.Response)
 }
 
 // ApiCollector FIXME ...
@@ -98,6 +113,60 @@ type ApiCollector struct {
 	*RawDataSubTask
 	args        *ApiCollectorArgs
 	urlTemplate *template.Template
+	// inputsByUrl keeps track of the input each url was generated from, so a failing request can be
+	// traced back to its input
+	inputsByUrl map[string]interface{}
+	inputsMu    sync.Mutex
+	// summary is guarded by inputsMu
+	summary ApiCollectorSummary
+}
+
+// ApiCollectorSummary summarizes what an ApiCollector did, so the collectors don't need to count it in their
+// AfterResponse. It is complete once Execute returned
+type ApiCollectorSummary struct {
+	// Requests is the number of requests sent, one per page, the retries of a request are not counted
+	Requests int64
+	// Inputs is the number of inputs fetched from the Input iterator
+	Inputs int64
+	// FailedInputs are the inputs skipped by SkipInputOnStatus, and the one whose requests ran out of retries
+	FailedInputs []ApiCollectorInputFailure
+}
+
+// ApiCollectorInputFailure is an input of ApiCollector which could not be collected
+type ApiCollectorInputFailure struct {
+	// ID is the `ID` field of the input, 0 if the input has no such field
+	ID         int64
+	Input      interface{}
+	StatusCode int
+}
+
+// merge adds the counts and the failures of other to the summary
+func (s ApiCollectorSummary) merge(other ApiCollectorSummary) ApiCollectorSummary {
+	s.Requests += other.Requests
+	s.Inputs += other.Inputs
+	s.FailedInputs = append(append([]ApiCollectorInputFailure{}, s.FailedInputs...), other.FailedInputs...)
+	return s
+}
+
+// collectorRunCause lets CollectorRunError expose the errors.Error methods of its cause
+type collectorRunCause = errors.Error
+
+// CollectorRunError is returned by ApiCollector when the requests of a single input still failed after
+// all retries were used up, it tells the caller which input could not be collected
+type CollectorRunError struct {
+	collectorRunCause
+	// RunID is the `ID` field of the input, 0 if the input has no such field
+	RunID      int64
+	Input      interface{}
+	URL        string
+	StatusCode int
+}
+
+var _ errors.Error = (*CollectorRunError)(nil)
+
+// Unwrap returns the cause of the failure
+func (e *CollectorRunError) Unwrap() error {
+	return e.collectorRunCause
 }
 
 // NewApiCollector allocates a new ApiCollector with the given args.
@@ -127,20 +196,81 @@ func NewApiCollector(args ApiCollectorArgs) (*ApiCollector, errors.Error) {
 		RawDataSubTask: rawDataSubTask,
 		args:           &args,
 		urlTemplate:    tpl,
+		inputsByUrl:    make(map[string]interface{}),
 	}
-	if args.AfterResponse != nil {
-		apiCollector.SetAfterResponse(args.AfterResponse)
-	} else {
-		apiCollector.SetAfterResponse(func(res *http.Response) errors.Error {
+	afterResponse := args.AfterResponse
+	if afterResponse == nil {
+		afterResponse = func(res *http.Response) errors.Error {
 			if res.StatusCode == http.StatusUnauthorized {
 				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
 			}
 			return nil
-		})
+		}
+	}
+	if len(args.SkipInputOnStatus) > 0 {
+		afterResponse = apiCollector.skipInputOnStatus(afterResponse)
 	}
+	apiCollector.SetAfterResponse(afterResponse)
 	return apiCollector, nil
 }
 
+// skipInputOnStatus wraps afterResponse so the requests answered with one of the SkipInputOnStatus codes are
+// ignored, and reported to OnInputSkipped along with their input
+func (collector *ApiCollector) skipInputOnStatus(afterResponse plugin.ApiClientAfterResponse) plugin.ApiClientAfterResponse {
+	return func(res *http.Response) errors.Error {
+		for _, statusCode := range collector.args.SkipInputOnStatus {
+			if res.StatusCode != statusCode {
+				continue
+			}
+			input := collector.inputOfResponse(res)
+			collector.recordFailedInput(input, res.StatusCode)
+			if collector.args.OnInputSkipped != nil {
+				collector.args.OnInputSkipped(input, res)
+			}
+			return ErrIgnoreAndContinue
+		}
+		return afterResponse(res)
+	}
+}
+
+// recordFailedInput adds the input which could not be collected to the summary
+func (collector *ApiCollector) recordFailedInput(input interface{}, statusCode int) {
+	collector.inputsMu.Lock()
+	defer collector.inputsMu.Unlock()
+	collector.summary.FailedInputs = append(collector.summary.FailedInputs, ApiCollectorInputFailure{
+		ID:         getInputId(input),
+		Input:      input,
+		StatusCode: statusCode,
+	})
+}
+
+// Summary returns what the collector did so far, see ApiCollectorSummary
+func (collector *ApiCollector) Summary() ApiCollectorSummary {
+	collector.inputsMu.Lock()
+	defer collector.inputsMu.Unlock()
+	return ApiCollectorSummary{}.merge(collector.summary)
+}
+
+// inputOfResponse returns the input the request of res was generated from, the request path may be prefixed
+// by the endpoint of the api client, i.e. `/api/v3/` for GitHub Enterprise
+func (collector *ApiCollector) inputOfResponse(res *http.Response) interface{} {
+	if res.Request == nil || res.Request.URL == nil {
+		return nil
+	}
+	path := strings.TrimPrefix(res.Request.URL.Path, "/")
+	collector.inputsMu.Lock()
+	defer collector.inputsMu.Unlock()
+	if input, ok := collector.inputsByUrl[path]; ok {
+		return input
+	}
+	for apiUrl, input := range collector.inputsByUrl {
+		if strings.HasSuffix(path, "/"+strings.TrimPrefix(apiUrl, "/")) {
+			return input
+		}
+	}
+	return nil
+}
+
 var rawTableAutoMigrateLock sync.Mutex
 
 func (collector *ApiCollector) ensureRawTable() errors.Error {
@@ -168,7 +298,7 @@ func (collector *ApiCollector) Execute() errors.Error {
 		isIncremental = false
 	}
 	// flush data if not incremental collection
-	if !isIncremental {
+	if !isIncremental && !collector.args.KeepRawData {
 		err = db.Delete(&RawData{}, dal.From(collector.table), dal.Where("params = ?", collector.params))
 		if err != nil {
 			return errors.Default.Wrap(err, "error deleting data from collector")
@@ -193,6 +323,23 @@ func (collector *ApiCollector) Execute() errors.Error {
 		}
 	}
 
+	// if MaxRetry was specified
+	if collector.args.MaxRetry != nil {
+		maxRetry := *collector.args.MaxRetry
+		if maxRetry < 0 {
+			return errors.Default.New("MaxRetry must not be negative")
+		}
+		oldMaxRetry := collector.args.ApiClient.GetMaxRetry()
+		if oldMaxRetry != maxRetry {
+			logger.Info("set max retry to %d", maxRetry)
+			collector.args.ApiClient.SetMaxRetry(maxRetry)
+			defer func() {
+				logger.Info("restore max retry to %d", oldMaxRetry)
+				collector.args.ApiClient.SetMaxRetry(oldMaxRetry)
+			}()
+		}
+	}
+
 	collector.args.Ctx.SetProgress(0, -1)
 	if collector.args.Input != nil {
 		iterator := collector.args.Input
@@ -205,7 +352,7 @@ func (collector *ApiCollector) Execute() errors.Error {
 			if !iterator.HasNext() || apiClient.HasError() {
 				err = collector.args.ApiClient.WaitAsync()
 				if err != nil {
-					return err
+					return collector.asCollectorRunError(err)
 				}
 				if !iterator.HasNext() || apiClient.HasError() {
 					break
@@ -216,6 +363,9 @@ func (collector *ApiCollector) Execute() errors.Error {
 			if err != nil {
 				break
 			}
+			collector.inputsMu.Lock()
+			collector.summary.Inputs++
+			collector.inputsMu.Unlock()
 			collector.exec(input)
 		}
 	} else {
@@ -230,7 +380,7 @@ func (collector *ApiCollector) Execute() errors.Error {
 	err = collector.args.ApiClient.WaitAsync()
 	if err != nil {
 		logger.Error(err, "end api collection error")
-		err = errors.Default.Wrap(err, "Error waiting for async Collector execution")
+		err = collector.asCollectorRunError(errors.Default.Wrap(err, "Error waiting for async Collector execution"))
 	} else {
 		logger.Info("end api collection without error")
 	}
@@ -238,6 +388,45 @@ func (collector *ApiCollector) Execute() errors.Error {
 	return err
 }
 
+// asCollectorRunError converts err to a CollectorRunError when it was caused by the requests of an input
+// running out of retries, otherwise err is returned as is
+func (collector *ApiCollector) asCollectorRunError(err errors.Error) errors.Error {
+	retryErr := &RetryExceededError{}
+	if !errors.As(err, &retryErr) {
+		return err
+	}
+	collector.inputsMu.Lock()
+	input, ok := collector.inputsByUrl[retryErr.Path]
+	collector.inputsMu.Unlock()
+	if !ok {
+		return err
+	}
+	collector.recordFailedInput(input, retryErr.StatusCode)
+	return &CollectorRunError{
+		collectorRunCause: err,
+		RunID:             getInputId(input),
+		Input:             input,
+		URL:               retryErr.Path,
+		StatusCode:        retryErr.StatusCode,
+	}
+}
+
+// getInputId returns the value of the integer `ID` field of the input, 0 if there is none
+func getInputId(input interface{}) int64 {
+	v := reflect.Indirect(reflect.ValueOf(input))
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	field := v.FieldByName("ID")
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint())
+	}
+	return 0
+}
+
 func (collector *ApiCollector) exec(input interface{}) {
 	inputJson, err := json.Marshal(input)
 	if err != nil {
@@ -442,6 +631,12 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 			panic(err)
 		}
 	}
+	collector.inputsMu.Lock()
+	collector.summary.Requests++
+	if reqData.Input != nil {
+		collector.inputsByUrl[apiUrl] = reqData.Input
+	}
+	collector.inputsMu.Unlock()
 	logger := collector.args.Ctx.GetLogger()
 	logger.Debug("fetchAsync <<< enqueueing for %s %v", apiUrl, apiQuery)
 	responseHandler := func(res *http.Response) errors.Error {
@@ -103,6 +103,17 @@ func (m *StatefulApiCollector) Execute() errors.Error {
 	return m.CollectorStateManager.Close()
 }
 
+// Summary returns what the nested ApiCollectors did so far, see ApiCollectorSummary
+func (m *StatefulApiCollector) Summary() ApiCollectorSummary {
+	summary := ApiCollectorSummary{}
+	for _, subtask := range m.nestedCollectors {
+		if apiCollector, ok := subtask.(*ApiCollector); ok {
+			summary = summary.merge(apiCollector.Summary())
+		}
+	}
+	return summary
+}
+
 // NewStatefulApiCollectorForFinalizableEntity aims to add timeFilter/diffSync support for
 // APIs that do NOT support filtering data by the updated date. However, it comes with the
 // following constraints:
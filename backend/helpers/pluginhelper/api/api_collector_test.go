@@ -107,3 +107,118 @@ func TestFetchPageUndetermined(t *testing.T) {
 
 	mockDal.AssertExpectations(t)
 }
+
+func TestCollectorRunError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusInternalServerError, http.StatusNotFound} {
+		mockDal := new(mockdal.Dal)
+		mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil).Once()
+		mockDal.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		mockCtx := unithelper.DummySubTaskContext(mockDal)
+
+		mockInput := new(mockapi.Iterator)
+		mockInput.On("HasNext").Return(true).Once()
+		mockInput.On("HasNext").Return(false)
+		mockInput.On("Fetch").Return(&struct{ ID int64 }{ID: 42}, nil).Once()
+		mockInput.On("Close").Return(nil)
+
+		// the run ran out of retries, the client reports the failure when waiting for the requests
+		mockApi := new(mockapi.RateLimitedApiClient)
+		mockApi.On("DoGetAsync", "repos/a/b/actions/runs/42/jobs", mock.Anything, mock.Anything, mock.Anything).Return().Once()
+		mockApi.On("HasError").Return(false)
+		mockApi.On("WaitAsync").Return(errors.HttpStatus(statusCode).WrapRaw(&RetryExceededError{
+			Path:       "repos/a/b/actions/runs/42/jobs",
+			Retry:      3,
+			StatusCode: statusCode,
+		}))
+		mockApi.On("SetAfterFunction", mock.Anything).Return()
+
+		collector, err := NewApiCollector(ApiCollectorArgs{
+			RawDataSubTaskArgs: RawDataSubTaskArgs{
+				Ctx:     mockCtx,
+				Table:   "whatever rawtable",
+				Options: &TestOpts{},
+			},
+			ApiClient:      mockApi,
+			Input:          mockInput,
+			UrlTemplate:    "repos/a/b/actions/runs/{{ .Input.ID }}/jobs",
+			ResponseParser: GetRawMessageArrayFromResponse,
+		})
+		assert.Nil(t, err)
+
+		err = collector.Execute()
+		runErr := &CollectorRunError{}
+		if assert.True(t, errors.As(err, &runErr)) {
+			assert.Equal(t, int64(42), runErr.RunID)
+			assert.Equal(t, "repos/a/b/actions/runs/42/jobs", runErr.URL)
+			assert.Equal(t, statusCode, runErr.StatusCode)
+			assert.Equal(t, statusCode, runErr.GetType().GetHttpCode())
+		}
+		assert.Equal(t, ApiCollectorSummary{
+			Requests:     1,
+			Inputs:       1,
+			FailedInputs: []ApiCollectorInputFailure{{ID: 42, Input: &struct{ ID int64 }{ID: 42}, StatusCode: statusCode}},
+		}, collector.Summary())
+		mockApi.AssertExpectations(t)
+	}
+}
+
+func TestSkipInputOnStatus(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDal.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	mockCtx := unithelper.DummySubTaskContext(mockDal)
+
+	mockInput := new(mockapi.Iterator)
+	mockInput.On("HasNext").Return(true).Once()
+	mockInput.On("HasNext").Return(false)
+	mockInput.On("Fetch").Return(&struct{ ID int64 }{ID: 42}, nil).Once()
+	mockInput.On("Close").Return(nil)
+
+	var afterResponse plugin.ApiClientAfterResponse
+	mockApi := new(mockapi.RateLimitedApiClient)
+	mockApi.On("DoGetAsync", "repos/a/b/actions/runs/42/jobs", mock.Anything, mock.Anything, mock.Anything).Return().Once()
+	mockApi.On("HasError").Return(false)
+	mockApi.On("WaitAsync").Return(nil)
+	mockApi.On("SetAfterFunction", mock.Anything).Run(func(args mock.Arguments) {
+		afterResponse = args.Get(0).(plugin.ApiClientAfterResponse)
+	}).Return()
+
+	var skipped []interface{}
+	collector, err := NewApiCollector(ApiCollectorArgs{
+		RawDataSubTaskArgs: RawDataSubTaskArgs{
+			Ctx:     mockCtx,
+			Table:   "whatever rawtable",
+			Options: &TestOpts{},
+		},
+		ApiClient:         mockApi,
+		Input:             mockInput,
+		UrlTemplate:       "repos/a/b/actions/runs/{{ .Input.ID }}/jobs",
+		ResponseParser:    GetRawMessageArrayFromResponse,
+		SkipInputOnStatus: []int{http.StatusNotFound},
+		OnInputSkipped: func(input interface{}, res *http.Response) {
+			skipped = append(skipped, input)
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, collector.Execute())
+
+	response := func(statusCode int) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Request:    &http.Request{URL: &url.URL{Path: "/api/v3/repos/a/b/actions/runs/42/jobs"}},
+		}
+	}
+	assert.Equal(t, ErrIgnoreAndContinue, afterResponse(response(http.StatusNotFound)))
+	assert.Equal(t, []interface{}{&struct{ ID int64 }{ID: 42}}, skipped)
+	// the other responses are still handled by the default AfterResponse
+	assert.Nil(t, afterResponse(response(http.StatusOK)))
+	assert.NotNil(t, afterResponse(response(http.StatusUnauthorized)))
+	assert.Len(t, skipped, 1)
+	assert.Equal(t, ApiCollectorSummary{
+		Requests:     1,
+		Inputs:       1,
+		FailedInputs: []ApiCollectorInputFailure{{ID: 42, Input: &struct{ ID int64 }{ID: 42}, StatusCode: http.StatusNotFound}},
+	}, collector.Summary())
+}
@@ -105,6 +105,16 @@ func (c *CollectorStateManager) GetUntil() *time.Time {
 	return c.until
 }
 
+// ResetToFullSync discards the previous state, the collector runs in the full sync mode as if it had never succeeded,
+// i.e. when the previous state can not be trusted
+func (c *CollectorStateManager) ResetToFullSync() {
+	c.isIncremental = false
+	c.since = c.syncPolicy.TimeAfter
+	if c.since == nil {
+		c.since = c.state.TimeAfter
+	}
+}
+
 func (c *CollectorStateManager) Close() errors.Error {
 	// update timeAfter in the database only for fullsync mode
 	if !c.isIncremental {
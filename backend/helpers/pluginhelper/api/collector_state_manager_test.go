@@ -148,6 +148,27 @@ func TestCollectorStateManager(t *testing.T) {
 	}
 }
 
+func TestCollectorStateManagerResetToFullSync(t *testing.T) {
+	time1 := errors.Must1(time.Parse(time.RFC3339, "2021-01-01T00:00:00Z"))
+	future := time.Now().Add(24 * time.Hour)
+	started := time.Now()
+	mockBasicRes := newMockBasicRes(&models.CollectorLatestState{TimeAfter: &time1, LatestSuccessStart: &future})
+	stateManager, err := NewCollectorStateManager(mockBasicRes, &models.SyncPolicy{}, "table", "params")
+	assert.Nil(t, err)
+	assert.True(t, stateManager.IsIncremental())
+	assert.Equal(t, &future, stateManager.GetSince())
+
+	stateManager.ResetToFullSync()
+	assert.False(t, stateManager.IsIncremental())
+	assert.Equal(t, &time1, stateManager.GetSince())
+	// the state is repaired once the collection succeeds
+	assert.Nil(t, stateManager.Close())
+	assert.Equal(t, &time1, stateManager.state.TimeAfter)
+	assert.GreaterOrEqual(t, stateManager.state.LatestSuccessStart.Unix(), started.Unix())
+	assert.True(t, stateManager.state.LatestSuccessStart.Before(future))
+	mockBasicRes.AssertExpectations(t)
+}
+
 func newMockBasicRes(state *models.CollectorLatestState) *mockcontext.BasicRes {
 	// Refresh Global Variables and set the sql mock
 	return unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
@@ -18,6 +18,8 @@ limitations under the License.
 package api
 
 import (
+	"time"
+
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
@@ -31,3 +33,12 @@ func CallDB(f func(any, ...dal.Clause) errors.Error, x any, clauses ...dal.Claus
 	}
 	return f(x, clauses...)
 }
+
+// NormalizeNullableTime turns nil, Go zero and year 0000 times into nil, the latter is what parsing
+// "0000-01-01T00:00:00Z" from an api response yields, and neither of them can be saved into a MySQL datetime column
+func NormalizeNullableTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() || t.Year() <= 0 {
+		return nil
+	}
+	return t
+}
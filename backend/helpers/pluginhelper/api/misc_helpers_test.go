@@ -0,0 +1,36 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeNullableTime(t *testing.T) {
+	year0Time := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	zeroTime := time.Time{}
+	validTime := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	assert.Nil(t, NormalizeNullableTime(nil))
+	assert.Nil(t, NormalizeNullableTime(&year0Time))
+	assert.Nil(t, NormalizeNullableTime(&zeroTime))
+	assert.Equal(t, &validTime, NormalizeNullableTime(&validTime))
+}
@@ -94,6 +94,10 @@ func (s *WorkerScheduler) SubmitBlocking(task func() errors.Error) {
 	s.waitGroup.Add(1)
 	s.checkError(s.pool.Submit(func() {
 		defer s.waitGroup.Done()
+		// the error is recorded before the task is done, so WaitAsync can't return without it
+		defer func() {
+			s.checkError(recover())
+		}()
 
 		id := atomic.AddInt32(&s.counter, 1)
 		s.logger.Debug("schedulerJob >>> %d started", id)
@@ -146,6 +150,8 @@ func (s *WorkerScheduler) checkError(err interface{}) {
 
 // HasError return if any error occurred
 func (s *WorkerScheduler) HasError() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return len(s.workerErrors) > 0
 }
 
@@ -164,13 +170,20 @@ func (s *WorkerScheduler) NextTick(task func() errors.Error) {
 // Wait blocks current go-routine until all workers returned
 func (s *WorkerScheduler) WaitAsync() errors.Error {
 	s.waitGroup.Wait()
-	if len(s.workerErrors) > 0 {
-		for _, err := range s.workerErrors {
+	s.mu.Lock()
+	workerErrors := append([]error{}, s.workerErrors...)
+	s.mu.Unlock()
+	if len(workerErrors) > 0 {
+		for _, err := range workerErrors {
 			if errors.Is(err, context.Canceled) {
 				return errors.Default.Wrap(err, "task canceled")
 			}
 		}
-		return errors.Default.Combine(s.workerErrors)
+		if len(workerErrors) == 1 {
+			// keep the error chain intact so callers can inspect the root cause
+			return errors.Convert(workerErrors[0])
+		}
+		return errors.Default.Combine(workerErrors)
 	}
 	return nil
 }
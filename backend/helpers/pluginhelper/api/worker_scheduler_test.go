@@ -64,3 +64,17 @@ func TestWorkerSchedulerQpsControl(t *testing.T) {
 	}
 	cancel()
 }
+
+func TestWorkerSchedulerKeepsErrorChain(t *testing.T) {
+	s, _ := NewWorkerScheduler(context.Background(), 1, time.Millisecond, unithelper.DummyLogger())
+	defer s.Release()
+	s.SubmitBlocking(func() errors.Error {
+		return errors.HttpStatus(500).WrapRaw(&RetryExceededError{Path: "repos/a/b/actions/runs/1/jobs", Retry: 3, StatusCode: 500})
+	})
+	err := s.WaitAsync()
+	retryErr := &RetryExceededError{}
+	if assert.True(t, errors.As(err, &retryErr)) {
+		assert.Equal(t, "repos/a/b/actions/runs/1/jobs", retryErr.Path)
+		assert.Equal(t, 500, retryErr.StatusCode)
+	}
+}
@@ -242,15 +242,16 @@ func toRepoModel(r *repo) dsmodels.DsRemoteApiScopeListEntry[models.GithubRepo]
 		Name:     fmt.Sprintf("%v", r.Name),
 		FullName: fmt.Sprintf("%v", r.FullName),
 		Data: &models.GithubRepo{
-			GithubId:    r.ID,
-			Name:        r.Name,
-			FullName:    r.FullName,
-			HTMLUrl:     r.HTMLURL,
-			Description: r.Description,
-			OwnerId:     r.Owner.ID,
-			CloneUrl:    r.CloneURL,
-			CreatedDate: r.CreatedAt,
-			UpdatedDate: r.UpdatedAt,
+			GithubId:      r.ID,
+			Name:          r.Name,
+			FullName:      r.FullName,
+			HTMLUrl:       r.HTMLURL,
+			Description:   r.Description,
+			OwnerId:       r.Owner.ID,
+			CloneUrl:      r.CloneURL,
+			DefaultBranch: r.DefaultBranch,
+			CreatedDate:   r.CreatedAt,
+			UpdatedDate:   r.UpdatedAt,
 		},
 	}
 }
@@ -264,15 +265,16 @@ func toGithubAppRepoModel(r *GithubAppRepo) dsmodels.DsRemoteApiScopeListEntry[m
 		Name:     fmt.Sprintf("%v", r.Name),
 		FullName: fmt.Sprintf("%v", r.FullName),
 		Data: &models.GithubRepo{
-			GithubId:    r.ID,
-			Name:        r.Name,
-			FullName:    r.FullName,
-			HTMLUrl:     r.HTMLURL,
-			Description: r.Description,
-			OwnerId:     r.Owner.ID,
-			CloneUrl:    r.CloneURL,
-			CreatedDate: r.CreatedAt,
-			UpdatedDate: r.UpdatedAt,
+			GithubId:      r.ID,
+			Name:          r.Name,
+			FullName:      r.FullName,
+			HTMLUrl:       r.HTMLURL,
+			Description:   r.Description,
+			OwnerId:       r.Owner.ID,
+			CloneUrl:      r.CloneURL,
+			DefaultBranch: r.DefaultBranch,
+			CreatedDate:   r.CreatedAt,
+			UpdatedDate:   r.UpdatedAt,
 		},
 	}
 }
@@ -48,6 +48,9 @@ func TestGithubCICDJobDataFlow(t *testing.T) {
 	// SELECT * FROM _raw_github_api_jobs INTO OUTFILE "/tmp/_raw_github_api_jobs.csv" FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' LINES TERMINATED BY '\r\n';
 	dataflowTester.ImportCsvIntoRawTable("./raw_tables/_raw_github_api_jobs.csv", "_raw_github_api_jobs")
 
+	// the runs are not imported, the event of the jobs is left empty
+	dataflowTester.FlushTabler(&models.GithubRun{})
+
 	// verify when production regex is omitted
 	dataflowTester.FlushTabler(&models.GithubJob{})
 	dataflowTester.Subtask(tasks.ExtractJobsMeta, taskData)
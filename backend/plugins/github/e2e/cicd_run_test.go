@@ -51,6 +51,7 @@ func TestGithubCICDRunDataFlow(t *testing.T) {
 
 	// verify when production regex is omitted
 	dataflowTester.FlushTabler(&models.GithubRun{})
+	dataflowTester.FlushTabler(&models.GithubRunPullRequest{})
 	dataflowTester.Subtask(tasks.ExtractRunsMeta, taskData)
 	dataflowTester.VerifyTableWithOptions(&models.GithubRun{}, e2ehelper.TableOptions{
 		CSVRelPath:  "./snapshot_tables/_tool_github_runs_no_prod_regex.csv",
@@ -59,6 +60,7 @@ func TestGithubCICDRunDataFlow(t *testing.T) {
 
 	// verify extraction
 	dataflowTester.FlushTabler(&models.GithubRun{})
+	dataflowTester.FlushTabler(&models.GithubRunPullRequest{})
 	_ = regexEnricher.TryAdd(devops.PRODUCTION, "CodeQL.*")
 	dataflowTester.Subtask(tasks.ExtractRunsMeta, taskData)
 	dataflowTester.VerifyTableWithOptions(&models.GithubRun{}, e2ehelper.TableOptions{
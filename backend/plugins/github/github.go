@@ -18,8 +18,14 @@ limitations under the License.
 package main // must be main for plugin entry point
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/core/runner"
 	"github.com/apache/incubator-devlake/plugins/github/impl"
+	"github.com/apache/incubator-devlake/plugins/github/tasks"
 	"github.com/spf13/cobra"
 )
 
@@ -49,11 +55,27 @@ func main() {
 	deploymentPattern := cmd.Flags().StringP("deployment", "", "", "deployment pattern")
 	productionPattern := cmd.Flags().StringP("production", "", "", "production pattern")
 
+	jobs := cmd.Flags().Bool("jobs", false, "only collect the jobs of the collected workflow runs and print the summary")
+	jobsPageSize := cmd.Flags().Int("jobsPageSize", 0, "number of jobs requested per page, 1-100")
+	jobsConcurrency := cmd.Flags().Int("jobsConcurrency", 0, "number of parallel jobs requests")
+	workflowNames := cmd.Flags().StringSlice("workflowNames", nil, "only collect the jobs of these workflows")
+
 	cmd.Run = func(cmd *cobra.Command, args []string) {
-		runner.DirectRun(cmd, args, PluginEntry, map[string]interface{}{
-			"connectionId": *connectionId,
-			"owner":        *owner,
-			"repo":         *repo,
+		var pluginTask plugin.PluginTask = PluginEntry
+		if *jobs {
+			// runs the jobs collector alone, the runs of the repo must have been collected before
+			if err := cmd.Flags().Set("subtasks", tasks.CollectJobsMeta.Name); err != nil {
+				panic(err)
+			}
+			pluginTask = jobsRunner{PluginEntry}
+		}
+		runner.DirectRun(cmd, args, pluginTask, map[string]interface{}{
+			"connectionId":    *connectionId,
+			"owner":           *owner,
+			"repo":            *repo,
+			"jobsPageSize":    *jobsPageSize,
+			"jobsConcurrency": *jobsConcurrency,
+			"workflowNames":   *workflowNames,
 			"scopeConfig": map[string]interface{}{
 				"prType":               *prType,
 				"prComponent":          *prComponent,
@@ -71,3 +93,23 @@ func main() {
 	}
 	runner.RunCmd(cmd)
 }
+
+// jobsRunner prints the summary of the jobs collection once the task is done, to reproduce collection failures
+type jobsRunner struct {
+	impl.Github
+}
+
+func (p jobsRunner) Close(taskCtx plugin.TaskContext) errors.Error {
+	if data, ok := taskCtx.GetData().(*tasks.GithubTaskData); ok {
+		if data.JobCollectionResult == nil {
+			fmt.Println("the jobs were not collected, see the logs for the error")
+		} else {
+			summary, err := json.MarshalIndent(data.JobCollectionResult, "", "  ")
+			if err != nil {
+				return errors.Convert(err)
+			}
+			fmt.Println(string(summary))
+		}
+	}
+	return p.Github.Close(taskCtx)
+}
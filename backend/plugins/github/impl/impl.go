@@ -90,6 +90,23 @@ func (p Github) GetTablesInfo() []dal.Tabler {
 		&models.GithubIssueEvent{},
 		&models.GithubIssueLabel{},
 		&models.GithubJob{},
+		&models.GithubJobStep{},
+		&models.GithubJobCollectionFailure{},
+		&models.GithubJobCollectionCheckpoint{},
+		&models.GithubJobLog{},
+		&models.GithubRunTiming{},
+		&models.GithubRunApproval{},
+		&models.GithubEnvironment{},
+		&models.GithubDeploymentStatus{},
+		&models.GithubWorkflow{},
+		&models.GithubJobDurationSummary{},
+		&models.GithubCheckRun{},
+		&models.GithubRunPullRequest{},
+		&models.GithubRunArtifact{},
+		&models.GithubJobAnnotation{},
+		&models.GithubRunner{},
+		&models.GithubJobPageEtag{},
+		&models.GithubFlakyJob{},
 		&models.GithubMilestone{},
 		&models.GithubPrComment{},
 		&models.GithubPrCommit{},
@@ -146,6 +163,7 @@ func (p Github) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]i
 	if err != nil {
 		return nil, err
 	}
+	tasks.GuardRateLimit(apiClient, *op.RateLimitMinRemaining, logger)
 
 	regexEnricher := helper.NewRegexEnricher()
 	if err = regexEnricher.TryAdd(devops.DEPLOYMENT, op.ScopeConfig.DeploymentPattern); err != nil {
@@ -159,14 +177,54 @@ func (p Github) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]i
 	}
 
 	taskData := &tasks.GithubTaskData{
-		Options:       op,
-		ApiClient:     apiClient,
-		RegexEnricher: regexEnricher,
+		Options:              op,
+		ApiClient:            apiClient,
+		RegexEnricher:        regexEnricher,
+		ActionsApiPathPrefix: connection.ActionsApiPathPrefix,
+	}
+	for _, scope := range op.JobsScopes {
+		scopeData, err := prepareJobsScopeData(taskCtx, connectionHelper, taskData, scope)
+		if err != nil {
+			return nil, err
+		}
+		taskData.JobsScopes = append(taskData.JobsScopes, scopeData)
 	}
 
 	return taskData, nil
 }
 
+// prepareJobsScopeData builds the task data of a repo of JobsScopes, the api client of its own connection keeps
+// track of the rate limit of the connection
+func prepareJobsScopeData(
+	taskCtx plugin.TaskContext,
+	connectionHelper *helper.ConnectionApiHelper,
+	taskData *tasks.GithubTaskData,
+	scope tasks.JobsScope,
+) (*tasks.GithubTaskData, errors.Error) {
+	connection := &models.GithubConnection{}
+	err := connectionHelper.FirstById(connection, scope.ConnectionId)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("unable to get the github connection %d of jobsScopes", scope.ConnectionId))
+	}
+	apiClient, err := tasks.CreateApiClient(taskCtx, connection)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("unable to get github API client instance of connection %d", scope.ConnectionId))
+	}
+	tasks.GuardRateLimit(apiClient, *taskData.Options.RateLimitMinRemaining, taskCtx.GetLogger())
+
+	op := *taskData.Options
+	op.ConnectionId = scope.ConnectionId
+	op.GithubId = scope.GithubId
+	op.Owner, op.Repo, op.Name, op.FullName = "", "", scope.Name, scope.Name
+	op.JobsScopes = nil
+	return &tasks.GithubTaskData{
+		Options:              &op,
+		ApiClient:            apiClient,
+		RegexEnricher:        taskData.RegexEnricher,
+		ActionsApiPathPrefix: connection.ActionsApiPathPrefix,
+	}, nil
+}
+
 func (p Github) RootPkgPath() string {
 	return "github.com/apache/incubator-devlake/plugins/github"
 }
@@ -296,6 +354,10 @@ func EnrichOptions(taskCtx plugin.TaskContext,
 		if op.ScopeConfigId == 0 {
 			op.ScopeConfigId = githubRepo.ScopeConfigId
 		}
+		if op.DefaultBranchOnly && githubRepo.DefaultBranch == "" {
+			// the scopes created before the default branch was stored don't have it
+			enrichDefaultBranch(taskCtx, op, apiClient, &githubRepo)
+		}
 	} else {
 		if taskCtx.GetDal().IsErrorNotFound(err) && op.Name != "" {
 			var repo *tasks.GithubApiRepo
@@ -330,6 +392,25 @@ func EnrichOptions(taskCtx plugin.TaskContext,
 	return err
 }
 
+// enrichDefaultBranch fetches the default branch of the repo and stores it on the scope, the jobs of all the branches
+// are collected when it fails
+func enrichDefaultBranch(taskCtx plugin.TaskContext, op *tasks.GithubOptions, apiClient *helper.ApiClient, githubRepo *models.GithubRepo) {
+	logger := taskCtx.GetLogger()
+	repo, err := api.MemorizedGetApiRepo(nil, op, apiClient)
+	if err != nil {
+		logger.Warn(err, "failed to get the default branch of %s", op.Name)
+		return
+	}
+	githubRepo.DefaultBranch = repo.DefaultBranch
+	err = taskCtx.GetDal().UpdateColumn(
+		&models.GithubRepo{}, "default_branch", repo.DefaultBranch,
+		dal.Where("connection_id = ? AND github_id = ?", githubRepo.ConnectionId, githubRepo.GithubId),
+	)
+	if err != nil {
+		logger.Warn(err, "failed to save the default branch of %s", op.Name)
+	}
+}
+
 func convertApiRepoToScope(repo *tasks.GithubApiRepo, connectionId uint64) *models.GithubRepo {
 	var scope models.GithubRepo
 	scope.ConnectionId = connectionId
@@ -341,6 +422,7 @@ func convertApiRepoToScope(repo *tasks.GithubApiRepo, connectionId uint64) *mode
 	scope.Name = repo.Name
 	scope.FullName = repo.FullName
 	scope.CloneUrl = repo.CloneUrl
+	scope.DefaultBranch = repo.DefaultBranch
 	return &scope
 }
 
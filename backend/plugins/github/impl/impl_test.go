@@ -35,4 +35,16 @@ func Test_genSubtaskList(t *testing.T) {
 		subtaskNameList[index] = item.Name
 	}
 	t.Logf("got subtask list %s", subtaskNameList)
+
+	// the contents of the workflows update the rows of the workflows
+	positions := make(map[string]int, len(subtaskNameList))
+	for index, name := range subtaskNameList {
+		positions[name] = index
+	}
+	ordered := []string{"Extract Workflows", "Collect Workflow Contents", "Extract Workflow Contents"}
+	for i := 1; i < len(ordered); i++ {
+		if positions[ordered[i-1]] > positions[ordered[i]] {
+			t.Errorf("%s should run before %s", ordered[i-1], ordered[i])
+		}
+	}
 }
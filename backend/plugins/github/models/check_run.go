@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubCheckRun is a check run of a commit, registered by GitHub Actions or by a third party CI app
+type GithubCheckRun struct {
+	common.NoPKModel
+	ConnectionId uint64     `gorm:"primaryKey"`
+	RepoId       int        `gorm:"primaryKey"`
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	HeadSha      string     `json:"head_sha" gorm:"type:varchar(255)"`
+	Name         string     `json:"name" gorm:"type:varchar(255)"`
+	Status       string     `json:"status" gorm:"type:varchar(255)"`
+	Conclusion   string     `json:"conclusion" gorm:"type:varchar(255)"`
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	HTMLURL      string     `json:"html_url" gorm:"type:varchar(255)"`
+	AppSlug      string     `gorm:"type:varchar(255)"`
+}
+
+func (GithubCheckRun) TableName() string {
+	return "_tool_github_check_runs"
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/apache/incubator-devlake/core/utils"
@@ -30,6 +31,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -43,6 +45,19 @@ type GithubAccessToken struct {
 	helper.AccessToken `mapstructure:",squash"`
 	tokens             []string `gorm:"-" json:"-" mapstructure:"-"`
 	tokenIndex         int      `gorm:"-" json:"-" mapstructure:"-"`
+	// tokensLock guards the tokens, they are replaced while requests are sent in parallel when an installation
+	// token expires. It is a pointer since the connections are copied by value, and it is set by PrepareApiClient
+	tokensLock *sync.Mutex `gorm:"-" json:"-" mapstructure:"-"`
+}
+
+// lockTokens locks the tokens of the connection and returns the function unlocking them, the connections which
+// were not prepared have no tokens to guard
+func (gat *GithubAccessToken) lockTokens() func() {
+	if gat.tokensLock == nil {
+		return func() {}
+	}
+	gat.tokensLock.Lock()
+	return gat.tokensLock.Unlock
 }
 
 type GithubAppKey struct {
@@ -60,6 +75,7 @@ type GithubConn struct {
 
 // PrepareApiClient splits Token to tokens for SetupAuthentication to utilize
 func (conn *GithubConn) PrepareApiClient(apiClient plugin.ApiClient) errors.Error {
+	conn.tokensLock = &sync.Mutex{}
 
 	if conn.AuthMethod == AccessToken {
 		conn.tokens = strings.Split(conn.Token, ",")
@@ -80,6 +96,7 @@ func (conn *GithubConn) PrepareApiClient(apiClient plugin.ApiClient) errors.Erro
 
 // SetupAuthentication sets up the HTTP Request Authentication
 func (conn *GithubConn) SetupAuthentication(req *http.Request) errors.Error {
+	defer conn.lockTokens()()
 	// Rotates token on each request.
 	if len(conn.tokens) > 0 {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", conn.tokens[conn.tokenIndex]))
@@ -90,6 +107,32 @@ func (conn *GithubConn) SetupAuthentication(req *http.Request) errors.Error {
 	return nil
 }
 
+// RefreshInstallationToken replaces the installation token of a GitHub App connection after a request was
+// rejected with it, the token expires after an hour. The authorization of the rejected request is compared with
+// the current token, so the requests rejected in parallel mint a new token only once. It returns whether a new
+// token was minted. The apiClient must not be the one authenticated by the connection, it would replace the JWT
+func (conn *GithubConn) RefreshInstallationToken(apiClient plugin.ApiClient, rejectedAuthorization string) (bool, errors.Error) {
+	if conn.AuthMethod != AppKey || conn.InstallationID == 0 {
+		return false, nil
+	}
+	defer conn.lockTokens()()
+	if rejectedAuthorization != fmt.Sprintf("Bearer %v", conn.Token) {
+		// refreshed by another request already
+		return false, nil
+	}
+	token, err := conn.getInstallationAccessToken(apiClient)
+	if err != nil {
+		return false, err
+	}
+	if token.Token == "" {
+		return false, errors.Unauthorized.New("failed to refresh the installation token of the GitHub App")
+	}
+	conn.Token = token.Token
+	conn.tokens = []string{token.Token}
+	conn.tokenIndex = 0
+	return true, nil
+}
+
 func (gat *GithubAccessToken) GetTokensCount() int {
 	return len(gat.tokens)
 }
@@ -99,6 +142,62 @@ type GithubConnection struct {
 	helper.BaseConnection `mapstructure:",squash"`
 	GithubConn            `mapstructure:",squash"`
 	EnableGraphql         bool `mapstructure:"enableGraphql" json:"enableGraphql"`
+	// ActionsApiPathPrefix is prepended to the paths of the Actions API, i.e. "github-actions" when a gateway
+	// in front of a GitHub Enterprise Server serves it under a different path. Leave it empty for GitHub
+	ActionsApiPathPrefix string `mapstructure:"actionsApiPathPrefix" json:"actionsApiPathPrefix" gorm:"type:varchar(255)"`
+	// RetryJitterSeconds is the upper bound of the random delay before a failed request is retried, so the
+	// requests failing at the same time during a GitHub incident are not retried all at once. 0 disables it
+	RetryJitterSeconds int `mapstructure:"retryJitterSeconds" json:"retryJitterSeconds"`
+	// ApiVersion is the version of the REST API requested with the X-GitHub-Api-Version header, i.e. "2022-11-28",
+	// so the fields returned don't change when GitHub or a GitHub Enterprise Server is upgraded. Leave it empty for
+	// DefaultApiVersion
+	ApiVersion string `mapstructure:"apiVersion" json:"apiVersion" gorm:"type:varchar(255)"`
+}
+
+// DefaultApiVersion is the version of the REST API requested when the connection doesn't set one, it is supported
+// by GitHub and by GitHub Enterprise Server since 3.9
+const DefaultApiVersion = "2022-11-28"
+
+// GetApiVersion returns the version of the REST API to request
+func (connection *GithubConnection) GetApiVersion() string {
+	if connection.ApiVersion == "" {
+		return DefaultApiVersion
+	}
+	return connection.ApiVersion
+}
+
+// CustomValidate validates the authentication of the connection, its ActionsApiPathPrefix, RetryJitterSeconds and
+// ApiVersion
+func (connection *GithubConnection) CustomValidate(entity interface{}, v *validator.Validate) errors.Error {
+	err := connection.MultiAuth.CustomValidate(entity, v)
+	if err != nil {
+		return err
+	}
+	if connection.RetryJitterSeconds < 0 {
+		return errors.BadInput.New(fmt.Sprintf("retryJitterSeconds must not be negative, got %d", connection.RetryJitterSeconds))
+	}
+	if connection.ApiVersion != "" {
+		// the versions are named after the date they were released
+		if _, err := time.Parse("2006-01-02", connection.ApiVersion); err != nil {
+			return errors.BadInput.New(fmt.Sprintf("apiVersion must be a date like %s, got %s", DefaultApiVersion, connection.ApiVersion))
+		}
+	}
+	return ValidateActionsApiPathPrefix(connection.ActionsApiPathPrefix)
+}
+
+// ValidateActionsApiPathPrefix makes sure the prefix is a path relative to the endpoint, neither an url nor a
+// template since it is prepended to the url templates of the collectors
+func ValidateActionsApiPathPrefix(prefix string) errors.Error {
+	if prefix == "" {
+		return nil
+	}
+	if strings.Contains(prefix, "://") {
+		return errors.BadInput.New(fmt.Sprintf("actionsApiPathPrefix must be a path without scheme, got %s", prefix))
+	}
+	if strings.ContainsAny(prefix, "{}?#: ") {
+		return errors.BadInput.New(fmt.Sprintf("actionsApiPathPrefix must be a plain path, got %s", prefix))
+	}
+	return nil
 }
 
 const (
@@ -134,6 +233,15 @@ func (connection *GithubConnection) Merge(existed, modified *GithubConnection, b
 	if _, ok := body["enableGraphql"]; ok {
 		existed.EnableGraphql = modified.EnableGraphql
 	}
+	if _, ok := body["actionsApiPathPrefix"]; ok {
+		existed.ActionsApiPathPrefix = modified.ActionsApiPathPrefix
+	}
+	if _, ok := body["retryJitterSeconds"]; ok {
+		existed.RetryJitterSeconds = modified.RetryJitterSeconds
+	}
+	if _, ok := body["apiVersion"]; ok {
+		existed.ApiVersion = modified.ApiVersion
+	}
 	existed.AppId = modified.AppId
 	existed.SecretKey = modified.SecretKey
 	existed.InstallationID = modified.InstallationID
@@ -227,3 +227,70 @@ func TestGithubConnection_Sanitize(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateActionsApiPathPrefix(t *testing.T) {
+	assert.Nil(t, ValidateActionsApiPathPrefix(""))
+	assert.Nil(t, ValidateActionsApiPathPrefix("github-actions"))
+	assert.Nil(t, ValidateActionsApiPathPrefix("/gateway/actions/"))
+	assert.NotNil(t, ValidateActionsApiPathPrefix("https://gateway.example.com/actions"))
+	assert.NotNil(t, ValidateActionsApiPathPrefix("gateway.example.com:8443/actions"))
+	assert.NotNil(t, ValidateActionsApiPathPrefix("{{ .Params.Name }}"))
+}
+
+func TestGithubConnection_CustomValidateActionsApiPathPrefix(t *testing.T) {
+	connection := &GithubConnection{}
+	connection.AuthMethod = "AccessToken"
+	connection.Endpoint = "https://github.example.com/api/v3/"
+	connection.Name = "test"
+	connection.Token = "some_token"
+	connection.ActionsApiPathPrefix = "actions-gateway"
+	assert.Nil(t, connection.CustomValidate(connection, validator.New()))
+	connection.ActionsApiPathPrefix = "https://actions-gateway"
+	assert.NotNil(t, connection.CustomValidate(connection, validator.New()))
+}
+
+func TestGithubConnection_CustomValidateRetryJitterSeconds(t *testing.T) {
+	connection := &GithubConnection{}
+	connection.AuthMethod = "AccessToken"
+	connection.Endpoint = "https://api.github.com/"
+	connection.Name = "test"
+	connection.Token = "some_token"
+	connection.RetryJitterSeconds = 10
+	assert.Nil(t, connection.CustomValidate(connection, validator.New()))
+	connection.RetryJitterSeconds = -1
+	assert.NotNil(t, connection.CustomValidate(connection, validator.New()))
+}
+
+func TestGithubConnection_ApiVersion(t *testing.T) {
+	connection := &GithubConnection{}
+	connection.AuthMethod = "AccessToken"
+	connection.Endpoint = "https://api.github.com/"
+	connection.Name = "test"
+	connection.Token = "some_token"
+	assert.Equal(t, DefaultApiVersion, connection.GetApiVersion())
+	assert.Nil(t, connection.CustomValidate(connection, validator.New()))
+
+	connection.ApiVersion = "2026-03-10"
+	assert.Equal(t, "2026-03-10", connection.GetApiVersion())
+	assert.Nil(t, connection.CustomValidate(connection, validator.New()))
+	connection.ApiVersion = "v3"
+	assert.NotNil(t, connection.CustomValidate(connection, validator.New()))
+}
+
+func TestGithubConn_TokensLockPerConnection(t *testing.T) {
+	first := &GithubConn{}
+	first.AuthMethod = AccessToken
+	first.Token = "token-1,token-2"
+	second := &GithubConn{}
+	second.AuthMethod = AccessToken
+	second.Token = "token-3"
+	assert.Nil(t, first.PrepareApiClient(nil))
+	assert.Nil(t, second.PrepareApiClient(nil))
+
+	// the requests of unrelated connections don't wait for each other
+	assert.NotSame(t, first.tokensLock, second.tokensLock)
+	unlock := first.lockTokens()
+	defer unlock()
+	assert.True(t, second.tokensLock.TryLock())
+	second.tokensLock.Unlock()
+}
@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubDeploymentStatus is a status of a deployment collected by the rest api, the latest one tells
+// the outcome of the deployment
+type GithubDeploymentStatus struct {
+	common.NoPKModel
+	ConnectionId uint64    `gorm:"primaryKey"`
+	GithubId     int       `gorm:"index"`
+	DeploymentId uint      `gorm:"index"`
+	Id           int64     `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	State        string    `json:"state" gorm:"type:varchar(255)"`
+	Environment  string    `json:"environment" gorm:"type:varchar(255)"`
+	Description  string    `json:"description" gorm:"type:text"`
+	LogUrl       string    `json:"log_url" gorm:"type:varchar(255)"`
+	CreatedDate  time.Time `json:"created_at"`
+	UpdatedDate  time.Time `json:"updated_at"`
+}
+
+func (GithubDeploymentStatus) TableName() string {
+	return "_tool_github_deployment_statuses"
+}
@@ -0,0 +1,42 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"gorm.io/datatypes"
+)
+
+// GithubEnvironment is a deployment environment configured in the settings of the repo
+type GithubEnvironment struct {
+	common.NoPKModel
+	ConnectionId    uint64         `gorm:"primaryKey"`
+	RepoId          int            `gorm:"primaryKey"`
+	ID              int64          `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	Name            string         `json:"name" gorm:"type:varchar(255)"`
+	WaitTimer       int            `json:"-"` // minutes, of the wait_timer protection rule
+	ProtectionRules datatypes.JSON `json:"protection_rules"`
+	GithubCreatedAt *time.Time     `json:"created_at"`
+	GithubUpdatedAt *time.Time     `json:"updated_at"`
+}
+
+func (GithubEnvironment) TableName() string {
+	return "_tool_github_environments"
+}
@@ -0,0 +1,47 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubFlakyJob is a job of a workflow which both succeeded and failed on the same commit, across the attempts of
+// a run or across the runs of the commit
+type GithubFlakyJob struct {
+	common.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	HeadSha      string `gorm:"primaryKey;type:varchar(255)"`
+	WorkflowId   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name         string `gorm:"primaryKey;type:varchar(255)"`
+	WorkflowName string `gorm:"type:varchar(255)"`
+	SuccessCount int
+	FailureCount int
+	// FlipCount is how many times the conclusion went from a success to a failure or back, in the order the
+	// jobs started
+	FlipCount       int
+	LastConclusion  string `gorm:"type:varchar(255)"`
+	LatestStartedAt *time.Time
+}
+
+func (GithubFlakyJob) TableName() string {
+	return "_tool_github_flaky_jobs"
+}
@@ -26,28 +26,50 @@ import (
 
 type GithubJob struct {
 	common.NoPKModel
-	ConnectionId  uint64         `gorm:"primaryKey"`
-	RepoId        int            `gorm:"primaryKey"`
-	ID            int            `json:"id" gorm:"primaryKey;autoIncrement:false"`
-	RunID         int            `json:"run_id"`
-	RunURL        string         `json:"run_url" gorm:"type:varchar(255)"`
-	NodeID        string         `json:"node_id" gorm:"type:varchar(255)"`
-	HeadSha       string         `json:"head_sha" gorm:"type:varchar(255)"`
-	URL           string         `json:"url" gorm:"type:varchar(255)"`
-	HTMLURL       string         `json:"html_url" gorm:"type:varchar(255)"`
-	Status        string         `json:"status" gorm:"type:varchar(255)"`
-	Conclusion    string         `json:"conclusion" gorm:"type:varchar(255)"`
-	StartedAt     *time.Time     `json:"started_at"`
-	CompletedAt   *time.Time     `json:"completed_at"`
-	Name          string         `json:"name" gorm:"type:varchar(255)"`
-	Steps         datatypes.JSON `json:"steps"`
-	CheckRunURL   string         `json:"check_run_url" gorm:"type:varchar(255)"`
-	Labels        datatypes.JSON `json:"labels"`
-	RunnerID      int            `json:"runner_id"`
-	RunnerName    string         `json:"runner_name" gorm:"type:varchar(255)"`
-	RunnerGroupID int            `json:"runner_group_id"`
-	Type          string         `json:"type" gorm:"type:varchar(255)"`
-	Environment   string         `gorm:"type:varchar(255)"`
+	ConnectionId    uint64         `gorm:"primaryKey"`
+	RepoId          int            `gorm:"primaryKey"`
+	ID              int            `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	RunID           int            `json:"run_id"`
+	RunAttempt      int            `json:"run_attempt"` // every attempt of a run has its own job ids
+	RunURL          string         `json:"run_url" gorm:"type:varchar(255)"`
+	RunName         string         `json:"-" gorm:"type:text"` // copied from the run, kept when the run is deleted
+	RunDisplayTitle string         `json:"-" gorm:"type:text"` // copied from the run, i.e. the commit message
+	NodeID          string         `json:"node_id" gorm:"type:varchar(255)"`
+	HeadSha         string         `json:"head_sha" gorm:"type:varchar(255)"`
+	Event           string         `json:"-" gorm:"type:varchar(255)"` // copied from the run, i.e. push
+	URL             string         `json:"url" gorm:"type:varchar(255)"`
+	HTMLURL         string         `json:"html_url" gorm:"type:varchar(255)"`
+	Status          string         `json:"status" gorm:"type:varchar(255)"`
+	Conclusion      string         `json:"conclusion" gorm:"type:varchar(255)"`
+	GithubCreatedAt *time.Time     `json:"created_at"` // when the job was queued
+	StartedAt       *time.Time     `json:"started_at"`
+	CompletedAt     *time.Time     `json:"completed_at"`
+	Name            string         `json:"name" gorm:"type:varchar(1000)"`
+	Steps           datatypes.JSON `json:"steps"`
+	CheckRunURL     string         `json:"check_run_url" gorm:"type:varchar(255)"`
+	Labels          datatypes.JSON `json:"labels"`
+	RunnerID        int            `json:"runner_id"`
+	RunnerName      string         `json:"runner_name" gorm:"type:varchar(255)"`
+	RunnerGroupID   int            `json:"runner_group_id"`
+	RunnerGroupName string         `json:"runner_group_name" gorm:"type:varchar(255)"`
+	Type            string         `json:"type" gorm:"type:varchar(255)"`
+	Environment     string         `gorm:"type:varchar(255)"`
+	// GithubUpdatedAt is the latest of StartedAt and CompletedAt, the api doesn't return when a job was updated
+	GithubUpdatedAt *time.Time `json:"-"`
+	// DurationSec is how many seconds the job ran from StartedAt to CompletedAt, null when either of them is, so the
+	// dashboards don't compute it at query time
+	DurationSec *float64 `json:"-"`
+	// Matrix are the values of the matrix the job was named after, i.e. ["ubuntu-latest", "1.20"] for
+	// `build (ubuntu-latest, 1.20)`, null when it is not a matrix job or the parseJobMatrix option is off
+	Matrix datatypes.JSON `json:"-"`
+	// WorkflowName is the name of the workflow of the run, returned by the recent versions of the api only, it is
+	// null for the jobs collected from an older GitHub Enterprise Server
+	WorkflowName *string `json:"workflow_name" gorm:"type:varchar(255)"`
+	// NoRunnerAvailable is set for the jobs which were likely cancelled after waiting for a runner matching their
+	// labels that never came, see noRunnerAvailable
+	NoRunnerAvailable bool `json:"-"`
+	// RawPayload is the payload the job was extracted from, only kept with the keepRawOnExtract option
+	RawPayload datatypes.JSON `json:"-"`
 }
 
 func (GithubJob) TableName() string {
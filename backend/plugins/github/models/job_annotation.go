@@ -0,0 +1,40 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobAnnotation is an annotation of the check run of a job, i.e. a compiler warning or a failed test reported
+// by a step, the annotations have no id so they are keyed by their location and level
+type GithubJobAnnotation struct {
+	common.NoPKModel
+	ConnectionId    uint64 `gorm:"primaryKey"`
+	RepoId          int    `gorm:"primaryKey"`
+	JobId           int64  `gorm:"primaryKey;autoIncrement:false"`
+	Path            string `json:"path" gorm:"primaryKey;type:varchar(255)"`
+	StartLine       int    `json:"start_line" gorm:"primaryKey;autoIncrement:false"`
+	EndLine         int    `json:"end_line" gorm:"primaryKey;autoIncrement:false"`
+	AnnotationLevel string `json:"annotation_level" gorm:"primaryKey;type:varchar(20)"` // notice, warning or failure
+	Title           string `json:"title" gorm:"type:varchar(255)"`
+	Message         string `json:"message"`
+}
+
+func (GithubJobAnnotation) TableName() string {
+	return "_tool_github_job_annotations"
+}
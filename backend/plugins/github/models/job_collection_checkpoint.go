@@ -0,0 +1,50 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobCollectionCheckpoint records how far an unfinished jobs collection got, so an interrupted
+// collection can be resumed instead of starting over. It is removed once the collection completes
+type GithubJobCollectionCheckpoint struct {
+	common.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	// LastRunId is the last run id, in RunsOrder, that the jobs of all the runs before it were collected
+	LastRunId int64 `json:"last_run_id"`
+	// RunsOrder is the order the runs were collected in, LastRunId means nothing in the other one. Empty for the
+	// checkpoints saved before the order could be set, which were collected oldest first
+	RunsOrder string `json:"runs_order" gorm:"type:varchar(20)"`
+	// StartedAt is when the interrupted collection started, runs updated after it are collected again
+	StartedAt time.Time `json:"started_at"`
+	// Capped tells the collection was not interrupted but stopped after MaxRunsPerRun runs, the next one carries on
+	// with the runs updated since Since, which is nil when they were all selected
+	Capped bool       `json:"capped"`
+	Since  *time.Time `json:"since"`
+	// WindowStart is the start of the window of created_at being collected when the jobs are collected by windows,
+	// the jobs of the runs created before it were all collected
+	WindowStart *time.Time `json:"window_start"`
+}
+
+func (GithubJobCollectionCheckpoint) TableName() string {
+	return "_tool_github_job_collection_checkpoints"
+}
@@ -0,0 +1,40 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobCollectionFailure records a workflow run whose jobs could not be collected, so it
+// can be inspected by operators and re-attempted by the next collection
+type GithubJobCollectionFailure struct {
+	common.NoPKModel
+	ConnectionId uint64    `gorm:"primaryKey"`
+	RepoId       int       `gorm:"primaryKey"`
+	RunId        int64     `gorm:"primaryKey;autoIncrement:false"`
+	HttpStatus   int       `json:"http_status"`
+	ErrorDetail  string    `json:"error_detail" gorm:"type:text"`
+	CollectedAt  time.Time `json:"collected_at"`
+}
+
+func (GithubJobCollectionFailure) TableName() string {
+	return "_tool_github_job_collection_failures"
+}
@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobDurationSummary stores the duration percentiles of the completed jobs of a workflow, so dashboards don't
+// have to compute them over all the jobs on every query
+type GithubJobDurationSummary struct {
+	common.NoPKModel
+	ConnectionId   uint64  `gorm:"primaryKey"`
+	RepoId         int     `gorm:"primaryKey"`
+	WorkflowId     int     `gorm:"primaryKey;autoIncrement:false"`
+	WorkflowName   string  `gorm:"type:varchar(255)"`
+	JobCount       int     `json:"job_count"`
+	P50DurationSec float64 `json:"p50_duration_sec"`
+	P90DurationSec float64 `json:"p90_duration_sec"`
+	// LatestJobUpdatedAt is the latest github_updated_at of the summarized jobs, the summary is computed again
+	// once a job of the workflow is updated after it
+	LatestJobUpdatedAt *time.Time
+}
+
+func (GithubJobDurationSummary) TableName() string {
+	return "_tool_github_job_duration_summaries"
+}
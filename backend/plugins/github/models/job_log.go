@@ -0,0 +1,42 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobLog keeps the tail of the log of a failed job for failure triage
+type GithubJobLog struct {
+	common.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	JobId        int    `gorm:"primaryKey;autoIncrement:false"`
+	RunId        int    `gorm:"index"`
+	LogUrl       string `gorm:"type:varchar(255)"`
+	LogTail      string `gorm:"type:text"`
+	LogSize      int64  // size of the whole log in bytes
+	Truncated    bool   // whether LogTail only holds the end of the log
+	CollectedAt  time.Time
+}
+
+func (GithubJobLog) TableName() string {
+	return "_tool_github_job_logs"
+}
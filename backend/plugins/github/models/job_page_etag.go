@@ -0,0 +1,37 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubJobPageEtag stores the ETag of a page of the jobs of a run, so the next incremental collection requests the
+// page conditionally, a page which didn't change is answered with a 304 which doesn't count against the rate limit
+type GithubJobPageEtag struct {
+	common.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	RunId        int64  `gorm:"primaryKey;autoIncrement:false"`
+	Page         int    `gorm:"primaryKey;autoIncrement:false"`
+	Etag         string `gorm:"type:varchar(255)"`
+}
+
+func (GithubJobPageEtag) TableName() string {
+	return "_tool_github_job_page_etags"
+}
@@ -0,0 +1,63 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addGithubFlakyJobs)(nil)
+
+type flakyJob20261017 struct {
+	archived.NoPKModel
+	ConnectionId    uint64 `gorm:"primaryKey"`
+	RepoId          int    `gorm:"primaryKey"`
+	HeadSha         string `gorm:"primaryKey;type:varchar(255)"`
+	WorkflowId      int    `gorm:"primaryKey;autoIncrement:false"`
+	Name            string `gorm:"primaryKey;type:varchar(255)"`
+	WorkflowName    string `gorm:"type:varchar(255)"`
+	SuccessCount    int
+	FailureCount    int
+	FlipCount       int
+	LastConclusion  string `gorm:"type:varchar(255)"`
+	LatestStartedAt *time.Time
+}
+
+func (flakyJob20261017) TableName() string {
+	return "_tool_github_flaky_jobs"
+}
+
+type addGithubFlakyJobs struct{}
+
+func (*addGithubFlakyJobs) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &flakyJob20261017{})
+}
+
+func (*addGithubFlakyJobs) Version() uint64 {
+	return 20261017235925
+}
+
+func (*addGithubFlakyJobs) Name() string {
+	return "add table _tool_github_flaky_jobs"
+}
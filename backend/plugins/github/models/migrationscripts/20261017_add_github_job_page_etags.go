@@ -0,0 +1,55 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addGithubJobPageEtags)(nil)
+
+type jobPageEtag20261017 struct {
+	archived.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	RunId        int64  `gorm:"primaryKey;autoIncrement:false"`
+	Page         int    `gorm:"primaryKey;autoIncrement:false"`
+	Etag         string `gorm:"type:varchar(255)"`
+}
+
+func (jobPageEtag20261017) TableName() string {
+	return "_tool_github_job_page_etags"
+}
+
+type addGithubJobPageEtags struct{}
+
+func (*addGithubJobPageEtags) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &jobPageEtag20261017{})
+}
+
+func (*addGithubJobPageEtags) Version() uint64 {
+	return 20261017235915
+}
+
+func (*addGithubJobPageEtags) Name() string {
+	return "add table _tool_github_job_page_etags"
+}
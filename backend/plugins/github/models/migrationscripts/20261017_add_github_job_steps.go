@@ -0,0 +1,62 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addGithubJobSteps)(nil)
+
+type jobStep20261017 struct {
+	archived.NoPKModel
+	ConnectionId uint64 `gorm:"primaryKey"`
+	RepoId       int    `gorm:"primaryKey"`
+	JobId        int    `gorm:"primaryKey;autoIncrement:false"`
+	Number       int    `gorm:"primaryKey;autoIncrement:false"`
+	RunId        int    `gorm:"index"`
+	Name         string `gorm:"type:varchar(255)"`
+	Status       string `gorm:"type:varchar(255)"`
+	Conclusion   string `gorm:"type:varchar(255)"`
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+}
+
+func (jobStep20261017) TableName() string {
+	return "_tool_github_job_steps"
+}
+
+type addGithubJobSteps struct{}
+
+func (*addGithubJobSteps) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &jobStep20261017{})
+}
+
+func (*addGithubJobSteps) Version() uint64 {
+	return 20261017100000
+}
+
+func (*addGithubJobSteps) Name() string {
+	return "add table _tool_github_job_steps"
+}
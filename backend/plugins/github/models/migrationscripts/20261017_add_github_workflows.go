@@ -0,0 +1,64 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addGithubWorkflows)(nil)
+
+type workflow20261017 struct {
+	archived.NoPKModel
+	ConnectionId    uint64 `gorm:"primaryKey"`
+	RepoId          int    `gorm:"primaryKey"`
+	ID              int    `gorm:"primaryKey;autoIncrement:false"`
+	NodeID          string `gorm:"type:varchar(255)"`
+	Name            string `gorm:"type:varchar(255)"`
+	Path            string `gorm:"type:varchar(255)"`
+	State           string `gorm:"type:varchar(255)"`
+	URL             string `gorm:"type:varchar(255)"`
+	HTMLURL         string `gorm:"type:varchar(255)"`
+	GithubCreatedAt *time.Time
+	GithubUpdatedAt *time.Time
+	Content         string `gorm:"type:text"`
+}
+
+func (workflow20261017) TableName() string {
+	return "_tool_github_workflows"
+}
+
+type addGithubWorkflows struct{}
+
+func (*addGithubWorkflows) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &workflow20261017{})
+}
+
+func (*addGithubWorkflows) Version() uint64 {
+	return 20261017190000
+}
+
+func (*addGithubWorkflows) Name() string {
+	return "add table _tool_github_workflows"
+}
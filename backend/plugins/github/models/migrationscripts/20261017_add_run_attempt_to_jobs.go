@@ -0,0 +1,52 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addRunAttemptToJobs)(nil)
+
+type job20261017 struct {
+	RunAttempt int
+}
+
+func (job20261017) TableName() string {
+	return "_tool_github_jobs"
+}
+
+type addRunAttemptToJobs struct{}
+
+func (*addRunAttemptToJobs) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&job20261017{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (*addRunAttemptToJobs) Version() uint64 {
+	return 20261017150000
+}
+
+func (*addRunAttemptToJobs) Name() string {
+	return "add run_attempt to _tool_github_jobs"
+}
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*widenJobColumns)(nil)
+
+type widenJobColumns struct{}
+
+func (*widenJobColumns) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	// the names of the matrix jobs include every value of the matrix and easily exceed 255 characters
+	if err := db.ModifyColumnType("_tool_github_jobs", "name", "varchar(1000)"); err != nil {
+		return err
+	}
+	// status and conclusion are restored to the width of the model where they drifted
+	for _, column := range []string{"status", "conclusion"} {
+		if err := db.ModifyColumnType("_tool_github_jobs", column, "varchar(255)"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*widenJobColumns) Version() uint64 {
+	return 20261017235940
+}
+
+func (*widenJobColumns) Name() string {
+	return "widen name, status and conclusion of _tool_github_jobs"
+}
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addRunsOrderToJobCollectionCheckpoints)(nil)
+
+type jobCollectionCheckpointRunsOrder20261018 struct {
+	RunsOrder string `gorm:"type:varchar(20)"`
+}
+
+func (jobCollectionCheckpointRunsOrder20261018) TableName() string {
+	return "_tool_github_job_collection_checkpoints"
+}
+
+type addRunsOrderToJobCollectionCheckpoints struct{}
+
+func (*addRunsOrderToJobCollectionCheckpoints) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&jobCollectionCheckpointRunsOrder20261018{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (*addRunsOrderToJobCollectionCheckpoints) Version() uint64 {
+	return 20261018000300
+}
+
+func (*addRunsOrderToJobCollectionCheckpoints) Name() string {
+	return "add runs_order to _tool_github_job_collection_checkpoints"
+}
@@ -55,5 +55,44 @@ func All() []plugin.MigrationScript {
 		new(addIsDraftToPr),
 		new(changeIssueComponentType),
 		new(addIndexToGithubJobs),
+		new(addGithubJobSteps),
+		new(addGithubJobCollectionFailures),
+		new(addGithubJobLogs),
+		new(addJobsPartialToRuns),
+		new(addGithubJobCollectionCheckpoints),
+		new(addRunAttemptToJobs),
+		new(addGithubRunTimings),
+		new(addGithubDeploymentStatuses),
+		new(addRunnerGroupNameToJobs),
+		new(addGithubWorkflows),
+		new(addActionsApiPathPrefixToConnections),
+		new(addGithubUpdatedAtToJobs),
+		new(addGithubJobDurationSummaries),
+		new(addDefaultBranchToRepos),
+		new(addGithubCheckRuns),
+		new(addRetryJitterSecondsToConnections),
+		new(addGithubRunPullRequests),
+		new(addCappedToJobCollectionCheckpoints),
+		new(addCreatedAtToJobs),
+		new(addGithubRunArtifacts),
+		new(addRawPayloadToJobs),
+		new(addEventToJobs),
+		new(addGithubRunners),
+		new(addMatrixToJobs),
+		new(addGithubJobPageEtags),
+		new(addTriggeringActorToRuns),
+		new(addGithubFlakyJobs),
+		new(addWindowStartToJobCollectionCheckpoints),
+		new(addNoRunnerAvailableToJobs),
+		new(widenJobColumns),
+		new(addGithubRunApprovals),
+		new(addWorkflowNameToJobs),
+		new(addApiVersionToConnections),
+		new(addGithubEnvironments),
+		new(addFailPipelineOnJobCollectionErrorToScopeConfigs),
+		new(addRunNameToJobs),
+		new(addDurationSecToJobs),
+		new(addRunsOrderToJobCollectionCheckpoints),
+		new(addGithubJobAnnotations),
 	}
 }
@@ -39,6 +39,7 @@ type GithubRepo struct {
 	ParentGithubId int        `json:"parentId" mapstructure:"parentGithubId,omitempty"`
 	ParentHTMLUrl  string     `json:"parentHtmlUrl" mapstructure:"parentHtmlUrl,omitempty"`
 	CloneUrl       string     `json:"cloneUrl" gorm:"type:varchar(255)" mapstructure:"cloneUrl,omitempty"`
+	DefaultBranch  string     `json:"defaultBranch" gorm:"type:varchar(255)" mapstructure:"defaultBranch,omitempty"`
 	CreatedDate    *time.Time `json:"createdDate" mapstructure:"createdDate"`
 	UpdatedDate    *time.Time `json:"updatedDate" mapstructure:"updatedDate"`
 }
@@ -56,6 +56,10 @@ type GithubRun struct {
 	WorkflowURL      string     `json:"workflow_url" gorm:"type:varchar(255)"`
 	Type             string     `json:"type" gorm:"type:varchar(255)"`
 	Environment      string     `gorm:"type:varchar(255)"`
+	// TriggeringActor is the login of the user who triggered the run, or re-ran it for the later attempts
+	TriggeringActor string `json:"-" gorm:"type:varchar(255)"`
+	// JobsPartial is set when some of the jobs of the run could not be collected
+	JobsPartial bool `json:"-"`
 }
 
 func (GithubRun) TableName() string {
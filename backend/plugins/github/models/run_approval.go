@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubRunApproval stores who reviewed the deployment of a workflow run to an environment protected by required
+// reviewers. The api doesn't tell when the review happened, so WaitStartedAt and ApprovedAt are the wait of the job
+// of the run which waited the longest, see runApprovalWait
+type GithubRunApproval struct {
+	common.NoPKModel
+	ConnectionId  uint64     `gorm:"primaryKey"`
+	RepoId        int        `gorm:"primaryKey"`
+	RunId         int64      `gorm:"primaryKey;autoIncrement:false"`
+	EnvironmentId int64      `gorm:"primaryKey;autoIncrement:false"`
+	Environment   string     `gorm:"type:varchar(255)"`
+	State         string     `gorm:"type:varchar(100)"` // approved or rejected
+	Approver      string     `gorm:"type:varchar(255)"`
+	Comment       string     `gorm:"type:text"`
+	WaitStartedAt *time.Time // nil when none of the jobs of the run started
+	ApprovedAt    *time.Time
+}
+
+func (GithubRunApproval) TableName() string {
+	return "_tool_github_run_approvals"
+}
@@ -0,0 +1,38 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubRunTiming stores the billable time of a workflow run per runner OS
+type GithubRunTiming struct {
+	common.NoPKModel
+	ConnectionId      uint64 `gorm:"primaryKey"`
+	RepoId            int    `gorm:"primaryKey"`
+	RunId             int64  `gorm:"primaryKey;autoIncrement:false"`
+	UbuntuBillableMs  int64  `json:"ubuntu_billable_ms"`
+	WindowsBillableMs int64  `json:"windows_billable_ms"`
+	MacosBillableMs   int64  `json:"macos_billable_ms"`
+	RunDurationMs     int64  `json:"run_duration_ms"`
+}
+
+func (GithubRunTiming) TableName() string {
+	return "_tool_github_run_timings"
+}
@@ -41,6 +41,10 @@ type GithubScopeConfig struct {
 	EnvNamePattern       string            `mapstructure:"envNamePattern,omitempty" json:"envNamePattern" gorm:"type:varchar(255)"`
 	Refdiff              datatypes.JSONMap `mapstructure:"refdiff,omitempty" json:"refdiff" swaggertype:"object" format:"json"`
 	GracefulDegradation  bool              `mapstructure:"gracefulDegradation,omitempty" json:"gracefulDegradation" gorm:"type:bool"`
+
+	// FailPipelineOnJobCollectionError fails the collection of jobs when the jobs of some runs could not be collected
+	// after the retries, instead of going on with the runs collected, for the dashboards which can't miss any job
+	FailPipelineOnJobCollectionError bool `mapstructure:"failPipelineOnJobCollectionError,omitempty" json:"failPipelineOnJobCollectionError" gorm:"type:bool"`
 }
 
 // GetConnectionId implements plugin.ToolLayerScopeConfig.
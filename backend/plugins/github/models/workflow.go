@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// GithubWorkflow is a workflow of GitHub Actions along with the YAML of its file, the runs refer to it by WorkflowID
+type GithubWorkflow struct {
+	common.NoPKModel
+	ConnectionId    uint64     `gorm:"primaryKey"`
+	RepoId          int        `gorm:"primaryKey"`
+	ID              int        `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	NodeID          string     `json:"node_id" gorm:"type:varchar(255)"`
+	Name            string     `json:"name" gorm:"type:varchar(255)"`
+	Path            string     `json:"path" gorm:"type:varchar(255)"`
+	State           string     `json:"state" gorm:"type:varchar(255)"`
+	URL             string     `json:"url" gorm:"type:varchar(255)"`
+	HTMLURL         string     `json:"html_url" gorm:"type:varchar(255)"`
+	GithubCreatedAt *time.Time `json:"created_at"`
+	GithubUpdatedAt *time.Time `json:"updated_at"`
+	Content         string     `json:"-" gorm:"type:text"`
+}
+
+func (GithubWorkflow) TableName() string {
+	return "_tool_github_workflows"
+}
@@ -18,8 +18,11 @@ limitations under the License.
 package tasks
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/apache/incubator-devlake/core/errors"
@@ -81,5 +84,77 @@ func CreateApiClient(taskCtx plugin.TaskContext, connection *models.GithubConnec
 	if err != nil {
 		return nil, err
 	}
+	RefreshExpiredToken(asyncApiClient, newTokenRefresher(taskCtx, connection), connection.SetupAuthentication, taskCtx.GetLogger())
+	asyncApiClient.SetRetryJitter(time.Duration(connection.RetryJitterSeconds) * time.Second)
+	asyncApiClient.SetHeaders(withApiVersionHeader(asyncApiClient.GetHeaders(), connection.GetApiVersion()))
 	return asyncApiClient, nil
 }
+
+// withApiVersionHeader returns a copy of the headers of the api client along with the version of the REST API to
+// request, the headers are shared by all the requests of the subtasks
+func withApiVersionHeader(headers map[string]string, version string) map[string]string {
+	versioned := make(map[string]string, len(headers)+1)
+	for name, value := range headers {
+		versioned[name] = value
+	}
+	versioned["X-GitHub-Api-Version"] = version
+	return versioned
+}
+
+// MAX_DOWNLOAD_REDIRECTS is how many redirects a download of a log follows, the same as the default of net/http
+const MAX_DOWNLOAD_REDIRECTS = 10
+
+// newDownloadClient returns a copy of the http client of the api client which follows the redirects of the downloads
+// of the logs to the blob storage. The copy shares the transport, so every hop goes through the proxy of the
+// connection, and the credentials are stripped once a redirect leaves the host of the api
+func newDownloadClient(apiClient *api.ApiClient) *http.Client {
+	client := apiClient.GetHttpClient()
+	client.CheckRedirect = crossHostRedirectPolicy
+	return client
+}
+
+// crossHostRedirectPolicy strips the Authorization header from a redirect to another host than the one of the first
+// request, the download urls are pre-signed and the token must not leak to the blob storage. net/http keeps it for
+// the subdomains of the host, i.e. a GitHub Enterprise Server storing its blobs on a subdomain, so it is done here
+func crossHostRedirectPolicy(req *http.Request, via []*http.Request) error {
+	if len(via) >= MAX_DOWNLOAD_REDIRECTS {
+		return fmt.Errorf("stopped after %d redirects", MAX_DOWNLOAD_REDIRECTS)
+	}
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+// newTokenRefresher returns a function minting a new installation token after a request of a GitHub App connection
+// was rejected with a 401, see RefreshExpiredToken. It returns nil for the other connections since their tokens
+// can't be refreshed
+func newTokenRefresher(taskCtx plugin.TaskContext, connection *models.GithubConnection) func(res *http.Response) errors.Error {
+	if connection.AuthMethod != models.AppKey || connection.InstallationID == 0 {
+		return nil
+	}
+	var once sync.Once
+	var apiClient *api.ApiClient
+	var clientErr errors.Error
+	return func(res *http.Response) errors.Error {
+		// the api client of the task can't be used, its authentication would replace the JWT
+		once.Do(func() {
+			apiClient, clientErr = api.NewApiClient(taskCtx.GetContext(), connection.GetEndpoint(), nil, time.Minute, connection.Proxy, taskCtx)
+		})
+		if clientErr != nil {
+			return clientErr
+		}
+		rejectedAuthorization := ""
+		if res.Request != nil {
+			rejectedAuthorization = res.Request.Header.Get("Authorization")
+		}
+		refreshed, err := connection.RefreshInstallationToken(apiClient, rejectedAuthorization)
+		if err != nil {
+			return err
+		}
+		if refreshed {
+			taskCtx.GetLogger().Info("the installation token of connection %d expired and was refreshed", connection.ID)
+		}
+		return nil
+	}
+}
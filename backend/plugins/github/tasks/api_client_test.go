@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithApiVersionHeader(t *testing.T) {
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	versioned := withApiVersionHeader(headers, "2022-11-28")
+	assert.Equal(t, map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}, versioned)
+	// the headers of the client are not changed in place
+	assert.Len(t, headers, 1)
+
+	assert.Equal(t, map[string]string{"X-GitHub-Api-Version": "2022-11-28"}, withApiVersionHeader(nil, "2022-11-28"))
+}
+
+func TestCrossHostRedirectPolicy(t *testing.T) {
+	newRequest := func(rawUrl string) *http.Request {
+		u, err := url.Parse(rawUrl)
+		assert.Nil(t, err)
+		req := &http.Request{URL: u, Header: http.Header{}}
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Accept", "application/vnd.github+json")
+		return req
+	}
+	via := []*http.Request{newRequest("https://api.github.com/repos/a/b/actions/jobs/1/logs")}
+
+	// the blob storage doesn't get the token
+	req := newRequest("https://productionresultssa0.blob.core.windows.net/actions-results/signed")
+	assert.Nil(t, crossHostRedirectPolicy(req, via))
+	assert.Empty(t, req.Header.Get("Authorization"))
+	assert.Equal(t, "application/vnd.github+json", req.Header.Get("Accept"))
+
+	// neither does a subdomain, nor another port, of the host of the api
+	req = newRequest("https://storage.api.github.com/signed")
+	assert.Nil(t, crossHostRedirectPolicy(req, via))
+	assert.Empty(t, req.Header.Get("Authorization"))
+	req = newRequest("https://api.github.com:8443/signed")
+	assert.Nil(t, crossHostRedirectPolicy(req, via))
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// a redirect within the api keeps it
+	req = newRequest("https://API.github.com/repositories/1/actions/jobs/1/logs")
+	assert.Nil(t, crossHostRedirectPolicy(req, via))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	// a redirect loop is stopped
+	loop := make([]*http.Request, MAX_DOWNLOAD_REDIRECTS)
+	for i := range loop {
+		loop[i] = via[0]
+	}
+	assert.NotNil(t, crossHostRedirectPolicy(newRequest("https://api.github.com/loop"), loop))
+}
@@ -0,0 +1,183 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CheckActionsAccessMeta)
+}
+
+// ACTIONS_ACCESS_CHECK is not a table, the collectors of the actions api depend on it to run after the check
+const ACTIONS_ACCESS_CHECK = "github_actions_access_check"
+
+var CheckActionsAccessMeta = plugin.SubTaskMeta{
+	Name:             "Check Actions Access",
+	EntryPoint:       CheckActionsAccess,
+	EnabledByDefault: true,
+	Description:      "Check that the token is allowed to read the Github actions of the repo before collecting them",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{},
+	ProductTables:    []string{ACTIONS_ACCESS_CHECK},
+}
+
+// ActionsDisabledError tells that GitHub Actions are disabled for the repo, by itself or by its organization, the
+// api answers 404 for its runs just like for a deleted run
+type ActionsDisabledError struct {
+	Repo string
+}
+
+func (e *ActionsDisabledError) Error() string {
+	return fmt.Sprintf("GitHub Actions are disabled for %s, its runs and jobs are not collected", e.Repo)
+}
+
+// CheckActionsAccess checks whether GitHub Actions are enabled for the repo, then lists a single run of the repo. The
+// repo is skipped by the collectors of the actions api when they are disabled, and it fails right away when the token
+// is not allowed to read the actions, instead of the collectors warning about every run
+func CheckActionsAccess(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+	logger := taskCtx.GetLogger()
+	permissions, err := data.ApiClient.Get(actionsApiPath(data, fmt.Sprintf("repos/%s/actions/permissions", data.Options.Name)), nil, nil)
+	if err != nil {
+		logger.Warn(err, "failed to check whether the actions of %s are enabled, going on with the collection", data.Options.Name)
+	} else {
+		defer permissions.Body.Close()
+		data.ActionsDisabled = checkActionsEnabled(data.Options.Name, permissions)
+		if data.ActionsDisabled != nil {
+			logger.Warn(nil, "%s", data.ActionsDisabled.Error())
+			return nil
+		}
+	}
+
+	query := url.Values{}
+	query.Set("per_page", "1")
+	res, err := data.ApiClient.Get(actionsApiPath(data, fmt.Sprintf("repos/%s/actions/runs", data.Options.Name)), query, nil)
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to check the access to the actions of %s", data.Options.Name))
+	}
+	defer res.Body.Close()
+	return checkActionsAccess(logger, data.Options.Name, res)
+}
+
+// checkActionsEnabled returns an ActionsDisabledError when the actions permissions of the repo tell that GitHub Actions
+// are disabled. Reading them needs the administration permission of the repo, so nothing is known without it
+func checkActionsEnabled(repoName string, res *http.Response) *ActionsDisabledError {
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil
+	}
+	permissions := &struct {
+		Enabled *bool `json:"enabled"`
+	}{}
+	if json.Unmarshal(body, permissions) != nil || permissions.Enabled == nil || *permissions.Enabled {
+		return nil
+	}
+	return &ActionsDisabledError{Repo: repoName}
+}
+
+// ACCEPTED_PERMISSIONS_HEADER lists the permissions of a fine-grained token or a GitHub App the endpoint accepts
+const ACCEPTED_PERMISSIONS_HEADER = "X-Accepted-GitHub-Permissions"
+
+// missingPermissionsError names the permissions the token is missing to read what from the
+// X-Accepted-GitHub-Permissions header of the 403 or 404 it was answered with, i.e. `actions=read`, nil when the
+// header is absent. The sets of permissions accepted alternatively are separated by `;` in the header
+func missingPermissionsError(what string, res *http.Response) errors.Error {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusNotFound {
+		return nil
+	}
+	var permissions []string
+	for _, set := range strings.Split(res.Header.Get(ACCEPTED_PERMISSIONS_HEADER), ";") {
+		if set = strings.TrimSpace(set); set != "" {
+			permissions = append(permissions, fmt.Sprintf("`%s`", set))
+		}
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	return errors.Forbidden.New(fmt.Sprintf("the token is missing the permissions to read %s, GitHub accepts %s. "+
+		"Please grant them to the fine-grained token or the GitHub App", what, strings.Join(permissions, " or ")))
+}
+
+// archivedRepoMessage returns the message of the 403 GitHub rejects a request to an archived repo with, i.e.
+// `Repository was archived so is read-only.`, empty for the other responses. The body is left to be read again
+func archivedRepoMessage(res *http.Response) string {
+	if res.StatusCode != http.StatusForbidden || res.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	message := &struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(body, message) != nil || !strings.Contains(strings.ToLower(message.Message), "archived") {
+		return ""
+	}
+	return message.Message
+}
+
+// checkActionsAccess tells why the token can't read the actions of the repo from the response, the errors of the
+// server side are left to the collectors to retry
+func checkActionsAccess(logger log.Logger, repoName string, res *http.Response) errors.Error {
+	if !isJobsRateLimited(res) {
+		if err := missingPermissionsError(fmt.Sprintf("the actions of %s", repoName), res); err != nil {
+			return err
+		}
+	}
+	if message := archivedRepoMessage(res); message != "" {
+		// reading the actions is allowed for an archived repo, the 403 is not the one of a token lacking permissions
+		logger.Warn(nil, "checking the access to the actions of archived repository %s returned 403: %s, "+
+			"going on with the collection", repoName, message)
+		return nil
+	}
+	switch {
+	case res.StatusCode == http.StatusUnauthorized:
+		return errors.Unauthorized.New(fmt.Sprintf("the token was rejected reading the actions of %s, "+
+			"please check that it is valid and not expired", repoName))
+	case res.StatusCode == http.StatusForbidden && !isJobsRateLimited(res):
+		return errors.Forbidden.New(fmt.Sprintf("the token is not allowed to read the actions of %s. A classic token "+
+			"needs the `repo` scope, or `public_repo` for a public repository, and a fine-grained token the `Actions` "+
+			"read permission of the repository", repoName))
+	case res.StatusCode == http.StatusNotFound:
+		return errors.NotFound.New(fmt.Sprintf("the actions of %s were not found, either the repository does not exist "+
+			"or the token is not allowed to read it. A fine-grained token needs the `Actions` read permission of the "+
+			"repository", repoName))
+	case res.StatusCode >= 400:
+		logger.Warn(nil, "checking the access to the actions of %s returned %d, going on with the collection", repoName, res.StatusCode)
+	default:
+		logger.Info("the token is allowed to read the actions of %s", repoName)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockplugin "github.com/apache/incubator-devlake/mocks/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckActionsAccess(t *testing.T) {
+	logger := unithelper.DummyLogger()
+	newResponse := func(statusCode int, header http.Header) *http.Response {
+		return &http.Response{StatusCode: statusCode, Header: header}
+	}
+
+	assert.Nil(t, checkActionsAccess(logger, "a/b", newResponse(http.StatusOK, http.Header{})))
+
+	err := checkActionsAccess(logger, "a/b", newResponse(http.StatusUnauthorized, http.Header{}))
+	assert.Equal(t, errors.Unauthorized, err.GetType())
+
+	err = checkActionsAccess(logger, "a/b", newResponse(http.StatusForbidden, http.Header{}))
+	assert.Equal(t, errors.Forbidden, err.GetType())
+	assert.Contains(t, err.Error(), "`Actions` read permission")
+
+	err = checkActionsAccess(logger, "a/b", newResponse(http.StatusNotFound, http.Header{}))
+	assert.Equal(t, errors.NotFound, err.GetType())
+
+	// the 403 of an archived repo isn't taken for a token lacking permissions
+	archived := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"message":"Repository was archived so is read-only."}`)),
+	}
+	assert.Nil(t, checkActionsAccess(logger, "a/b", archived))
+	assert.Equal(t, "", archivedRepoMessage(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Body:       io.NopCloser(strings.NewReader(`{"message":"Resource not accessible by integration"}`)),
+	}))
+
+	// a fine-grained token is told which permission it lacks
+	accepted := http.Header{}
+	accepted.Set(ACCEPTED_PERMISSIONS_HEADER, "actions=read")
+	err = checkActionsAccess(logger, "a/b", newResponse(http.StatusForbidden, accepted))
+	assert.Equal(t, errors.Forbidden, err.GetType())
+	assert.Contains(t, err.Error(), "the token is missing the permissions to read the actions of a/b, GitHub accepts `actions=read`")
+	err = checkActionsAccess(logger, "a/b", newResponse(http.StatusNotFound, accepted))
+	assert.Equal(t, errors.Forbidden, err.GetType())
+
+	// the rate limit and the errors of the server side don't stop the collection
+	rateLimited := http.Header{}
+	rateLimited.Set("X-RateLimit-Remaining", "0")
+	assert.Nil(t, checkActionsAccess(logger, "a/b", newResponse(http.StatusForbidden, rateLimited)))
+	assert.Nil(t, checkActionsAccess(logger, "a/b", newResponse(http.StatusBadGateway, http.Header{})))
+}
+
+func TestCheckActionsEnabled(t *testing.T) {
+	newResponse := func(statusCode int, body string) *http.Response {
+		return &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	assert.Nil(t, checkActionsEnabled("a/b", newResponse(http.StatusOK, `{"enabled":true,"allowed_actions":"all"}`)))
+	disabled := checkActionsEnabled("a/b", newResponse(http.StatusOK, `{"enabled":false}`))
+	if assert.NotNil(t, disabled) {
+		assert.Equal(t, "GitHub Actions are disabled for a/b, its runs and jobs are not collected", disabled.Error())
+	}
+	// the token lacks the administration permission, or the body isn't understood
+	assert.Nil(t, checkActionsEnabled("a/b", newResponse(http.StatusForbidden, `{"message":"Resource not accessible by integration"}`)))
+	assert.Nil(t, checkActionsEnabled("a/b", newResponse(http.StatusOK, `{}`)))
+	assert.Nil(t, checkActionsEnabled("a/b", newResponse(http.StatusOK, `not json`)))
+}
+
+func TestCollectJobsSkipsActionsDisabled(t *testing.T) {
+	taskCtx := mockplugin.NewSubTaskContext(t)
+	taskCtx.On("GetLogger").Return(unithelper.DummyLogger())
+	taskCtx.On("GetName").Return("Collect Jobs")
+	data := &GithubTaskData{ActionsDisabled: &ActionsDisabledError{Repo: "a/b"}}
+
+	assert.Nil(t, collectJobs(taskCtx, data))
+	assert.Equal(t, data.ActionsDisabled.Error(), data.JobCollectionResult.Notice)
+	assert.False(t, skipActionsDisabled(taskCtx, &GithubTaskData{}))
+}
+
+func TestCollectJobAnnotationsSkipsActionsDisabled(t *testing.T) {
+	taskCtx := mockplugin.NewSubTaskContext(t)
+	taskCtx.On("GetData").Return(&GithubTaskData{ActionsDisabled: &ActionsDisabledError{Repo: "a/b"}})
+	taskCtx.On("GetLogger").Return(unithelper.DummyLogger())
+	taskCtx.On("GetName").Return("Collect Job Annotations")
+
+	// the annotations are those of the check runs of the jobs
+	assert.Nil(t, CollectJobAnnotations(taskCtx))
+}
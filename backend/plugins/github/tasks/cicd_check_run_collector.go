@@ -0,0 +1,136 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectCheckRunsMeta)
+}
+
+const RAW_CHECK_RUN_TABLE = "github_api_check_runs"
+
+var CollectCheckRunsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Check Runs",
+	EntryPoint:       CollectCheckRuns,
+	EnabledByDefault: false,
+	Description:      "Collect check runs of the commits from Github api, supports both timeFilter and diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubCommit{}.TableName(),     // cursor
+		models.GithubRepoCommit{}.TableName(), // cursor
+	},
+	ProductTables: []string{RAW_CHECK_RUN_TABLE},
+}
+
+type SimpleGithubCommit struct {
+	Sha string
+}
+
+type GithubRawCheckRunsResult struct {
+	TotalCount int64             `json:"total_count"`
+	CheckRuns  []json.RawMessage `json:"check_runs"`
+}
+
+// CollectCheckRuns collects the check runs of the commits of the repo, one request per commit, so it is disabled
+// by default. The commits committed since the last collection are requested in incremental mode
+func CollectCheckRuns(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	logger := taskCtx.GetLogger()
+
+	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_CHECK_RUN_TABLE,
+	})
+	if err != nil {
+		return err
+	}
+
+	clauses := []dal.Clause{
+		dal.Select("c.sha"),
+		dal.From("_tool_github_commits c"),
+		dal.Join("JOIN _tool_github_repo_commits rc ON rc.commit_sha = c.sha"),
+		dal.Where("rc.repo_id = ? AND rc.connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+	}
+	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
+		clauses = append(clauses, dal.Where("c.committed_date > ?", apiCollector.GetSince()))
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubCommit{}))
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Input:       iterator,
+		UrlTemplate: "repos/{{ .Params.Name }}/commits/{{ .Input.Sha }}/check-runs",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			body := &GithubRawCheckRunsResult{}
+			err := api.UnmarshalResponse(res, body)
+			if err != nil {
+				return nil, err
+			}
+			return body.CheckRuns, nil
+		},
+		AfterResponse: func(res *http.Response) errors.Error {
+			if res.StatusCode == http.StatusUnauthorized {
+				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+			}
+			// the commit is not in the repo anymore, i.e. after a force push
+			if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusUnprocessableEntity {
+				logger.Warn(nil, "commit not found (%d) at %s. Skipping...", res.StatusCode, res.Request.URL.Path)
+				return api.ErrIgnoreAndContinue
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return apiCollector.Execute()
+}
@@ -0,0 +1,140 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertCheckRunsMeta)
+}
+
+var ConvertCheckRunsMeta = plugin.SubTaskMeta{
+	Name:             "Convert Check Runs",
+	EntryPoint:       ConvertCheckRuns,
+	EnabledByDefault: false,
+	Description:      "Convert tool layer table github_check_runs of the CI apps other than GitHub Actions into domain layer table cicd_pipelines",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubCheckRun{}.TableName(), // cursor
+		models.GithubJob{}.TableName(),      // deduplication
+		RAW_CHECK_RUN_TABLE,
+	},
+	ProductTables: []string{
+		devops.CICDPipeline{}.TableName(),
+		devops.CiCDPipelineCommit{}.TableName(),
+	},
+}
+
+// GITHUB_ACTIONS_APP_SLUG is the app registering the check runs of the Actions jobs
+const GITHUB_ACTIONS_APP_SLUG = "github-actions"
+
+// ConvertCheckRuns converts the check runs which are not Actions jobs, those are converted from the jobs already.
+// A check run of an Actions job has the id of the job, so the ones registered by other apps for the jobs are skipped too
+func ConvertCheckRuns(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	repo := &models.GithubRepo{}
+	err := db.First(repo, dal.Where("connection_id = ? AND github_id = ?", data.Options.ConnectionId, data.Options.GithubId))
+	if err != nil {
+		return err
+	}
+
+	cursor, err := db.Cursor(
+		dal.Select("c.*"),
+		dal.From("_tool_github_check_runs c"),
+		dal.Join(`LEFT JOIN _tool_github_jobs j
+			ON j.connection_id = c.connection_id AND j.repo_id = c.repo_id AND j.id = c.id`),
+		dal.Where("c.repo_id = ? AND c.connection_id = ? AND c.app_slug != ? AND j.id IS NULL",
+			data.Options.GithubId, data.Options.ConnectionId, GITHUB_ACTIONS_APP_SLUG),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	repoIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
+	checkRunIdGen := didgen.NewDomainIdGenerator(&models.GithubCheckRun{})
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_CHECK_RUN_TABLE,
+		},
+		InputRowType: reflect.TypeOf(models.GithubCheckRun{}),
+		Input:        cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			line := inputRow.(*models.GithubCheckRun)
+			// queued check runs have no date to place them at
+			if line.StartedAt == nil {
+				return nil, nil
+			}
+			pipelineId := checkRunIdGen.Generate(data.Options.ConnectionId, line.RepoId, line.ID)
+			domainPipeline := &devops.CICDPipeline{
+				DomainEntity: domainlayer.DomainEntity{Id: pipelineId},
+				Name:         line.Name,
+				TaskDatesInfo: devops.TaskDatesInfo{
+					CreatedDate:  *line.StartedAt,
+					StartedDate:  line.StartedAt,
+					FinishedDate: line.CompletedAt,
+				},
+				CicdScopeId:    repoIdGen.Generate(data.Options.ConnectionId, line.RepoId),
+				Type:           data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, line.Name),
+				Environment:    data.RegexEnricher.ReturnNameIfOmittedOrMatched(devops.PRODUCTION, line.Name),
+				Result:         getJobResult(line.Conclusion),
+				OriginalResult: line.Conclusion,
+				Status:         devops.GetStatus(jobStatusRule, line.Status),
+				OriginalStatus: line.Status,
+				Url:            line.HTMLURL,
+			}
+			if line.CompletedAt != nil && !line.CompletedAt.Before(*line.StartedAt) {
+				domainPipeline.DurationSec = float64(line.CompletedAt.Sub(*line.StartedAt).Milliseconds() / 1e3)
+			}
+			domainPipelineCommit := &devops.CiCDPipelineCommit{
+				PipelineId: pipelineId,
+				CommitSha:  line.HeadSha,
+				RepoId:     repoIdGen.Generate(data.Options.ConnectionId, line.RepoId),
+				RepoUrl:    repo.HTMLUrl,
+				Url:        line.HTMLURL,
+			}
+			return []interface{}{
+				domainPipeline,
+				domainPipelineCommit,
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
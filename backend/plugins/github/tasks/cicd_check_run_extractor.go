@@ -0,0 +1,96 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractCheckRunsMeta)
+}
+
+var ExtractCheckRunsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Check Runs",
+	EntryPoint:       ExtractCheckRuns,
+	EnabledByDefault: false,
+	Description:      "Extract raw check runs data into tool layer table github_check_runs",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_CHECK_RUN_TABLE},
+	ProductTables:    []string{models.GithubCheckRun{}.TableName()},
+}
+
+type githubRawCheckRun struct {
+	models.GithubCheckRun
+	App *struct {
+		Slug string `json:"slug"`
+	} `json:"app"`
+}
+
+func ExtractCheckRuns(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_CHECK_RUN_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			checkRun, err := extractCheckRun(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			checkRun.ConnectionId = data.Options.ConnectionId
+			checkRun.RepoId = data.Options.GithubId
+			return []interface{}{checkRun}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractCheckRun parses a check run, the statuses are stored uppercase like the ones of jobs
+func extractCheckRun(body json.RawMessage) (*models.GithubCheckRun, errors.Error) {
+	rawCheckRun := &githubRawCheckRun{}
+	err := errors.Convert(json.Unmarshal(body, rawCheckRun))
+	if err != nil {
+		return nil, err
+	}
+	checkRun := &rawCheckRun.GithubCheckRun
+	checkRun.Status = strings.ToUpper(checkRun.Status)
+	checkRun.Conclusion = strings.ToUpper(checkRun.Conclusion)
+	checkRun.StartedAt = api.NormalizeNullableTime(checkRun.StartedAt)
+	checkRun.CompletedAt = api.NormalizeNullableTime(checkRun.CompletedAt)
+	if rawCheckRun.App != nil {
+		checkRun.AppSlug = rawCheckRun.App.Slug
+	}
+	return checkRun, nil
+}
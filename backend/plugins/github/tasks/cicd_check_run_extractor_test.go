@@ -0,0 +1,52 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCheckRun(t *testing.T) {
+	checkRun, err := extractCheckRun(json.RawMessage(`{
+		"id": 4,
+		"head_sha": "ce587453ced02b1526dfb4cb910479d431683101",
+		"name": "ci/circleci: build",
+		"status": "completed",
+		"conclusion": "success",
+		"started_at": "2024-03-01T10:00:00Z",
+		"completed_at": "2024-03-01T10:05:00Z",
+		"html_url": "https://circleci.com/gh/a/b/1",
+		"app": {"id": 1, "slug": "circleci-checks"}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), checkRun.ID)
+	assert.Equal(t, "COMPLETED", checkRun.Status)
+	assert.Equal(t, "SUCCESS", checkRun.Conclusion)
+	assert.Equal(t, "circleci-checks", checkRun.AppSlug)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC), checkRun.CompletedAt.UTC())
+
+	// queued check runs have neither a start nor an app installed anymore
+	checkRun, err = extractCheckRun(json.RawMessage(`{"id": 5, "status": "queued", "conclusion": null, "started_at": null, "app": null}`))
+	assert.Nil(t, err)
+	assert.Nil(t, checkRun.StartedAt)
+	assert.Equal(t, "", checkRun.AppSlug)
+}
@@ -0,0 +1,97 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&PruneDeletedRunJobsMeta)
+}
+
+var PruneDeletedRunJobsMeta = plugin.SubTaskMeta{
+	Name:             "Prune Jobs of Deleted Runs",
+	EntryPoint:       PruneDeletedRunJobs,
+	EnabledByDefault: false,
+	Description:      "Delete the jobs and steps of the runs reported deleted (404) by the job collection, opt-in to keep the history otherwise",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubJobCollectionFailure{}.TableName(), // the deleted runs
+		models.GithubJobStep{}.TableName(),              // pruned once the jobs are extracted
+	},
+	// the jobs are not listed as a dependency to avoid a cycle, listing them as a product makes the jobs
+	// convertors run after the pruning
+	ProductTables: []string{models.GithubJob{}.TableName()},
+}
+
+func PruneDeletedRunJobs(taskCtx plugin.SubTaskContext) errors.Error {
+	return forEachJobsScope(taskCtx, pruneDeletedRunJobs)
+}
+
+func pruneDeletedRunJobs(taskCtx plugin.SubTaskContext, data *GithubTaskData) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+
+	var runIds []int64
+	err := db.Pluck("run_id", &runIds,
+		dal.From(&models.GithubJobCollectionFailure{}),
+		dal.Where("repo_id = ? AND connection_id = ? AND http_status = ?",
+			data.Options.GithubId, data.Options.ConnectionId, http.StatusNotFound),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load the deleted runs")
+	}
+	pruned, err := pruneJobsOfRuns(db, data.Options, runIds)
+	if err != nil {
+		return err
+	}
+	logger.Info("pruned %d jobs of %d deleted runs of %s", pruned, len(runIds), data.Options.Name)
+	return nil
+}
+
+// pruneJobsOfRuns deletes the jobs, the steps and the ETags of the pages of jobs of the runs of the repo, it returns how many jobs were deleted
+func pruneJobsOfRuns(db dal.Dal, op *GithubOptions, runIds []int64) (int64, errors.Error) {
+	if len(runIds) == 0 {
+		return 0, nil
+	}
+	where := dal.Where("repo_id = ? AND connection_id = ? AND run_id IN ?", op.GithubId, op.ConnectionId, runIds)
+	pruned, err := db.Count(dal.From(&models.GithubJob{}), where)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, "failed to count the jobs of the deleted runs")
+	}
+	err = db.Delete(&models.GithubJobStep{}, where)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, fmt.Sprintf("failed to delete the steps of %d deleted runs", len(runIds)))
+	}
+	err = db.Delete(&models.GithubJob{}, where)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, fmt.Sprintf("failed to delete the jobs of %d deleted runs", len(runIds)))
+	}
+	err = db.Delete(&models.GithubJobPageEtag{}, where)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, fmt.Sprintf("failed to delete the ETags of the jobs of %d deleted runs", len(runIds)))
+	}
+	return pruned, nil
+}
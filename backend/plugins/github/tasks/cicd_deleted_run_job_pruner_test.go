@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPruneJobsOfRuns(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, GithubId: 2}
+	mockDal := new(mockdal.Dal)
+
+	// nothing to prune without deleted runs
+	pruned, err := pruneJobsOfRuns(mockDal, op, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), pruned)
+
+	mockDal.On("Count", mock.Anything).Return(int64(3), nil).Once()
+	mockDal.On("Delete", mock.AnythingOfType("*models.GithubJobStep"), mock.Anything).Return(nil).Once()
+	mockDal.On("Delete", mock.AnythingOfType("*models.GithubJob"), mock.Anything).Return(nil).Once()
+	mockDal.On("Delete", mock.AnythingOfType("*models.GithubJobPageEtag"), mock.Anything).Return(nil).Once()
+	pruned, err = pruneJobsOfRuns(mockDal, op, []int64{100, 200})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), pruned)
+	mockDal.AssertExpectations(t)
+}
+
+func TestPruneDeletedRunJobsMetaIsOptIn(t *testing.T) {
+	assert.False(t, PruneDeletedRunJobsMeta.EnabledByDefault)
+}
@@ -0,0 +1,191 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&DetectFlakyJobsMeta)
+}
+
+var DetectFlakyJobsMeta = plugin.SubTaskMeta{
+	Name:             "Detect Flaky Jobs",
+	EntryPoint:       DetectFlakyJobs,
+	EnabledByDefault: false,
+	Description:      "Flag the jobs which both succeeded and failed on the same commit into github_flaky_jobs",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubJob{}.TableName(), // conclusions
+		models.GithubRun{}.TableName(), // workflow of the job
+	},
+	ProductTables: []string{models.GithubFlakyJob{}.TableName()},
+}
+
+type githubJobConclusion struct {
+	HeadSha      string
+	WorkflowId   int
+	WorkflowName string
+	Name         string
+	Conclusion   string
+	StartedAt    *time.Time
+}
+
+// flakyJobsOfCommits selects the commits of the repo with jobs extracted since the previous detection, their flaky
+// jobs are detected again over all the jobs of the commit
+const flakyJobsOfCommits = "head_sha IN (SELECT head_sha FROM _tool_github_jobs " +
+	"WHERE repo_id = ? AND connection_id = ? AND updated_at > ?)"
+
+// DetectFlakyJobs only detects again the flaky jobs of the commits with jobs extracted since the previous detection,
+// unless it is a full sync
+func DetectFlakyJobs(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	logger := taskCtx.GetLogger()
+
+	stateManager, err := api.NewSubtaskStateManager(&api.SubtaskCommonArgs{
+		SubTaskContext: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId)
+	clauses := []dal.Clause{
+		dal.Select("j.head_sha, r.workflow_id, r.name AS workflow_name, j.name, j.conclusion, j.started_at"),
+		dal.From("_tool_github_jobs j"),
+		dal.Join(`JOIN _tool_github_runs r
+			ON r.connection_id = j.connection_id AND r.repo_id = j.repo_id AND r.id = j.run_id`),
+		dal.Where("j.repo_id = ? AND j.connection_id = ? AND j.status = ?",
+			data.Options.GithubId, data.Options.ConnectionId, StatusCompleted),
+	}
+	deleted := []dal.Clause{scope}
+	if since := stateManager.GetSince(); stateManager.IsIncremental() && since != nil {
+		commits := dal.Where("j."+flakyJobsOfCommits, data.Options.GithubId, data.Options.ConnectionId, since)
+		clauses = append(clauses, commits)
+		deleted = append(deleted, dal.Where(flakyJobsOfCommits, data.Options.GithubId, data.Options.ConnectionId, since))
+	}
+	clauses = append(clauses, dal.Orderby("j.head_sha, r.workflow_id, j.name, j.started_at, j.id"))
+
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	detector := &flakyJobDetector{}
+	for cursor.Next() {
+		job := githubJobConclusion{}
+		err = db.Fetch(cursor, &job)
+		if err != nil {
+			return err
+		}
+		detector.add(job)
+	}
+	flakyJobs := detector.done()
+
+	// the flaky jobs of the commits are replaced, a job re-run successfully since may not be flaky anymore
+	err = db.Delete(&models.GithubFlakyJob{}, deleted...)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete the flaky jobs")
+	}
+	for _, flakyJob := range flakyJobs {
+		flakyJob.ConnectionId = data.Options.ConnectionId
+		flakyJob.RepoId = data.Options.GithubId
+	}
+	if len(flakyJobs) > 0 {
+		err = db.CreateOrUpdate(flakyJobs)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to save the flaky jobs")
+		}
+	}
+	logger.Info("detected %d flaky jobs", len(flakyJobs))
+	return stateManager.Close()
+}
+
+// flakyJobDetector groups the jobs of a commit, workflow and name, which are added in this order and by their
+// start, and keeps the groups with both a success and a failure
+type flakyJobDetector struct {
+	group     []githubJobConclusion
+	flakyJobs []*models.GithubFlakyJob
+}
+
+func (d *flakyJobDetector) add(job githubJobConclusion) {
+	if len(d.group) > 0 {
+		last := d.group[0]
+		if last.HeadSha != job.HeadSha || last.WorkflowId != job.WorkflowId || last.Name != job.Name {
+			d.flush()
+		}
+	}
+	d.group = append(d.group, job)
+}
+
+func (d *flakyJobDetector) done() []*models.GithubFlakyJob {
+	d.flush()
+	return d.flakyJobs
+}
+
+func (d *flakyJobDetector) flush() {
+	if flakyJob := detectFlakyJob(d.group); flakyJob != nil {
+		d.flakyJobs = append(d.flakyJobs, flakyJob)
+	}
+	d.group = d.group[:0]
+}
+
+// detectFlakyJob returns the flaky job of the jobs of a commit, workflow and name sorted by their start, it is nil
+// unless some of them succeeded and some failed. The cancelled and skipped jobs are neither, see getJobResult
+func detectFlakyJob(jobs []githubJobConclusion) *models.GithubFlakyJob {
+	flakyJob := &models.GithubFlakyJob{}
+	lastResult := ""
+	for _, job := range jobs {
+		result := getJobResult(job.Conclusion)
+		switch result {
+		case devops.RESULT_SUCCESS:
+			flakyJob.SuccessCount++
+		case devops.RESULT_FAILURE:
+			flakyJob.FailureCount++
+		default:
+			continue
+		}
+		if lastResult != "" && result != lastResult {
+			flakyJob.FlipCount++
+		}
+		lastResult = result
+		flakyJob.HeadSha = job.HeadSha
+		flakyJob.WorkflowId = job.WorkflowId
+		flakyJob.WorkflowName = job.WorkflowName
+		flakyJob.Name = job.Name
+		flakyJob.LastConclusion = job.Conclusion
+		flakyJob.LatestStartedAt = job.StartedAt
+	}
+	if flakyJob.SuccessCount == 0 || flakyJob.FailureCount == 0 {
+		return nil
+	}
+	return flakyJob
+}
@@ -0,0 +1,96 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlakyJobDetector(t *testing.T) {
+	at := func(minute int) *time.Time {
+		date := time.Date(2024, 3, 1, 0, minute, 0, 0, time.UTC)
+		return &date
+	}
+	job := func(sha string, workflowId int, name string, conclusion string, minute int) githubJobConclusion {
+		return githubJobConclusion{
+			HeadSha:      sha,
+			WorkflowId:   workflowId,
+			WorkflowName: "CI",
+			Name:         name,
+			Conclusion:   conclusion,
+			StartedAt:    at(minute),
+		}
+	}
+
+	detector := &flakyJobDetector{}
+	for _, j := range []githubJobConclusion{
+		// failed, then passed when re-run and failed again
+		job("aaa", 1, "test", "failure", 1),
+		job("aaa", 1, "test", "success", 2),
+		job("aaa", 1, "test", "cancelled", 3),
+		job("aaa", 1, "test", "timed_out", 4),
+		// always passed
+		job("aaa", 1, "lint", "success", 1),
+		job("aaa", 1, "lint", "success", 2),
+		// the same name in another workflow is another job
+		job("aaa", 2, "test", "success", 1),
+		// a success and a failure on another commit
+		job("bbb", 1, "test", "success", 5),
+		job("bbb", 1, "test", "failure", 6),
+		// cancelled and skipped are neither a success nor a failure
+		job("ccc", 1, "test", "success", 7),
+		job("ccc", 1, "test", "cancelled", 8),
+		job("ccc", 1, "test", "skipped", 9),
+	} {
+		detector.add(j)
+	}
+
+	assert.Equal(t, []*models.GithubFlakyJob{
+		{
+			HeadSha:         "aaa",
+			WorkflowId:      1,
+			WorkflowName:    "CI",
+			Name:            "test",
+			SuccessCount:    1,
+			FailureCount:    2,
+			FlipCount:       2,
+			LastConclusion:  "timed_out",
+			LatestStartedAt: at(4),
+		},
+		{
+			HeadSha:         "bbb",
+			WorkflowId:      1,
+			WorkflowName:    "CI",
+			Name:            "test",
+			SuccessCount:    1,
+			FailureCount:    1,
+			FlipCount:       1,
+			LastConclusion:  "failure",
+			LatestStartedAt: at(6),
+		},
+	}, detector.done())
+}
+
+func TestDetectFlakyJobWithoutJobs(t *testing.T) {
+	assert.Nil(t, detectFlakyJob(nil))
+	assert.Nil(t, (&flakyJobDetector{}).done())
+}
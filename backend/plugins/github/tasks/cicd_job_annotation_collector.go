@@ -0,0 +1,133 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectJobAnnotationsMeta)
+}
+
+const RAW_JOB_ANNOTATION_TABLE = "github_api_job_annotations"
+
+var CollectJobAnnotationsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Job Annotations",
+	EntryPoint:       CollectJobAnnotations,
+	EnabledByDefault: false,
+	Description:      "Collect check run annotations of the jobs from Github api, supports both timeFilter and diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubJob{}.TableName()},
+	ProductTables:    []string{RAW_JOB_ANNOTATION_TABLE},
+	SkipOnFail:       true,
+}
+
+func CollectJobAnnotations(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+
+	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_JOB_ANNOTATION_TABLE,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the check run of a job shares the id of the job
+	clauses := []dal.Clause{
+		dal.Select("id"),
+		dal.From(&models.GithubJob{}),
+		dal.Where(
+			"repo_id = ? AND connection_id = ?",
+			data.Options.GithubId, data.Options.ConnectionId,
+		),
+	}
+	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
+		clauses = append(clauses, dal.Where("completed_at > ?", apiCollector.GetSince()))
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubJob{}))
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Input:       iterator,
+		UrlTemplate: "repos/{{ .Params.Name }}/check-runs/{{ .Input.ID }}/annotations",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			var items []json.RawMessage
+			err := api.UnmarshalResponse(res, &items)
+			if err != nil {
+				return nil, err
+			}
+			return items, nil
+		},
+		AfterResponse: func(res *http.Response) errors.Error {
+			if res.StatusCode == http.StatusUnauthorized {
+				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+			}
+			// the job might have been deleted along with its run
+			if res.StatusCode == http.StatusNotFound {
+				logger.Warn(nil, "GitHub check run not found (404) at %s, likely deleted. Skipping...", res.Request.URL.Path)
+				return api.ErrIgnoreAndContinue
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return apiCollector.Execute()
+}
+
+type SimpleGithubJob struct {
+	ID int64
+}
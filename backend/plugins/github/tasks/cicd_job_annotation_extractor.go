@@ -0,0 +1,86 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractJobAnnotationsMeta)
+}
+
+var ExtractJobAnnotationsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Job Annotations",
+	EntryPoint:       ExtractJobAnnotations,
+	EnabledByDefault: false,
+	Description:      "Extract raw job annotations data into tool layer table github_job_annotations",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_JOB_ANNOTATION_TABLE},
+	ProductTables:    []string{models.GithubJobAnnotation{}.TableName()},
+}
+
+func ExtractJobAnnotations(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_JOB_ANNOTATION_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			annotation, err := extractJobAnnotation(row.Input, row.Data)
+			if err != nil {
+				return nil, err
+			}
+			annotation.ConnectionId = data.Options.ConnectionId
+			annotation.RepoId = data.Options.GithubId
+			return []interface{}{annotation}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractJobAnnotation parses an annotation of the check run of the job the collector was given as input, the check
+// run shares the id of the job
+func extractJobAnnotation(input json.RawMessage, body json.RawMessage) (*models.GithubJobAnnotation, errors.Error) {
+	job := &SimpleGithubJob{}
+	err := errors.Convert(json.Unmarshal(input, job))
+	if err != nil {
+		return nil, err
+	}
+	annotation := &models.GithubJobAnnotation{}
+	err = errors.Convert(json.Unmarshal(body, annotation))
+	if err != nil {
+		return nil, err
+	}
+	annotation.JobId = job.ID
+	return annotation, nil
+}
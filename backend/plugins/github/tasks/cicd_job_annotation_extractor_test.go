@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractJobAnnotation(t *testing.T) {
+	annotation, err := extractJobAnnotation([]byte(`{"ID": 21}`), []byte(`{
+		"path": "src/main.go", "start_line": 12, "end_line": 14, "start_column": null, "end_column": null,
+		"annotation_level": "failure", "title": "go vet", "message": "unreachable code",
+		"raw_details": null, "blob_href": "https://github.com/o/r/blob/abc/src/main.go"
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), annotation.JobId)
+	assert.Equal(t, "src/main.go", annotation.Path)
+	assert.Equal(t, 12, annotation.StartLine)
+	assert.Equal(t, 14, annotation.EndLine)
+	assert.Equal(t, "failure", annotation.AnnotationLevel)
+	assert.Equal(t, "go vet", annotation.Title)
+	assert.Equal(t, "unreachable code", annotation.Message)
+
+	// the annotations of a step are not attached to a file
+	annotation, err = extractJobAnnotation([]byte(`{"ID": 21}`), []byte(`{
+		"path": ".github", "start_line": 1, "end_line": 1,
+		"annotation_level": "warning", "title": null, "message": "Node.js 16 actions are deprecated"
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, ".github", annotation.Path)
+	assert.Equal(t, "warning", annotation.AnnotationLevel)
+	assert.Empty(t, annotation.Title)
+}
@@ -18,19 +18,27 @@ limitations under the License.
 package tasks
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/apache/incubator-devlake/plugins/github/utils"
 )
 
 func init() {
@@ -39,6 +47,32 @@ func init() {
 
 const RAW_JOB_TABLE = "github_api_jobs"
 
+// DEFAULT_JOBS_PAGE_SIZE is the maximum page size the jobs API accepts
+const DEFAULT_JOBS_PAGE_SIZE = 100
+
+// DEFAULT_MAX_ERROR_BODY_LENGTH is how much of the body of a failed response is kept by default
+const DEFAULT_MAX_ERROR_BODY_LENGTH = 300
+
+// DEFAULT_JOBS_FAILURE_LOG_LIMIT is how many failed runs are logged one by one by default
+const DEFAULT_JOBS_FAILURE_LOG_LIMIT = 20
+
+// DEFAULT_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS caps the pause after a rate limited jobs request
+const DEFAULT_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS = 300
+
+// DEFAULT_JOBS_MAX_PAGES_PER_RUN caps the pages of jobs collected for a run by default, 5000 jobs at 100 per page,
+// far more than GitHub allows in a run
+const DEFAULT_JOBS_MAX_PAGES_PER_RUN = 50
+
+// JOBS_FAILURE_RATIO_MIN_RUNS is how many runs must be processed before jobsMaxFailureRatio is checked, so the
+// first failures don't stop the collection
+const JOBS_FAILURE_RATIO_MIN_RUNS = 20
+
+// MAX_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS is the longest pause allowed, the primary rate limit is reset hourly
+const MAX_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS = 3600
+
+// DEFAULT_INCREMENTAL_OVERLAP_SECONDS is how far back an incremental jobs collection starts before the previous one
+const DEFAULT_INCREMENTAL_OVERLAP_SECONDS = 600
+
 var CollectJobsMeta = plugin.SubTaskMeta{
 	Name:             "Collect Job Runs",
 	EntryPoint:       CollectJobs,
@@ -46,185 +80,1875 @@ var CollectJobsMeta = plugin.SubTaskMeta{
 	Description:      "Collect Jobs data from Github action api, supports both timeFilter and diffSync.",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
 	DependencyTables: []string{models.GithubRun{}.TableName()},
-	ProductTables:    []string{RAW_JOB_TABLE},
-	SkipOnFail:       true, // Allow other subtasks to continue if job collection fails
+	ProductTables: []string{
+		RAW_JOB_TABLE,
+		models.GithubJobCollectionFailure{}.TableName(),
+		models.GithubJobCollectionCheckpoint{}.TableName(),
+		models.GithubJobPageEtag{}.TableName(),
+	},
+	SkipOnFail: true, // Allow other subtasks to continue if job collection fails
 }
 
 func CollectJobs(taskCtx plugin.SubTaskContext) errors.Error {
-	db := taskCtx.GetDal()
 	data := taskCtx.GetData().(*GithubTaskData)
+	err := forEachJobsScope(taskCtx, collectJobs)
+	for _, scopeData := range data.JobsScopes {
+		data.JobCollectionResult = data.JobCollectionResult.merge(scopeData.JobCollectionResult)
+	}
+	return err
+}
+
+func collectJobs(taskCtx plugin.SubTaskContext, data *GithubTaskData) errors.Error {
+	if skipActionsDisabled(taskCtx, data) {
+		data.JobCollectionResult = &JobCollectionResult{Notice: data.ActionsDisabled.Error()}
+		return nil
+	}
+	db := taskCtx.GetDal()
 	logger := taskCtx.GetLogger()
+	rawParams, err := jobsRawDataParams(data.Options)
+	if err != nil {
+		return err
+	}
 
 	// state manager
 	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
-		Ctx: taskCtx,
-		Params: GithubApiParams{
-			ConnectionId: data.Options.ConnectionId,
-			Name:         data.Options.Name,
-		},
-		Table: RAW_JOB_TABLE,
+		Ctx:    taskCtx,
+		Params: rawParams,
+		Table:  RAW_JOB_TABLE,
 	})
 	if err != nil {
 		return err
 	}
 
+	resetJobsIncrementalState(apiCollector, data.Options, logger, time.Now())
+
+	// the jobs of the runs listed by RunIDs are collected aside from the incremental collection
+	targeted := len(data.Options.RunIDs) > 0
+	if targeted {
+		err = validateJobsRunIds(db, data.Options)
+		if err != nil {
+			return err
+		}
+		logger.Info("collecting the jobs of the runs %v only", data.Options.RunIDs)
+	}
+
 	// load workflow_runs that need jobs collection
-	clauses := []dal.Clause{
-		dal.Select("id"),
-		dal.From(&models.GithubRun{}),
-		dal.Where(
-			"repo_id = ? AND connection_id = ?",
-			data.Options.GithubId, data.Options.ConnectionId,
-		),
+	var since *time.Time
+	if apiCollector.IsIncremental() && !targeted {
+		since = withIncrementalOverlap(apiCollector.GetSince(), data.Options)
+	}
+	// resume the interrupted collection if there is one
+	checkpoint := &models.GithubJobCollectionCheckpoint{}
+	if !targeted {
+		checkpoint, err = loadJobCollectionCheckpoint(db, data.Options)
+		if err != nil {
+			return err
+		}
 	}
-	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
-		clauses = append(clauses, dal.Where("github_updated_at > ?", apiCollector.GetSince()))
+	resumed := checkpoint.LastRunId > 0 || checkpoint.WindowStart != nil
+	if checkpoint.LastRunId > 0 {
+		logger.Info("resuming the jobs collection started at %s after run %d", checkpoint.StartedAt, checkpoint.LastRunId)
 	}
-	cursor, err := db.Cursor(clauses...)
+	if checkpoint.WindowStart != nil {
+		logger.Info("resuming the jobs collection started at %s at the window starting at %s",
+			checkpoint.StartedAt, checkpoint.WindowStart)
+	}
+	if checkpoint.Capped && apiCollector.IsIncremental() {
+		// the previous collection stopped at MaxRunsPerRun, the runs it left are selected the same way
+		since = checkpoint.Since
+	}
+	defaultBranch, err := loadJobsDefaultBranch(db, logger, data.Options)
 	if err != nil {
 		return err
 	}
-	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+	clauses := buildJobsRunClauses(data.Options, since, checkpoint, defaultBranch)
+
+	// progress is reported by runs instead of by pages, so the pipeline shows how many runs were processed
+	runsToProcess, err := db.Count(clauses...)
 	if err != nil {
 		return err
 	}
+	capped := false
+	if !targeted {
+		runsToProcess, capped = capJobsRuns(data.Options, runsToProcess)
+	}
+	if capped {
+		logger.Info("collecting the jobs of the first %d runs only, the next collection carries on with the others",
+			runsToProcess)
+	}
+	var failuresToRetry int64
+	if apiCollector.IsIncremental() && !targeted {
+		failuresToRetry, err = db.Count(
+			dal.From(&models.GithubJobCollectionFailure{}),
+			dal.Where(
+				"repo_id = ? AND connection_id = ?",
+				data.Options.GithubId, data.Options.ConnectionId,
+			),
+		)
+		if err != nil {
+			return err
+		}
+		runsToProcess += failuresToRetry
+	}
+	var notice string
+	if runsToProcess == 0 {
+		repoRuns, err := db.Count(
+			dal.From(&models.GithubRun{}),
+			dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+		)
+		if err != nil {
+			return err
+		}
+		notice = emptyRunsNotice(data.Options.Name, repoRuns)
+		if notice != "" {
+			logger.Warn(nil, "%s", notice)
+		} else {
+			logger.Info("none of the %d runs of the repo was updated since the last collection, no jobs to collect", repoRuns)
+		}
+	}
+	if data.Options.DryRun {
+		return estimateJobsRequests(db, logger, data.Options, clauses, failuresToRetry, capped)
+	}
+	taskCtx.SetProgress(0, int(runsToProcess))
+	collectorCtx := &runProgressSubTaskContext{taskCtx}
+	// the client is shared by the subtasks, so the requests of this one are the ones sent from now on
+	requestsBefore := data.ApiClient.RequestCount()
 
 	// Track failed runs for logging with error details
-	failedRuns := []int64{}
-	failedRunsErrors := make(map[int64]string) // Track error details per run
-	totalRuns := 0
-	currentRunId := int64(0)
-
-	// collect jobs with individual error handling
-	err = apiCollector.InitCollector(api.ApiCollectorArgs{
-		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
-			Ctx: taskCtx,
-			Params: GithubApiParams{
-				ConnectionId: data.Options.ConnectionId,
-				Name:         data.Options.Name,
-			},
-			Table: RAW_JOB_TABLE,
-		},
-		ApiClient:   data.ApiClient,
-		PageSize:    100,
-		Input:       iterator,
-		UrlTemplate: "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}/jobs",
-		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
-			query := url.Values{}
-			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
-			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
-
-			// Track current run ID from the input
-			if input, ok := reqData.Input.(*SimpleGithubRun); ok {
-				currentRunId = input.ID
+	state := newJobCollectionState(logger)
+	state.maxErrorBodyLength = getMaxErrorBodyLength(data.Options)
+	state.failureLogLimit = getJobsFailureLogLimit(data.Options)
+	state.rateLimitMaxWait = time.Duration(getJobsRateLimitMaxWaitSeconds(data.Options)) * time.Second
+	state.sleep = func(d time.Duration) {
+		select {
+		case <-taskCtx.GetContext().Done():
+		case <-time.After(d):
+		}
+	}
+	if data.Options.JobsTimingTopN > 0 {
+		state.trackTimings()
+	}
+	state.maxFailureRatio = data.Options.JobsMaxFailureRatio
+	state.maxPagesPerRun = data.Options.JobsMaxPagesPerRun
+	state.ctx = taskCtx.GetContext()
+	state.jobsPath = actionsApiPath(data, "repos/"+data.Options.Name+"/actions/runs/%d/jobs")
+	if apiCollector.IsIncremental() {
+		// a full sync clears the raw jobs, so the pages are requested unconditionally to collect them again
+		err = pruneJobPageEtags(db, data.Options)
+		if err != nil {
+			return err
+		}
+		state.loadEtags = func(runId int64) (map[int]string, errors.Error) {
+			return loadJobPageEtags(db, data.Options, runId)
+		}
+	}
+	state.onRunProcessed = func(processed int, failures int) {
+		taskCtx.IncProgress(1)
+		if processed%100 == 0 {
+			logger.Info("collected jobs of %d out of %d runs, %d failures", processed, runsToProcess, failures)
+			if !targeted {
+				// the callback is called with the lock held
+				saveJobCollectionCheckpoint(db, logger, data.Options, checkpoint, state.checkpointLocked())
 			}
+		}
+	}
 
-			return query, nil
-		},
-		GetTotalPages: GetTotalPagesFromResponse,
-		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
-			body := &GithubRawJobsResult{}
-			err := api.UnmarshalResponse(res, body)
-			if err != nil {
-				return nil, err
-			}
-			return body.GithubWorkflowJobs, nil
-		},
-		AfterResponse: func(res *http.Response) errors.Error {
-			// Count total runs processed
-			totalRuns++
-
-			// Handle 404 errors gracefully (run might have been deleted)
-			if res.StatusCode == http.StatusNotFound {
-				failedRuns = append(failedRuns, currentRunId)
-				failedRunsErrors[currentRunId] = "404 Not Found - Run likely deleted"
-				logger.Warn(nil, "GitHub run %d not found (404) at %s, likely deleted. Skipping...", 
-					currentRunId, res.Request.URL.Path)
-				return nil
-			}
+	// limit the number of parallel requests of the shared client while collecting jobs
+	if data.Options.JobsConcurrency > 0 {
+		oldNumOfWorkers := data.ApiClient.GetNumOfWorkers()
+		logger.Info("set number of workers to %d", data.Options.JobsConcurrency)
+		data.ApiClient.SetNumOfWorkers(data.Options.JobsConcurrency)
+		defer func() {
+			logger.Info("restore number of workers to %d", oldNumOfWorkers)
+			data.ApiClient.SetNumOfWorkers(oldNumOfWorkers)
+		}()
+	}
 
-			// Handle 500 errors gracefully (temporary GitHub API issues)
-			if res.StatusCode >= 500 {
-				// Read response body to get error details
-				errorBody := "unknown error"
-				if res.Body != nil {
-					if bodyBytes, err := io.ReadAll(res.Body); err == nil {
-						errorBody = string(bodyBytes)
-						// Truncate if too long to avoid log spam
-						if len(errorBody) > 300 {
-							errorBody = errorBody[:300] + "... (truncated)"
-						}
+	newCollectorArgs := func(input api.Iterator, trackCheckpoint bool) api.ApiCollectorArgs {
+		return api.ApiCollectorArgs{
+			RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+				Ctx:    collectorCtx,
+				Params: rawParams,
+				Table:  RAW_JOB_TABLE,
+			},
+			// the raw data of the runs before the checkpoint was collected by the interrupted collection, and the
+			// one of the runs which are not targeted is not collected again
+			KeepRawData: resumed || targeted,
+			ApiClient:   data.ApiClient,
+			MaxRetry:    data.Options.JobsMaxRetry,
+			PageSize:    getJobsPageSize(data.Options),
+			Input:       &partialCollectionIterator{Iterator: input, state: state},
+			UrlTemplate: actionsApiPath(data, "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}/jobs"),
+			Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+				query := jobsPageQuery(data.Options, reqData.Pager)
+
+				// Track the runs we requested jobs for
+				if input, ok := reqData.Input.(*SimpleGithubRun); ok {
+					state.markAttempted(input.ID)
+					if trackCheckpoint && reqData.Pager.Page == 1 {
+						state.markQueued(input.ID)
 					}
 				}
-				
-				failedRuns = append(failedRuns, currentRunId)
-				failedRunsErrors[currentRunId] = fmt.Sprintf("%d Server Error: %s", res.StatusCode, errorBody)
-				logger.Warn(nil, "GitHub API returned %d for run %d: %s. Skipping this run to continue collection", 
-					res.StatusCode, currentRunId, errorBody)
-				return nil // Skip this run but continue with others
+
+				return query, nil
+			},
+			Header: func(reqData *api.RequestData) (http.Header, errors.Error) {
+				if input, ok := reqData.Input.(*SimpleGithubRun); ok {
+					return state.etagHeader(input.ID, reqData.Pager.Page)
+				}
+				return nil, nil
+			},
+			GetTotalPages:     state.totalPages,
+			ResponseParser:    state.parseResponse,
+			AfterResponse:     state.afterResponse,
+			SkipInputOnStatus: jobsSkipRunOnStatus,
+			OnInputSkipped:    state.skipRun,
+		}
+	}
+
+	// re-attempt the runs that failed in previous collections before moving to newly-updated ones,
+	// a full sync would pick them up from the runs table anyway
+	if apiCollector.IsIncremental() && !targeted {
+		failureClauses := []dal.Clause{
+			dal.Select("run_id AS id"),
+			dal.From(&models.GithubJobCollectionFailure{}),
+			dal.Where(
+				"repo_id = ? AND connection_id = ?",
+				data.Options.GithubId, data.Options.ConnectionId,
+			),
+		}
+		if len(data.Options.WorkflowNames) > 0 {
+			failureClauses = append(failureClauses, dal.Where(
+				`run_id IN (SELECT id FROM _tool_github_runs
+					WHERE repo_id = ? AND connection_id = ? AND (name IN ? OR path IN ?))`,
+				data.Options.GithubId, data.Options.ConnectionId, data.Options.WorkflowNames, data.Options.WorkflowNames,
+			))
+		}
+		failureCursor, err := db.Cursor(failureClauses...)
+		if err != nil {
+			return err
+		}
+		failureIterator, err := api.NewDalCursorIterator(db, failureCursor, reflect.TypeOf(SimpleGithubRun{}))
+		if err != nil {
+			return err
+		}
+		err = apiCollector.InitCollector(newCollectorArgs(failureIterator, false))
+		if err != nil {
+			return err
+		}
+	}
+
+	windowed := data.Options.JobsWindowDays > 0 && !targeted
+	var targetedCollector *api.ApiCollector
+	if windowed {
+		// a collector per window, each of them starts once the previous one completed
+		windows, err := loadJobsWindows(db, clauses, data.Options.JobsWindowDays)
+		if err != nil {
+			return err
+		}
+		logger.Info("collecting the jobs of the runs in %d windows of %d days", len(windows), data.Options.JobsWindowDays)
+		for _, window := range windows {
+			window := window
+			windowIterator := &jobsWindowIterator{open: func() (api.Iterator, errors.Error) {
+				if window.Start != nil {
+					// the collectors of the previous windows completed
+					saveJobsWindowCheckpoint(db, logger, checkpoint, window.Start)
+				}
+				cursor, err := db.Cursor(append(append([]dal.Clause{}, clauses...), window.clause(), dal.Orderby("id"))...)
+				if err != nil {
+					return nil, err
+				}
+				return api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+			}}
+			err = apiCollector.InitCollector(newCollectorArgs(windowIterator, false))
+			if err != nil {
+				return err
 			}
+		}
+	} else {
+		// runs are collected in the order of ids so the checkpoint can tell which of them were done
+		runClauses := append(clauses, dal.Orderby(jobsRunsOrderBy(data.Options)))
+		if capped {
+			runClauses = append(runClauses, dal.Limit(int(runsToProcess)))
+		}
+		cursor, err := db.Cursor(runClauses...)
+		if err != nil {
+			return err
+		}
+		iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+		if err != nil {
+			return err
+		}
 
-			return nil
-		},
-	})
-	if err != nil {
-		return err
+		if targeted {
+			// executed on its own, so the incremental state of the collection isn't moved on
+			targetedCollector, err = api.NewApiCollector(newCollectorArgs(iterator, false))
+		} else {
+			// collect jobs with individual error handling
+			err = apiCollector.InitCollector(newCollectorArgs(iterator, true))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var collectorSummary api.ApiCollectorSummary
+	if targeted {
+		err = targetedCollector.Execute()
+		collectorSummary = targetedCollector.Summary()
+	} else {
+		err = apiCollector.Execute()
+		collectorSummary = apiCollector.Summary()
+	}
+	// the windows after the one which failed were not collected
+	windowsLeft := windowed && err != nil
+	if err != nil && state.cancelled() {
+		// the requests in flight were aborted, the runs collected until then are saved like a partial collection
+		logger.Warn(err, "the collection of jobs was cancelled")
+		err = nil
 	}
 
-	err = apiCollector.Execute()
-	
 	// Handle execution errors gracefully - especially retry failures
 	if err != nil {
-		// Check if this is a retry-related error that we want to handle gracefully
-		errorStr := err.Error()
-		if strings.Contains(errorStr, "Retry exceeded") && strings.Contains(errorStr, "times calling") {
-			// Try to extract run ID from the error message
-			// Error format: "Retry exceeded 3 times calling repos/.../actions/runs/12345/jobs"
-			runIdStr := ""
-			if strings.Contains(errorStr, "/actions/runs/") {
-				parts := strings.Split(errorStr, "/actions/runs/")
-				if len(parts) > 1 {
-					runParts := strings.Split(parts[1], "/")
-					if len(runParts) > 0 {
-						runIdStr = runParts[0]
-					}
-				}
+		runErr := &api.CollectorRunError{}
+		if !errors.As(err, &runErr) {
+			// For other types of errors, still fail the task, the next collection resumes from the checkpoint
+			if !targeted {
+				saveJobCollectionCheckpoint(db, logger, data.Options, checkpoint, state.checkpoint())
 			}
-			
-			logger.Warn(nil, "API collection completed with retry failures for run %s: %s", runIdStr, errorStr)
-			logger.Info("Some individual API calls failed after retries, but collection continued to maximize data collection")
-			
-			// Add this to our failed runs tracking if we can parse the run ID
-			if runIdStr != "" {
-				failedRunsErrors[0] = fmt.Sprintf("Retry failure: %s", errorStr)
+			return err
+		}
+		logger.Warn(nil, "API collection completed with retry failures for run %d (status %d) at %s: %s",
+			runErr.RunID, runErr.StatusCode, runErr.URL, runErr.Error())
+		logger.Info("Some individual API calls failed after retries, but collection continued to maximize data collection")
+
+		// the runs skipped on their status were recorded along with the reason already, the summary of the
+		// collector tells the ones which ran out of retries
+		for _, failure := range collectorSummary.FailedInputs {
+			if !isJobsSkipRunStatus(failure.StatusCode) {
+				state.recordServerFailure(failure.ID, failure.StatusCode, fmt.Sprintf("Retry failure: %s", runErr.Error()))
 			}
-			
-			// Don't return the error - treat as partial success
-		} else {
-			// For other types of errors, still fail the task
+		}
+
+		// Don't return the error - treat as partial success
+		err = nil
+	}
+
+	// Persist failed runs so they are visible to operators and re-attempted by the next collection
+	saveErr := saveJobCollectionFailures(db, data.Options, state.attemptedRuns, state.failedRunsErrors, state.failedRunsStatus)
+	if saveErr != nil {
+		return saveErr
+	}
+
+	err = saveJobPageEtags(db, data.Options, state.newEtags)
+	if err != nil {
+		return err
+	}
+	if state.notModifiedPages > 0 {
+		logger.Info("%d pages of jobs were not modified since the previous collection", state.notModifiedPages)
+	}
+
+	partialErr := state.partialCollectionError()
+	permissionsErr := state.missingPermissionsError()
+	switch {
+	case targeted:
+		// the checkpoint belongs to the collection of all the runs, it is left as it is
+	case capped:
+		// the next collection carries on after the runs collected this time
+		err = saveCappedJobCollectionCheckpoint(db, data.Options, checkpoint, since, state.checkpoint())
+		if err != nil {
 			return err
 		}
+	case partialErr != nil || permissionsErr != nil || windowsLeft:
+		// the collection stopped early, the next one carries on after the runs processed this time
+		saveJobCollectionCheckpoint(db, logger, data.Options, checkpoint, state.checkpoint())
+	default:
+		// the collection completed, so the next one starts over
+		err = db.Delete(
+			&models.GithubJobCollectionCheckpoint{},
+			dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to clear the job collection checkpoint")
+		}
 	}
 
+	data.JobCollectionResult = state.result()
+	data.JobCollectionResult.Notice = notice
+	data.JobCollectionResult.Requests = data.ApiClient.RequestCount() - requestsBefore
+
 	// Log summary of collection results
-	if len(failedRuns) > 0 {
-		logger.Info("Job collection completed with %d failed runs out of %d total runs. Failed run IDs: %v",
-			len(failedRuns), totalRuns, failedRuns)
-		
+	logger.Info("collected jobs of %d out of %d runs, %d failures",
+		len(state.processedRuns), runsToProcess, len(state.failedRunsErrors))
+	logger.Info("issued %d requests across %d runs", data.JobCollectionResult.Requests, len(state.processedRuns))
+	if len(state.failedRuns) > 0 {
+		// the summary is capped, all the failures are in _tool_github_job_collection_failures
+		failedRuns := state.failedRuns
+		more := ""
+		if len(failedRuns) > state.failureLogLimit {
+			failedRuns = failedRuns[:state.failureLogLimit]
+			more = fmt.Sprintf(" and %d more, see %s", len(state.failedRuns)-len(failedRuns),
+				models.GithubJobCollectionFailure{}.TableName())
+		}
+		logger.Info("Job collection completed with %d failed runs out of %d total runs. Failed run IDs: %v%s",
+			len(state.failedRuns), state.totalRuns, failedRuns, more)
+
 		// Log detailed error information for debugging
 		logger.Info("Error details for failed runs:")
-		for runId, errorMsg := range failedRunsErrors {
-			logger.Info("  Run %d: %s", runId, errorMsg)
+		for _, runId := range failedRuns {
+			logger.Info("  Run %d: %s", runId, state.failedRunsErrors[runId])
 		}
-		
+
 		logger.Info("Continuing pipeline execution despite individual run failures to maximize data collection")
-	} else {
-		logger.Info("Job collection completed successfully for all %d runs", totalRuns)
+	} else if state.totalRuns > 0 {
+		logger.Info("Job collection completed successfully for all %d runs", state.totalRuns)
+	}
+	summary, summaryErr := errors.Convert01(json.Marshal(state.summary(data.Options)))
+	if summaryErr != nil {
+		return summaryErr
+	}
+	logger.Info("%s%s", jobCollectionSummaryPrefix, summary)
+	if data.Options.JobsTimingTopN > 0 {
+		for _, timing := range state.slowestRuns(data.Options.JobsTimingTopN) {
+			logger.Info("collecting the jobs of run %d took %s for %d pages", timing.RunId, timing.Duration(), timing.Pages)
+		}
+	}
+	if partialErr != nil {
+		return errors.Default.WrapRaw(partialErr)
+	}
+	if permissionsErr != nil {
+		return permissionsErr
+	}
+	if err == nil && data.Options.ScopeConfig != nil && data.Options.ScopeConfig.FailPipelineOnJobCollectionError {
+		return state.missingJobsError()
 	}
 
 	return err
 }
 
+// JobCollectionResult summarizes the runs handled by CollectJobs
+type JobCollectionResult struct {
+	TotalRuns  int              `json:"totalRuns"`
+	FailedRuns []int64          `json:"failedRuns"`
+	Errors     map[int64]string `json:"errors"`
+	// Notice explains a collection which didn't fail but is likely not what was expected, i.e. there were no runs
+	Notice string `json:"notice,omitempty"`
+	// Requests is the number of requests sent to collect the jobs, counting every attempt of the retried ones,
+	// to attribute the usage of the rate limit of the token
+	Requests int64 `json:"requests"`
+}
+
+// jobsRawDataParams returns the params the raw jobs of the repo are stored with. All the connections share
+// RAW_JOB_TABLE, so the params must tell the connections and the repos apart, or the collection of a repo would
+// delete the raw jobs of another one
+func jobsRawDataParams(op *GithubOptions) (GithubApiParams, errors.Error) {
+	params := GithubApiParams{
+		ConnectionId: op.ConnectionId,
+		Name:         op.Name,
+	}
+	return params, validateRawDataParams(params)
+}
+
+// validateRawDataParams makes sure the params stored along with the raw data include the ConnectionId and the Name
+func validateRawDataParams(params interface{}) errors.Error {
+	fields := make(map[string]interface{})
+	err := errors.Convert(json.Unmarshal([]byte(plugin.MarshalScopeParams(params)), &fields))
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"ConnectionId", "Name"} {
+		if value, ok := fields[key]; !ok || value == nil || value == float64(0) || value == "" {
+			return errors.Default.New(fmt.Sprintf("the raw data params %s must include a %s", plugin.MarshalScopeParams(params), key))
+		}
+	}
+	return nil
+}
+
+// merge returns the result of the runs of both results, either of them may be nil
+func (r *JobCollectionResult) merge(other *JobCollectionResult) *JobCollectionResult {
+	if r == nil {
+		return other
+	}
+	if other == nil {
+		return r
+	}
+	merged := &JobCollectionResult{
+		TotalRuns:  r.TotalRuns + other.TotalRuns,
+		FailedRuns: []int64{},
+		Errors:     make(map[int64]string, len(r.Errors)+len(other.Errors)),
+		Notice:     strings.TrimSpace(r.Notice + " " + other.Notice),
+		Requests:   r.Requests + other.Requests,
+	}
+	// a run is listed once like it is in Errors, even when both results failed it
+	seen := make(map[int64]bool, len(r.FailedRuns)+len(other.FailedRuns))
+	for _, runId := range append(append([]int64{}, r.FailedRuns...), other.FailedRuns...) {
+		if !seen[runId] {
+			seen[runId] = true
+			merged.FailedRuns = append(merged.FailedRuns, runId)
+		}
+	}
+	for runId, detail := range r.Errors {
+		merged.Errors[runId] = detail
+	}
+	for runId, detail := range other.Errors {
+		merged.Errors[runId] = detail
+	}
+	return merged
+}
+
+// emptyRunsNotice tells what to check when the repo has no runs at all, which usually means that the runs were not
+// collected rather than the repo not using GitHub Actions. It is empty when the repo has some runs
+func emptyRunsNotice(repoName string, repoRuns int64) string {
+	if repoRuns > 0 {
+		return ""
+	}
+	return fmt.Sprintf("no workflow runs of %s found in %s, no jobs are collected. "+
+		"Please check that the \"%s\" and \"%s\" subtasks are enabled and ran successfully before",
+		repoName, models.GithubRun{}.TableName(), CollectRunsMeta.Name, ExtractRunsMeta.Name)
+}
+
+// jobCollectionState tracks the runs handled by CollectJobs, it is shared by the parallel requests
+// of the collector so all the accesses are guarded by the mutex
+type jobCollectionState struct {
+	mu               sync.Mutex
+	logger           log.Logger
+	failedRuns       []int64
+	failedRunsErrors map[int64]string // Track error details per run
+	failedRunsStatus map[int64]int    // Track the http status per run, 0 when unknown
+	attemptedRuns    map[int64]bool   // Track runs we requested jobs for
+	processedRuns    map[int64]bool   // Track runs with at least one response
+	queuedRuns       []int64          // Track runs of the main cursor in the order they were requested
+	checkpointIdx    int              // queuedRuns before it were all processed
+	totalRuns        int
+	rateLimitHits    map[int64]int // Track the rate limited responses per run for the backoff
+	rateLimitMaxWait time.Duration
+	// maxErrorBodyLength is how much of the body of a failed response is recorded
+	maxErrorBodyLength int
+	sleep              func(d time.Duration)
+	// onRunProcessed is called when a run got its first response
+	onRunProcessed func(processed int, failures int)
+	// timings tracks how long the jobs of each run took to collect, nil when JobsTimingTopN is not set
+	timings map[int64]*runCollectionTiming
+	now     func() time.Time
+	// failureLogLimit is how many failures are logged one by one, the next ones are rolled up in batches of as many
+	failureLogLimit int
+	failureWarnings int
+	// unloggedFailures are the runs which failed since the last rollup
+	unloggedFailures []int64
+	// etags are the ETags of the pages collected before by run and page, loaded by loadEtags the first time a page
+	// of the run is requested, so only the ETags of the runs of the input are loaded
+	etags map[int64]map[int]string
+	// loadEtags loads the ETags of the pages of a run by page, nil to request the pages unconditionally
+	loadEtags        func(runId int64) (map[int]string, errors.Error)
+	newEtags         map[jobPageKey]string
+	notModifiedPages int
+	// maxFailureRatio stops the collection once the ratio of failed runs exceeds it, 0 never stops it
+	maxFailureRatio float64
+	// serverFailedRuns are the runs which failed on the server side or ran out of retries, the ones weighed against
+	// maxFailureRatio. The deleted, moved and blocked runs are expected to be skipped
+	serverFailedRuns map[int64]bool
+	// ctx is the context of the task, the collection stops once it is cancelled
+	ctx context.Context
+	// jobsPath is the path of the jobs of a run, with a %d for the run id, to log the requests which were lost
+	// along with their response
+	jobsPath string
+	// maxPagesPerRun caps the pages collected for a run, 0 doesn't cap them
+	maxPagesPerRun int
+	// permissionsErr tells which permissions the token is missing to read the jobs, the collection stops once it
+	// is set since the other runs would be rejected the same way
+	permissionsErr errors.Error
+}
+
+// PartialCollectionError is returned by CollectJobs when it stopped early because too many runs failed, i.e. during
+// an outage of GitHub or when the token lost its permissions, or because the pipeline was cancelled. The runs
+// processed until then are kept, and the next collection carries on after them
+type PartialCollectionError struct {
+	Processed       int
+	Failed          int
+	MaxFailureRatio float64
+	// Cancelled is set when the context of the task was cancelled, i.e. the pipeline timed out or was aborted
+	Cancelled bool
+}
+
+func (e *PartialCollectionError) Error() string {
+	if e.Cancelled {
+		return fmt.Sprintf("the collection of jobs was cancelled after %d runs, %d of them failed", e.Processed, e.Failed)
+	}
+	return fmt.Sprintf("stopped collecting jobs after %d of %d runs failed, more than the jobsMaxFailureRatio of %v",
+		e.Failed, e.Processed, e.MaxFailureRatio)
+}
+
+// partialCollectionIterator stops feeding the collector once the collection is cancelled or too many runs failed,
+// see partialCollectionError
+type partialCollectionIterator struct {
+	api.Iterator
+	state *jobCollectionState
+}
+
+func (it *partialCollectionIterator) HasNext() bool {
+	return it.state.partialCollectionError() == nil && it.state.missingPermissionsError() == nil && it.Iterator.HasNext()
+}
+
+// runCollectionTiming is how long the jobs of a run took to collect, from the request of its first page to the
+// last response
+type runCollectionTiming struct {
+	RunId    int64
+	Started  time.Time
+	Finished time.Time
+	Pages    int
+}
+
+// Duration returns how long the jobs of the run took to collect
+func (t *runCollectionTiming) Duration() time.Duration {
+	return t.Finished.Sub(t.Started)
+}
+
+func newJobCollectionState(logger log.Logger) *jobCollectionState {
+	return &jobCollectionState{
+		logger:             logger,
+		failedRuns:         []int64{},
+		failedRunsErrors:   make(map[int64]string),
+		failedRunsStatus:   make(map[int64]int),
+		serverFailedRuns:   make(map[int64]bool),
+		attemptedRuns:      make(map[int64]bool),
+		processedRuns:      make(map[int64]bool),
+		rateLimitHits:      make(map[int64]int),
+		rateLimitMaxWait:   DEFAULT_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS * time.Second,
+		sleep:              time.Sleep,
+		maxErrorBodyLength: DEFAULT_MAX_ERROR_BODY_LENGTH,
+		failureLogLimit:    DEFAULT_JOBS_FAILURE_LOG_LIMIT,
+		etags:              make(map[int64]map[int]string),
+		newEtags:           make(map[jobPageKey]string),
+		now:                time.Now,
+	}
+}
+
+// trackTimings enables the tracking of how long the jobs of each run take to collect
+func (s *jobCollectionState) trackTimings() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timings = make(map[int64]*runCollectionTiming)
+}
+
+// recordTimingLocked counts a response for the timing of the run
+func (s *jobCollectionState) recordTimingLocked(runId int64) {
+	if s.timings == nil {
+		return
+	}
+	if timing, ok := s.timings[runId]; ok {
+		timing.Finished = s.now()
+		timing.Pages++
+	}
+}
+
+// slowestRuns returns the timings of the n runs whose jobs took the longest to collect, the slowest first
+func (s *jobCollectionState) slowestRuns(n int) []runCollectionTiming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	timings := make([]runCollectionTiming, 0, len(s.timings))
+	for _, timing := range s.timings {
+		if timing.Pages > 0 {
+			timings = append(timings, *timing)
+		}
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration() != timings[j].Duration() {
+			return timings[i].Duration() > timings[j].Duration()
+		}
+		return timings[i].RunId < timings[j].RunId
+	})
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+	return timings
+}
+
+// result returns a copy of the failures recorded so far
+func (s *jobCollectionState) result() *JobCollectionResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := &JobCollectionResult{
+		TotalRuns:  len(s.processedRuns),
+		FailedRuns: append([]int64{}, s.failedRuns...),
+		Errors:     make(map[int64]string, len(s.failedRunsErrors)),
+	}
+	for runId, detail := range s.failedRunsErrors {
+		result.Errors[runId] = detail
+	}
+	return result
+}
+
+// jobCollectionSummaryPrefix starts the log line of the summary of the collection of jobs, followed by its JSON
+const jobCollectionSummaryPrefix = "github jobs collection summary: "
+
+// JobCollectionSummary is logged as a single JSON line at the end of the collection of jobs, so external tooling can
+// read the failed runs without parsing the logs meant for humans. Unlike them it lists all the failed runs
+type JobCollectionSummary struct {
+	ConnectionId uint64                        `json:"connection_id"`
+	RepoId       int                           `json:"repo_id"`
+	Repo         string                        `json:"repo"`
+	TotalRuns    int                           `json:"total_runs"`
+	FailedRuns   []JobCollectionSummaryFailure `json:"failed_runs"`
+}
+
+// JobCollectionSummaryFailure is a run whose jobs could not be collected, HttpStatus is 0 when unknown
+type JobCollectionSummaryFailure struct {
+	RunId      int64  `json:"run_id"`
+	HttpStatus int    `json:"http_status"`
+	Error      string `json:"error"`
+}
+
+// summary returns the summary of the collection of jobs of the repo, the failed runs in the order they failed
+func (s *jobCollectionState) summary(op *GithubOptions) *JobCollectionSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := &JobCollectionSummary{
+		ConnectionId: op.ConnectionId,
+		RepoId:       op.GithubId,
+		Repo:         op.Name,
+		TotalRuns:    len(s.processedRuns),
+		FailedRuns:   make([]JobCollectionSummaryFailure, 0, len(s.failedRuns)),
+	}
+	for _, runId := range s.failedRuns {
+		summary.FailedRuns = append(summary.FailedRuns, JobCollectionSummaryFailure{
+			RunId:      runId,
+			HttpStatus: s.failedRunsStatus[runId],
+			Error:      s.failedRunsErrors[runId],
+		})
+	}
+	return summary
+}
+
+// missingJobsError returns the error the collection of jobs fails with when FailPipelineOnJobCollectionError is set,
+// nil when no jobs are missing. The runs which were deleted, moved or blocked have no jobs to miss, only the ones which
+// failed on the server side, ran out of retries or exceeded the page cap do
+func (s *jobCollectionState) missingJobsError() errors.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []int64
+	for _, runId := range s.failedRuns {
+		if !isJobsSkipRunStatus(s.failedRunsStatus[runId]) {
+			missing = append(missing, runId)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return errors.Default.New(fmt.Sprintf("the jobs of %d runs could not be collected, the first one is run %d: %s. "+
+		"The collection fails since failPipelineOnJobCollectionError is set, the jobs collected were kept and the runs "+
+		"are attempted again by the next collection", len(missing), missing[0], s.failedRunsErrors[missing[0]]))
+}
+
+// partialCollectionError returns the error to stop the collection with when the context is cancelled or the ratio
+// of the runs which failed on the server side exceeds maxFailureRatio, nil otherwise
+func (s *jobCollectionState) partialCollectionError() *PartialCollectionError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	processed := len(s.processedRuns)
+	failed := len(s.serverFailedRuns)
+	if s.cancelled() {
+		return &PartialCollectionError{Processed: processed, Failed: failed, Cancelled: true}
+	}
+	if s.maxFailureRatio <= 0 || processed < JOBS_FAILURE_RATIO_MIN_RUNS ||
+		float64(failed) <= s.maxFailureRatio*float64(processed) {
+		return nil
+	}
+	return &PartialCollectionError{Processed: processed, Failed: failed, MaxFailureRatio: s.maxFailureRatio}
+}
+
+// missingPermissionsError returns the error telling which permissions the token is missing to read the jobs, nil
+// when none of the runs was rejected for them
+func (s *jobCollectionState) missingPermissionsError() errors.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.permissionsErr
+}
+
+// recordMissingPermissionsLocked records the run rejected because the token is missing permissions, as forbidden
+// even when GitHub answered 404 so its jobs are not pruned as the ones of a deleted run
+func (s *jobCollectionState) recordMissingPermissionsLocked(runId int64, err errors.Error) {
+	s.recordFailureLocked(runId, http.StatusForbidden, err.Error())
+	if s.permissionsErr == nil {
+		s.permissionsErr = err
+		s.logger.Error(err, "")
+	}
+}
+
+// cancelled tells whether the context of the task was cancelled
+func (s *jobCollectionState) cancelled() bool {
+	return s.ctx != nil && s.ctx.Err() != nil
+}
+
+// jobPageKey identifies a page of the jobs of a run
+type jobPageKey struct {
+	RunId int64
+	Page  int
+}
+
+// etagHeader returns the If-None-Match header of the page when its ETag is known, nil otherwise
+func (s *jobCollectionState) etagHeader(runId int64, page int) (http.Header, errors.Error) {
+	if s.loadEtags == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	pageEtags, loaded := s.etags[runId]
+	s.mu.Unlock()
+	if !loaded {
+		// loaded without the lock held so the other requests don't wait for the database
+		var err errors.Error
+		pageEtags, err = s.loadEtags(runId)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.etags[runId] = pageEtags
+		s.mu.Unlock()
+	}
+	etag := pageEtags[page]
+	if etag == "" {
+		return nil, nil
+	}
+	header := http.Header{}
+	header.Set("If-None-Match", etag)
+	return header, nil
+}
+
+// parseResponse returns the jobs of a page of a run. A page which failed on the server side was recorded as a failure
+// of its run by afterResponse, it yields no jobs instead of failing the collection on its body, which is often not
+// JSON. The jobs of the pages of the run collected before are kept and extracted, the run is flagged jobs_partial and
+// attempted again by the next collection
+func (s *jobCollectionState) parseResponse(res *http.Response) ([]json.RawMessage, errors.Error) {
+	if s.recordEtag(res) {
+		// the jobs didn't change since the raw data of the previous collection, nothing to extract
+		return nil, nil
+	}
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil, nil
+	}
+	return parseJobsResponse(res)
+}
+
+// recordEtag records the ETag of the page of the response, it tells if the page was not modified. Only the ETags of
+// the pages collected successfully are recorded
+func (s *jobCollectionState) recordEtag(res *http.Response) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if res.StatusCode == http.StatusNotModified {
+		s.notModifiedPages++
+		return true
+	}
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" || res.Request == nil || res.Request.URL == nil {
+		return false
+	}
+	page, err := strconv.Atoi(res.Request.URL.Query().Get("page"))
+	if err != nil {
+		return false
+	}
+	s.newEtags[jobPageKey{RunId: runIdOfJobsResponse(res), Page: page}] = etag
+	return false
+}
+
+// markAttempted records that the jobs of the run are being requested
+func (s *jobCollectionState) markAttempted(runId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attemptedRuns[runId] = true
+	if s.timings != nil && s.timings[runId] == nil {
+		s.timings[runId] = &runCollectionTiming{RunId: runId, Started: s.now()}
+	}
+}
+
+// markQueued records the run of the cursor ordered by ids, for checkpointing
+func (s *jobCollectionState) markQueued(runId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queuedRuns = append(s.queuedRuns, runId)
+}
+
+// checkpoint returns the highest queued run that it and all the runs before it were processed,
+// 0 if there is none
+func (s *jobCollectionState) checkpoint() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpointLocked()
+}
+
+func (s *jobCollectionState) checkpointLocked() int64 {
+	for s.checkpointIdx < len(s.queuedRuns) && s.processedRuns[s.queuedRuns[s.checkpointIdx]] {
+		s.checkpointIdx++
+	}
+	if s.checkpointIdx == 0 {
+		return 0
+	}
+	return s.queuedRuns[s.checkpointIdx-1]
+}
+
+// recordFailure records why the jobs of the run could not be collected
+func (s *jobCollectionState) recordFailure(runId int64, httpStatus int, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordFailureLocked(runId, httpStatus, detail)
+}
+
+// recordServerFailure records the runs which failed on the server side or ran out of retries, see serverFailedRuns
+func (s *jobCollectionState) recordServerFailure(runId int64, httpStatus int, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordServerFailureLocked(runId, httpStatus, detail)
+}
+
+func (s *jobCollectionState) recordServerFailureLocked(runId int64, httpStatus int, detail string) {
+	s.recordFailureLocked(runId, httpStatus, detail)
+	s.serverFailedRuns[runId] = true
+}
+
+func (s *jobCollectionState) recordFailureLocked(runId int64, httpStatus int, detail string) {
+	if _, failed := s.failedRunsErrors[runId]; !failed {
+		s.failedRuns = append(s.failedRuns, runId)
+	}
+	s.failedRunsErrors[runId] = detail
+	s.failedRunsStatus[runId] = httpStatus
+}
+
+// warnFailureLocked logs the first failureLogLimit failures one by one, then the runs of every failureLogLimit
+// failures at once, so an outage of GitHub doesn't flood the logs
+func (s *jobCollectionState) warnFailureLocked(runId int64, format string, a ...interface{}) {
+	s.failureWarnings++
+	if s.failureWarnings <= s.failureLogLimit {
+		s.logger.Warn(nil, format, a...)
+		if s.failureWarnings == s.failureLogLimit {
+			s.logger.Warn(nil, "%d runs failed, the next failures are logged every %d runs", s.failureWarnings, s.failureLogLimit)
+		}
+		return
+	}
+	s.unloggedFailures = append(s.unloggedFailures, runId)
+	if len(s.unloggedFailures) >= s.failureLogLimit {
+		s.logger.Warn(nil, "%d more runs failed, %d in total: %v", len(s.unloggedFailures), s.failureWarnings, s.unloggedFailures)
+		s.unloggedFailures = nil
+	}
+}
+
+func (s *jobCollectionState) markProcessedLocked(runId int64) {
+	if s.processedRuns[runId] {
+		return
+	}
+	s.processedRuns[runId] = true
+	if s.onRunProcessed != nil {
+		s.onRunProcessed(len(s.processedRuns), len(s.failedRunsErrors))
+	}
+}
+
+// jobsSkipRunOnStatus are the statuses of the runs which are not there anymore, or which GitHub won't serve,
+// they are skipped right away
+var jobsSkipRunOnStatus = []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusUnavailableForLegalReasons}
+
+// isJobsSkipRunStatus tells whether the run answered with the status is skipped, see jobsSkipRunOnStatus
+func isJobsSkipRunStatus(status int) bool {
+	for _, skipStatus := range jobsSkipRunOnStatus {
+		if status == skipStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// skipRun records the runs which were deleted, moved to another repository or blocked for legal reasons, as
+// failures. The collection continues without them, they don't count towards maxFailureRatio
+func (s *jobCollectionState) skipRun(input interface{}, res *http.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalRuns++
+	runId := runIdOfJobsResponse(res)
+	if run, ok := input.(*SimpleGithubRun); ok {
+		runId = run.ID
+	}
+	defer s.markProcessedLocked(runId)
+	s.recordTimingLocked(runId)
+
+	if err := missingPermissionsError(fmt.Sprintf("the jobs of run %d", runId), res); err != nil {
+		s.recordMissingPermissionsLocked(runId, err)
+		return
+	}
+	if res.StatusCode == http.StatusUnprocessableEntity {
+		// GitHub returns it when the run was moved or the repo renamed
+		s.recordFailureLocked(runId, res.StatusCode, "422 Unprocessable Entity - Run likely moved or repo renamed")
+		s.warnFailureLocked(runId, "GitHub run %d not found in this repository (422) at %s, likely moved or renamed. Skipping...",
+			runId, s.requestPath(res, runId))
+		return
+	}
+	if res.StatusCode == http.StatusUnavailableForLegalReasons {
+		// GitHub returns it for the content taken down, i.e. after a DMCA notice
+		s.recordFailureLocked(runId, res.StatusCode, "451 Unavailable For Legal Reasons - Run blocked by GitHub")
+		s.warnFailureLocked(runId, "GitHub run %d is unavailable for legal reasons (451) at %s. Skipping...",
+			runId, s.requestPath(res, runId))
+		return
+	}
+	s.recordFailureLocked(runId, res.StatusCode, "404 Not Found - Run likely deleted")
+	s.warnFailureLocked(runId, "GitHub run %d not found (404) at %s, likely deleted. Skipping...",
+		runId, s.requestPath(res, runId))
+}
+
+// afterResponse records the runs which failed on the server side
+func (s *jobCollectionState) afterResponse(res *http.Response) errors.Error {
+	if isJobsRateLimited(res) {
+		// pause before the api client retries the request, instead of using up the retries right away
+		s.waitForRateLimit(res)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Count total runs processed
+	s.totalRuns++
+	// the url was generated from the run of the request, so concurrent requests are attributed correctly
+	runId := runIdOfJobsResponse(res)
+	defer s.markProcessedLocked(runId)
+	s.recordTimingLocked(runId)
+
+	if err := missingPermissionsError(fmt.Sprintf("the jobs of run %d", runId), res); err != nil {
+		s.recordMissingPermissionsLocked(runId, err)
+		// the request would be rejected again, it is not retried
+		return api.ErrIgnoreAndContinue
+	}
+	if message := archivedRepoMessage(res); message != "" {
+		// the jobs are read-only, GitHub isn't expected to reject reading them for an archived repo
+		s.recordFailureLocked(runId, res.StatusCode, fmt.Sprintf("403 Forbidden - Repository is archived: %s", message))
+		s.warnFailureLocked(runId, "GitHub rejected the jobs of run %d of an archived repository at %s: %s. "+
+			"This is not a permission of the token, the request is not retried", runId, s.requestPath(res, runId), message)
+		return api.ErrIgnoreAndContinue
+	}
+
+	// Handle 500 errors gracefully (temporary GitHub API issues)
+	if res.StatusCode >= 500 {
+		// Read response body to get error details
+		errorBody := "unknown error"
+		if res.Body != nil {
+			if bodyBytes, err := io.ReadAll(res.Body); err == nil {
+				// Truncate if too long to avoid log spam
+				var truncated bool
+				errorBody, truncated = truncateErrorBody(string(bodyBytes), s.maxErrorBodyLength)
+				if truncated {
+					s.logger.Debug("the body of the %d response for run %d was truncated from %d to %d bytes",
+						res.StatusCode, runId, len(bodyBytes), s.maxErrorBodyLength)
+				}
+			}
+		}
+
+		s.recordServerFailureLocked(runId, res.StatusCode, fmt.Sprintf("%d Server Error: %s", res.StatusCode, errorBody))
+		s.warnFailureLocked(runId, "GitHub API returned %d for run %d: %s. Skipping this run to continue collection",
+			res.StatusCode, runId, errorBody)
+		return nil // Skip this run but continue with others
+	}
+
+	return nil
+}
+
+// waitForRateLimit sleeps as long as GitHub asks for, the lock is not held while sleeping
+func (s *jobCollectionState) waitForRateLimit(res *http.Response) {
+	runId := runIdOfJobsResponse(res)
+	s.mu.Lock()
+	s.rateLimitHits[runId]++
+	wait := jobsRateLimitWait(res, s.rateLimitHits[runId], time.Now(), s.rateLimitMaxWait)
+	s.mu.Unlock()
+
+	s.logger.Warn(nil, "GitHub API rate limited the jobs request of run %d with status %d, waiting %s before retrying",
+		runId, res.StatusCode, wait)
+	s.sleep(wait)
+}
+
+// isJobsRateLimited tells if the response was rejected by the primary or secondary rate limit, a 403 without
+// rate limit headers is a permission error and is not retried later
+func isJobsRateLimited(res *http.Response) bool {
+	if res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if res.StatusCode != http.StatusForbidden {
+		return false
+	}
+	return res.Header.Get("Retry-After") != "" || res.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// jobsRateLimitWait computes how long to wait after the rate limited response, honoring the `Retry-After` and
+// `X-RateLimit-Reset` headers, or backing off exponentially by the number of hits when neither is present.
+// The result never exceeds maxWait
+func jobsRateLimitWait(res *http.Response, hits int, now time.Time, maxWait time.Duration) time.Duration {
+	wait := time.Duration(0)
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			wait = date.Sub(now)
+		}
+	} else if reset := res.Header.Get("X-RateLimit-Reset"); reset != "" {
+		if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			wait = time.Unix(epoch, 0).Sub(now)
+		}
+	} else {
+		// 1s, 2s, 4s ... the shift is bounded to avoid overflowing
+		if hits < 1 {
+			hits = 1
+		}
+		if hits > 16 {
+			hits = 16
+		}
+		wait = time.Second << (hits - 1)
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
+
+// truncateErrorBody keeps the first maxLength bytes of the body, telling the original length when it was cut
+func truncateErrorBody(body string, maxLength int) (string, bool) {
+	if len(body) <= maxLength {
+		return body, false
+	}
+	// the cut might split a multi-byte character
+	truncated := strings.ToValidUTF8(body[:maxLength], "")
+	return fmt.Sprintf("%s... (truncated, %d bytes in total)", truncated, len(body)), true
+}
+
+// runIdOfJobsResponse returns the run id of the request of the response, 0 when the request is missing, i.e. when
+// the transport of the client didn't keep it
+func runIdOfJobsResponse(res *http.Response) int64 {
+	if res.Request == nil || res.Request.URL == nil {
+		return 0
+	}
+	return runIdFromJobsUrl(res.Request.URL)
+}
+
+// requestPath returns the path of the request of the response to log it, or the one of the jobs of the run when the
+// request is missing, so handling a failure doesn't panic
+func (s *jobCollectionState) requestPath(res *http.Response, runId int64) string {
+	if res.Request != nil && res.Request.URL != nil {
+		return res.Request.URL.Path
+	}
+	if s.jobsPath == "" {
+		return fmt.Sprintf("the jobs of run %d", runId)
+	}
+	return fmt.Sprintf(s.jobsPath, runId)
+}
+
+// runIdFromJobsUrl extracts the run id from a url like `.../repos/{owner}/{repo}/actions/runs/{run_id}/jobs`,
+// 0 is returned when there is no run id in it
+func runIdFromJobsUrl(u *url.URL) int64 {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 2; i > 0; i-- {
+		if segments[i-1] == "runs" && segments[i+1] == "jobs" {
+			runId, err := strconv.ParseInt(segments[i], 10, 64)
+			if err == nil {
+				return runId
+			}
+		}
+	}
+	return 0
+}
+
+// jobsRunUpdatedSince selects in incremental mode the runs updated since the last collection, plus the runs that
+// still have jobs which weren't completed when they were collected, or which changed after it. A job is re-run on
+// a new attempt of its run, but a run can be updated before its jobs are, so the collected jobs are checked as well.
+// The tradeoff is that the jobs of a stuck run are requested again on every sync until all of them are completed
+const jobsRunUpdatedSince = "(github_updated_at > ? OR id IN (SELECT run_id FROM _tool_github_jobs " +
+	"WHERE repo_id = ? AND connection_id = ? AND (status != ? OR github_updated_at > ?)))"
+
+// jobsRunStartedSince selects the runs like jobsRunUpdatedSince, by the start of their latest attempt instead of
+// their update, which GitHub also bumps for reasons unrelated to the jobs. A re-run starts a new attempt, so it is
+// still selected, and the runs started before an older GitHub Enterprise Server returned run_started_at fall back
+// to their creation
+const jobsRunStartedSince = "(COALESCE(run_started_at, github_created_at) > ? OR id IN (SELECT run_id FROM _tool_github_jobs " +
+	"WHERE repo_id = ? AND connection_id = ? AND (status != ? OR github_updated_at > ?)))"
+
+const (
+	JobsIncrementalByUpdatedAt    = "updated_at"
+	JobsIncrementalByRunStartedAt = "run_started_at"
+)
+
+const (
+	JobsRunsOldestFirst = "oldest-first"
+	JobsRunsNewestFirst = "newest-first"
+)
+
+// jobsPageQuery is the query of a page of the jobs of a run, the jobs of all the attempts of the run are requested
+// with CollectAllAttempts since the api returns the ones of the latest attempt by default
+func jobsPageQuery(op *GithubOptions, pager *api.Pager) url.Values {
+	query := url.Values{}
+	query.Set("page", fmt.Sprintf("%v", pager.Page))
+	query.Set("per_page", fmt.Sprintf("%v", pager.Size))
+	if op.CollectAllAttempts {
+		query.Set("filter", "all")
+	}
+	return query
+}
+
+// jobsRunsOrderBy orders the runs by id, which grows along with their creation, the checkpoint is the last run
+// whose jobs were collected in this order
+func jobsRunsOrderBy(op *GithubOptions) string {
+	if op.RunsOrder == JobsRunsNewestFirst {
+		return "id DESC"
+	}
+	return "id"
+}
+
+// buildJobsRunClauses returns the clauses to load the runs whose jobs should be collected. Runs updated, or started
+// with JobsIncrementalBy, before `since` are skipped in incremental mode, see jobsRunUpdatedSince, and runs created before JobsCreatedDateAfter are skipped
+// when the option is set, so the more recent of the two bounds wins. When resuming from a checkpoint, the runs
+// up to it in the RunsOrder are skipped unless they were updated after the interrupted collection started. Only the runs on the
+// defaultBranch are loaded when it is not empty, only the completed ones with CompletedRunsOnly, and the runs
+// triggered by one of ExcludeActors are skipped. Only the runs of RunIDs are loaded when it is set, regardless of
+// the other options
+func buildJobsRunClauses(op *GithubOptions, since *time.Time, checkpoint *models.GithubJobCollectionCheckpoint, defaultBranch string) []dal.Clause {
+	clauses := []dal.Clause{
+		dal.Select("id"),
+		dal.From(&models.GithubRun{}),
+		dal.Where(
+			"repo_id = ? AND connection_id = ?",
+			op.GithubId, op.ConnectionId,
+		),
+	}
+	if len(op.RunIDs) > 0 {
+		// the runs are picked by hand, none of the options selecting them applies
+		return append(clauses, dal.Where("id IN ?", op.RunIDs))
+	}
+	if since != nil {
+		incremental := jobsRunUpdatedSince
+		if op.JobsIncrementalBy == JobsIncrementalByRunStartedAt {
+			incremental = jobsRunStartedSince
+		}
+		clauses = append(clauses, dal.Where(
+			incremental,
+			since, op.GithubId, op.ConnectionId, StatusCompleted, since,
+		))
+	}
+	if op.JobsCreatedDateAfter != nil {
+		clauses = append(clauses, dal.Where("github_created_at > ?", op.JobsCreatedDateAfter))
+	}
+	if checkpoint != nil && checkpoint.LastRunId > 0 {
+		resume := "(id > ? OR github_updated_at > ?)"
+		if op.RunsOrder == JobsRunsNewestFirst {
+			resume = "(id < ? OR github_updated_at > ?)"
+		}
+		clauses = append(clauses, dal.Where(resume, checkpoint.LastRunId, checkpoint.StartedAt))
+	}
+	if checkpoint != nil && checkpoint.WindowStart != nil {
+		clauses = append(clauses, dal.Where("(github_created_at >= ? OR github_updated_at > ?)", checkpoint.WindowStart, checkpoint.StartedAt))
+	}
+	if len(op.WorkflowNames) > 0 {
+		// a workflow is matched by its name or by the path of its file, i.e. ".github/workflows/ci.yml"
+		clauses = append(clauses, dal.Where("(name IN ? OR path IN ?)", op.WorkflowNames, op.WorkflowNames))
+	}
+	if defaultBranch != "" {
+		// compared to a value rather than joined with the repos, so an index on head_branch can be used
+		clauses = append(clauses, dal.Where("head_branch = ?", defaultBranch))
+	}
+	if op.CompletedRunsOnly {
+		// the runs keep the status of the api, which is lowercase
+		clauses = append(clauses, dal.Where("status = ?", strings.ToLower(StatusCompleted)))
+	}
+	for _, actor := range op.ExcludeActors {
+		// the runs extracted before the actor was stored have none, they are not excluded
+		clauses = append(clauses, dal.Where("COALESCE(triggering_actor, '') NOT LIKE ?", actorLikePattern(actor)))
+	}
+	return clauses
+}
+
+// validateJobsRunIds makes sure all the runs of RunIDs are runs of the repo of the connection, so a typo isn't
+// silently skipped
+func validateJobsRunIds(db dal.Dal, op *GithubOptions) errors.Error {
+	var runIds []int64
+	err := db.Pluck("id", &runIds,
+		dal.From(&models.GithubRun{}),
+		dal.Where("repo_id = ? AND connection_id = ? AND id IN ?", op.GithubId, op.ConnectionId, op.RunIDs),
+	)
+	if err != nil {
+		return err
+	}
+	found := make(map[int64]bool, len(runIds))
+	for _, runId := range runIds {
+		found[runId] = true
+	}
+	var unknown []int64
+	for _, runId := range op.RunIDs {
+		if !found[runId] {
+			unknown = append(unknown, runId)
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.BadInput.New(fmt.Sprintf("the runs %v are not runs of the repo %s of connection %d, collect the runs first",
+			unknown, op.Name, op.ConnectionId))
+	}
+	return nil
+}
+
+// actorLikePattern turns a pattern of ExcludeActors into the one of a LIKE, `*` matches any characters and the
+// other ones match themselves, i.e. the brackets of "*[bot]" or the underscores of a login
+func actorLikePattern(actor string) string {
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(actor)
+	return strings.ReplaceAll(pattern, "*", "%")
+}
+
+// loadJobsDefaultBranch returns the default branch of the repo when DefaultBranchOnly is set, it is empty when the
+// option is not set or the default branch is unknown, so the jobs of all the runs are collected
+func loadJobsDefaultBranch(db dal.Dal, logger log.Logger, op *GithubOptions) (string, errors.Error) {
+	if !op.DefaultBranchOnly {
+		return "", nil
+	}
+	repo := &models.GithubRepo{}
+	err := db.First(repo, dal.Where("connection_id = ? AND github_id = ?", op.ConnectionId, op.GithubId))
+	if err != nil && !db.IsErrorNotFound(err) {
+		return "", errors.Default.Wrap(err, "failed to load the default branch of the repo")
+	}
+	if repo.DefaultBranch == "" {
+		logger.Warn(nil, "the default branch of %s is unknown, collecting the jobs of the runs on all branches", op.Name)
+	}
+	return repo.DefaultBranch, nil
+}
+
+// estimateJobsRequests logs how many requests collecting the jobs of the runs takes, without sending any of them
+func estimateJobsRequests(db dal.Dal, logger log.Logger, op *GithubOptions, clauses []dal.Clause, failuresToRetry int64, capped bool) errors.Error {
+	runs, requests, err := countJobsRequests(db, op, clauses, failuresToRetry, capped)
+	if err != nil {
+		return err
+	}
+	logger.Info("dry run: collecting the jobs of %d runs takes about %d requests, nothing was collected", runs, requests)
+	return nil
+}
+
+// countJobsRequests walks the runs whose jobs would be collected and counts them along with the requests it takes.
+// The number of pages of a run is guessed from the jobs collected before, a run which was never collected is counted
+// as a single page, as are the failed runs to retry. Only the first MaxRunsPerRun runs are walked when the collection
+// is capped
+func countJobsRequests(db dal.Dal, op *GithubOptions, clauses []dal.Clause, failuresToRetry int64, capped bool) (int64, int64, errors.Error) {
+	var jobCounts []struct {
+		RunId int64
+		Count int
+	}
+	err := db.All(
+		&jobCounts,
+		dal.Select("run_id, COUNT(*) AS count"),
+		dal.From(&models.GithubJob{}),
+		dal.Where("repo_id = ? AND connection_id = ?", op.GithubId, op.ConnectionId),
+		dal.Groupby("run_id"),
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	jobsOfRuns := make(map[int64]int, len(jobCounts))
+	for _, jobCount := range jobCounts {
+		jobsOfRuns[jobCount.RunId] = jobCount.Count
+	}
+
+	runClauses := append(append([]dal.Clause{}, clauses...), dal.Orderby(jobsRunsOrderBy(op)))
+	if capped {
+		runClauses = append(runClauses, dal.Limit(op.MaxRunsPerRun))
+	}
+	cursor, err := db.Cursor(runClauses...)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close()
+	pageSize := getJobsPageSize(op)
+	runs, requests := failuresToRetry, failuresToRetry
+	for cursor.Next() {
+		run := &SimpleGithubRun{}
+		err = db.Fetch(cursor, run)
+		if err != nil {
+			return 0, 0, err
+		}
+		runs++
+		requests += int64(estimateJobsPages(jobsOfRuns[run.ID], pageSize))
+	}
+	return runs, requests, nil
+}
+
+// estimateJobsPages is the number of pages of jobs of a run, there is always one page even if the run has no jobs
+func estimateJobsPages(jobs int, pageSize int) int {
+	if jobs <= pageSize {
+		return 1
+	}
+	return (jobs + pageSize - 1) / pageSize
+}
+
+// incrementalStateManager is the part of api.CollectorStateManager deciding what time range is collected
+type incrementalStateManager interface {
+	IsIncremental() bool
+	GetSince() *time.Time
+	ResetToFullSync()
+}
+
+// resetJobsIncrementalState switches to a full sync when the stored incremental state is discarded on purpose with
+// ForceFullSync, or when it can't be trusted: a since in the future, i.e. after a clock skew, would skip every run
+// and leave the jobs uncollected silently. It returns whether the state was reset
+func resetJobsIncrementalState(stateManager incrementalStateManager, op *GithubOptions, logger log.Logger, now time.Time) bool {
+	if !stateManager.IsIncremental() {
+		return false
+	}
+	if op.ForceFullSync {
+		logger.Info("forceFullSync is set, discarding the incremental state of the jobs collection")
+		stateManager.ResetToFullSync()
+		return true
+	}
+	since := stateManager.GetSince()
+	if since != nil && since.After(now) {
+		logger.Warn(nil, "the jobs were last collected at %s which is in the future, collecting them all again", since)
+		stateManager.ResetToFullSync()
+		return true
+	}
+	return false
+}
+
+// withIncrementalOverlap moves since back by IncrementalOverlapSeconds, the updated_at of a run can lag behind the
+// completion of its jobs, so the runs updated right at the end of the previous collection could be skipped otherwise.
+// The jobs collected again are upserted by the extractor
+func withIncrementalOverlap(since *time.Time, op *GithubOptions) *time.Time {
+	if since == nil || op.IncrementalOverlapSeconds == nil || *op.IncrementalOverlapSeconds == 0 {
+		return since
+	}
+	overlapped := since.Add(-time.Duration(*op.IncrementalOverlapSeconds) * time.Second)
+	return &overlapped
+}
+
+// loadJobCollectionCheckpoint loads the checkpoint of the interrupted collection, a new one starting now is
+// returned when there is none. The runs of a checkpoint saved in the other RunsOrder are all collected again
+// since the ones left are not on the same side of its last run
+func loadJobCollectionCheckpoint(db dal.Dal, op *GithubOptions) (*models.GithubJobCollectionCheckpoint, errors.Error) {
+	checkpoint := &models.GithubJobCollectionCheckpoint{}
+	err := db.First(checkpoint, dal.Where("repo_id = ? AND connection_id = ?", op.GithubId, op.ConnectionId))
+	if err != nil {
+		if !db.IsErrorNotFound(err) {
+			return nil, errors.Default.Wrap(err, "failed to load the job collection checkpoint")
+		}
+		checkpoint = &models.GithubJobCollectionCheckpoint{
+			ConnectionId: op.ConnectionId,
+			RepoId:       op.GithubId,
+			StartedAt:    time.Now(),
+		}
+	}
+	if jobsCheckpointOrder(checkpoint) != op.RunsOrder {
+		checkpoint.LastRunId = 0
+	}
+	checkpoint.RunsOrder = op.RunsOrder
+	return checkpoint, nil
+}
+
+// jobsCheckpointOrder is the RunsOrder the checkpoint was saved in, the checkpoints saved before it was recorded
+// were collected oldest first
+func jobsCheckpointOrder(checkpoint *models.GithubJobCollectionCheckpoint) string {
+	if checkpoint.RunsOrder == "" {
+		return JobsRunsOldestFirst
+	}
+	return checkpoint.RunsOrder
+}
+
+// jobsCheckpointMoves tells if the runs up to lastRunId go past the checkpoint in the order the runs are collected in,
+// that is towards the higher ids for oldest-first and towards the lower ones for newest-first
+func jobsCheckpointMoves(op *GithubOptions, checkpoint *models.GithubJobCollectionCheckpoint, lastRunId int64) bool {
+	if lastRunId == 0 {
+		return false
+	}
+	if checkpoint.LastRunId == 0 {
+		return true
+	}
+	if op.RunsOrder == JobsRunsNewestFirst {
+		return lastRunId < checkpoint.LastRunId
+	}
+	return lastRunId > checkpoint.LastRunId
+}
+
+// saveJobCollectionCheckpoint persists the progress, it only logs the error since losing a checkpoint
+// means collecting some runs again at worst
+func saveJobCollectionCheckpoint(
+	db dal.Dal,
+	logger log.Logger,
+	op *GithubOptions,
+	checkpoint *models.GithubJobCollectionCheckpoint,
+	lastRunId int64,
+) {
+	if !jobsCheckpointMoves(op, checkpoint, lastRunId) {
+		return
+	}
+	checkpoint.LastRunId = lastRunId
+	err := db.CreateOrUpdate(checkpoint)
+	if err != nil {
+		logger.Warn(err, "failed to save the job collection checkpoint at run %d", lastRunId)
+	}
+}
+
+// saveCappedJobCollectionCheckpoint persists the progress of a collection which stopped at MaxRunsPerRun along with
+// the since it selected the runs with, the incremental state moves on when the collection ends, so the next one
+// would skip the runs left otherwise
+func saveCappedJobCollectionCheckpoint(
+	db dal.Dal,
+	op *GithubOptions,
+	checkpoint *models.GithubJobCollectionCheckpoint,
+	since *time.Time,
+	lastRunId int64,
+) errors.Error {
+	checkpoint.Capped = true
+	checkpoint.Since = since
+	if jobsCheckpointMoves(op, checkpoint, lastRunId) {
+		checkpoint.LastRunId = lastRunId
+	}
+	err := db.CreateOrUpdate(checkpoint)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to save the job collection checkpoint")
+	}
+	return nil
+}
+
+// jobsWindow is a window of the creation of the runs whose jobs are collected together, a nil Start includes the runs
+// created before End and the ones without a creation date, a nil End the ones created since Start
+type jobsWindow struct {
+	Start *time.Time
+	End   *time.Time
+}
+
+func (w jobsWindow) clause() dal.Clause {
+	switch {
+	case w.Start == nil && w.End == nil:
+		return dal.Where("1 = 1")
+	case w.Start == nil:
+		return dal.Where("(github_created_at < ? OR github_created_at IS NULL)", w.End)
+	case w.End == nil:
+		return dal.Where("github_created_at >= ?", w.Start)
+	default:
+		return dal.Where("github_created_at >= ? AND github_created_at < ?", w.Start, w.End)
+	}
+}
+
+// jobsWindows splits the creation dates of the runs from first to last into windows of days, starting at the
+// midnight UTC before first. The first window has no start and the last one no end, so all the runs are in one
+func jobsWindows(first time.Time, last time.Time, days int) []jobsWindow {
+	size := time.Duration(days) * 24 * time.Hour
+	windows := []jobsWindow{}
+	var start *time.Time
+	for end := first.UTC().Truncate(24 * time.Hour).Add(size); !end.After(last); end = end.Add(size) {
+		end := end
+		windows = append(windows, jobsWindow{Start: start, End: &end})
+		start = &end
+	}
+	return append(windows, jobsWindow{Start: start})
+}
+
+// loadJobsWindows returns the windows of the runs selected by clauses, whose first one selects their ids
+func loadJobsWindows(db dal.Dal, clauses []dal.Clause, days int) ([]jobsWindow, errors.Error) {
+	var bounds []struct {
+		FirstCreatedAt *time.Time
+		LastCreatedAt  *time.Time
+	}
+	err := db.All(&bounds, append([]dal.Clause{
+		dal.Select("MIN(github_created_at) AS first_created_at, MAX(github_created_at) AS last_created_at"),
+	}, clauses[1:]...)...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to load the creation dates of the runs")
+	}
+	if len(bounds) == 0 || bounds[0].FirstCreatedAt == nil || bounds[0].LastCreatedAt == nil {
+		return []jobsWindow{{}}, nil
+	}
+	return jobsWindows(*bounds[0].FirstCreatedAt, *bounds[0].LastCreatedAt, days), nil
+}
+
+// jobsWindowIterator opens the cursor of the runs of a window when the collector of the window starts, so a single
+// cursor is open at a time. An error opening it is returned by Fetch, the collector fails with it
+type jobsWindowIterator struct {
+	api.Iterator
+	open func() (api.Iterator, errors.Error)
+	err  errors.Error
+}
+
+func (it *jobsWindowIterator) HasNext() bool {
+	if it.Iterator == nil && it.err == nil {
+		it.Iterator, it.err = it.open()
+	}
+	return it.err != nil || it.Iterator.HasNext()
+}
+
+func (it *jobsWindowIterator) Fetch() (interface{}, errors.Error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	return it.Iterator.Fetch()
+}
+
+func (it *jobsWindowIterator) Close() errors.Error {
+	if it.Iterator == nil {
+		return nil
+	}
+	return it.Iterator.Close()
+}
+
+// saveJobsWindowCheckpoint persists the start of the window being collected, it only logs the error like
+// saveJobCollectionCheckpoint
+func saveJobsWindowCheckpoint(db dal.Dal, logger log.Logger, checkpoint *models.GithubJobCollectionCheckpoint, windowStart *time.Time) {
+	checkpoint.WindowStart = windowStart
+	err := db.CreateOrUpdate(checkpoint)
+	if err != nil {
+		logger.Warn(err, "failed to save the job collection checkpoint at the window starting at %s", windowStart)
+	}
+}
+
+// capJobsRuns caps the number of runs to collect the jobs of by MaxRunsPerRun, it tells whether some were left
+func capJobsRuns(op *GithubOptions, runsToProcess int64) (int64, bool) {
+	if op.MaxRunsPerRun > 0 && runsToProcess > int64(op.MaxRunsPerRun) {
+		return int64(op.MaxRunsPerRun), true
+	}
+	return runsToProcess, false
+}
+
+// saveJobCollectionFailures records the failed runs into the dead-letter table and removes the
+// runs which were collected successfully this time, the jobs_partial flag of the runs is updated accordingly
+func saveJobCollectionFailures(
+	db dal.Dal,
+	op *GithubOptions,
+	attemptedRuns map[int64]bool,
+	failedRunsErrors map[int64]string,
+	failedRunsStatus map[int64]int,
+) errors.Error {
+	succeededRuns := make([]int64, 0, len(attemptedRuns))
+	for runId := range attemptedRuns {
+		if _, failed := failedRunsErrors[runId]; !failed {
+			succeededRuns = append(succeededRuns, runId)
+		}
+	}
+	if len(succeededRuns) > 0 {
+		err := db.Delete(
+			&models.GithubJobCollectionFailure{},
+			dal.Where(
+				"repo_id = ? AND connection_id = ? AND run_id IN ?",
+				op.GithubId, op.ConnectionId, succeededRuns,
+			),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to delete resolved job collection failures")
+		}
+	}
+	if len(succeededRuns) > 0 {
+		err := db.UpdateColumn(
+			&models.GithubRun{}, "jobs_partial", false,
+			dal.Where(
+				"repo_id = ? AND connection_id = ? AND id IN ?",
+				op.GithubId, op.ConnectionId, succeededRuns,
+			),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to reset jobs_partial of runs")
+		}
+	}
+	now := time.Now()
+	for runId, errorMsg := range failedRunsErrors {
+		err := db.CreateOrUpdate(&models.GithubJobCollectionFailure{
+			ConnectionId: op.ConnectionId,
+			RepoId:       op.GithubId,
+			RunId:        runId,
+			HttpStatus:   failedRunsStatus[runId],
+			ErrorDetail:  errorMsg,
+			CollectedAt:  now,
+		})
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to record job collection failure of run %d", runId))
+		}
+	}
+	if len(failedRunsErrors) > 0 {
+		failedRuns := make([]int64, 0, len(failedRunsErrors))
+		for runId := range failedRunsErrors {
+			failedRuns = append(failedRuns, runId)
+		}
+		err := db.UpdateColumn(
+			&models.GithubRun{}, "jobs_partial", true,
+			dal.Where(
+				"repo_id = ? AND connection_id = ? AND id IN ?",
+				op.GithubId, op.ConnectionId, failedRuns,
+			),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to set jobs_partial of runs")
+		}
+	}
+	return nil
+}
+
+// loadJobPageEtags loads the ETags of the pages of jobs of the run collected before by page
+func loadJobPageEtags(db dal.Dal, op *GithubOptions, runId int64) (map[int]string, errors.Error) {
+	var pageEtags []*models.GithubJobPageEtag
+	err := db.All(
+		&pageEtags,
+		dal.Where("repo_id = ? AND connection_id = ? AND run_id = ?", op.GithubId, op.ConnectionId, runId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to load the ETags of the jobs of run %d", runId))
+	}
+	etags := make(map[int]string, len(pageEtags))
+	for _, pageEtag := range pageEtags {
+		etags[pageEtag.Page] = pageEtag.Etag
+	}
+	return etags, nil
+}
+
+// jobPageEtagsOfGoneRuns selects the ETags of the runs which are no longer in the runs table, i.e. the runs which
+// aged out of a full sync of the runs
+const jobPageEtagsOfGoneRuns = "repo_id = ? AND connection_id = ? AND run_id NOT IN " +
+	"(SELECT id FROM _tool_github_runs WHERE repo_id = ? AND connection_id = ?)"
+
+// jobPageEtagsOfRunsBefore selects the ETags of the runs created before JobsCreatedDateAfter, whose jobs are no
+// longer collected
+const jobPageEtagsOfRunsBefore = "repo_id = ? AND connection_id = ? AND run_id IN " +
+	"(SELECT id FROM _tool_github_runs WHERE repo_id = ? AND connection_id = ? AND github_created_at <= ?)"
+
+// pruneJobPageEtags deletes the ETags of the runs whose jobs are no longer collected, so they don't pile up. The
+// ETags of the deleted runs are deleted along with their jobs by pruneJobsOfRuns
+func pruneJobPageEtags(db dal.Dal, op *GithubOptions) errors.Error {
+	err := db.Delete(
+		&models.GithubJobPageEtag{},
+		dal.Where(jobPageEtagsOfGoneRuns, op.GithubId, op.ConnectionId, op.GithubId, op.ConnectionId),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete the ETags of the jobs of the runs which are gone")
+	}
+	if op.JobsCreatedDateAfter == nil {
+		return nil
+	}
+	err = db.Delete(
+		&models.GithubJobPageEtag{},
+		dal.Where(jobPageEtagsOfRunsBefore, op.GithubId, op.ConnectionId, op.GithubId, op.ConnectionId, op.JobsCreatedDateAfter),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete the ETags of the jobs of the runs created before jobsCreatedDateAfter")
+	}
+	return nil
+}
+
+// saveJobPageEtags saves the ETags of the pages collected this time
+func saveJobPageEtags(db dal.Dal, op *GithubOptions, etags map[jobPageKey]string) errors.Error {
+	if len(etags) == 0 {
+		return nil
+	}
+	pageEtags := make([]*models.GithubJobPageEtag, 0, len(etags))
+	for key, etag := range etags {
+		pageEtags = append(pageEtags, &models.GithubJobPageEtag{
+			ConnectionId: op.ConnectionId,
+			RepoId:       op.GithubId,
+			RunId:        key.RunId,
+			Page:         key.Page,
+			Etag:         etag,
+		})
+	}
+	err := db.CreateOrUpdate(pageEtags)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to save the ETags of the jobs")
+	}
+	return nil
+}
+
+// getJobsPageSize falls back to the default page size when the option was not validated
+func getJobsPageSize(op *GithubOptions) int {
+	if op.JobsPageSize < 1 || op.JobsPageSize > DEFAULT_JOBS_PAGE_SIZE {
+		return DEFAULT_JOBS_PAGE_SIZE
+	}
+	return op.JobsPageSize
+}
+
+// getMaxErrorBodyLength falls back to the default length when the option was not validated
+func getMaxErrorBodyLength(op *GithubOptions) int {
+	if op.MaxErrorBodyLength < 1 {
+		return DEFAULT_MAX_ERROR_BODY_LENGTH
+	}
+	return op.MaxErrorBodyLength
+}
+
+// getJobsFailureLogLimit falls back to the default limit when the option was not validated
+func getJobsFailureLogLimit(op *GithubOptions) int {
+	if op.JobsFailureLogLimit < 1 {
+		return DEFAULT_JOBS_FAILURE_LOG_LIMIT
+	}
+	return op.JobsFailureLogLimit
+}
+
+// getJobsRateLimitMaxWaitSeconds falls back to the default max wait when the option was not validated
+func getJobsRateLimitMaxWaitSeconds(op *GithubOptions) int {
+	if op.JobsRateLimitMaxWaitSeconds < 1 || op.JobsRateLimitMaxWaitSeconds > MAX_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS {
+		return DEFAULT_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS
+	}
+	return op.JobsRateLimitMaxWaitSeconds
+}
+
+// runProgressSubTaskContext ignores the page based progress of the api collector,
+// CollectJobs reports the progress by runs instead
+type runProgressSubTaskContext struct {
+	plugin.SubTaskContext
+}
+
+func (c *runProgressSubTaskContext) SetProgress(current int, total int) {}
+
+func (c *runProgressSubTaskContext) IncProgress(quantity int) {}
+
+// totalPages caps the pages of the run at maxPagesPerRun, so a run for which GitHub reports a wildly wrong number of
+// jobs doesn't stall the collection paginating forever. The first pages are collected anyway, and the run is recorded
+// as failed so it is attempted again by the next collection
+func (s *jobCollectionState) totalPages(res *http.Response, args *api.ApiCollectorArgs) (int, errors.Error) {
+	pages, err := getJobsTotalPages(res, args)
+	if err != nil || s.maxPagesPerRun <= 0 || pages <= s.maxPagesPerRun {
+		return pages, err
+	}
+	runId := runIdOfJobsResponse(res)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordFailureLocked(runId, 0, fmt.Sprintf("page cap exceeded - %d pages of jobs reported, only the first %d were collected",
+		pages, s.maxPagesPerRun))
+	s.warnFailureLocked(runId, "GitHub reported %d pages of jobs for run %d at %s, more than jobsMaxPagesPerRun of %d. "+
+		"Only the first %d pages are collected", pages, runId, s.requestPath(res, runId), s.maxPagesPerRun, s.maxPagesPerRun)
+	return s.maxPagesPerRun, nil
+}
+
+// getJobsTotalPages reads the number of pages from the Link header of the first page. GitHub sometimes omits it
+// or sends it without the last page, the number of pages is computed from the total_count of the jobs then
+func getJobsTotalPages(res *http.Response, args *api.ApiCollectorArgs) (int, errors.Error) {
+	link := res.Header.Get("link")
+	if strings.Contains(link, `rel="last"`) {
+		pageInfo, err := utils.GetPagingFromLinkHeader(link)
+		if err == nil {
+			return pageInfo.Last, nil
+		}
+	}
+	if res.Body == nil || args.PageSize <= 0 {
+		return 0, nil
+	}
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, errors.Convert(err)
+	}
+	res.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	body := &GithubRawJobsResult{}
+	if json.Unmarshal(bodyBytes, body) != nil {
+		return 0, nil
+	}
+	return int((body.TotalCount + int64(args.PageSize) - 1) / int64(args.PageSize)), nil
+}
+
+// parseJobsResponse returns the jobs of a page of the jobs api. Some proxies, and some versions of GitHub Enterprise
+// Server, return the jobs as a bare array instead of the object wrapping them, both are accepted so the collection
+// doesn't silently yield no jobs. The number of pages of a bare array is only known from the Link header
+func parseJobsResponse(res *http.Response) ([]json.RawMessage, errors.Error) {
+	var body json.RawMessage
+	err := api.UnmarshalResponse(res, &body)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var jobs []json.RawMessage
+		return jobs, errors.Convert(json.Unmarshal(trimmed, &jobs))
+	}
+	result := &GithubRawJobsResult{}
+	err = errors.Convert(json.Unmarshal(body, result))
+	if err != nil {
+		return nil, err
+	}
+	return result.GithubWorkflowJobs, nil
+}
+
 type SimpleGithubRun struct {
 	ID int64
 }
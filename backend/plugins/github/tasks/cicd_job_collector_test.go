@@ -0,0 +1,1513 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	mocklog "github.com/apache/incubator-devlake/mocks/core/log"
+	mockapi "github.com/apache/incubator-devlake/mocks/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBuildJobsRunClauses(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	whereClauses := func(clauses []dal.Clause) []string {
+		wheres := []string{}
+		for _, clause := range clauses {
+			if clause.Type == dal.WhereClause {
+				wheres = append(wheres, clause.Data.(dal.DalClause).Expr)
+			}
+		}
+		return wheres
+	}
+
+	op := &GithubOptions{ConnectionId: 1, GithubId: 2}
+	assert.Equal(t, []string{"repo_id = ? AND connection_id = ?"}, whereClauses(buildJobsRunClauses(op, nil, nil, "")))
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		jobsRunUpdatedSince,
+	}, whereClauses(buildJobsRunClauses(op, &since, nil, "")))
+
+	op.JobsCreatedDateAfter = &createdAfter
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"github_created_at > ?",
+	}, whereClauses(buildJobsRunClauses(op, nil, nil, "")))
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		jobsRunUpdatedSince,
+		"github_created_at > ?",
+	}, whereClauses(buildJobsRunClauses(op, &since, nil, "")))
+
+	// only resumed when the checkpoint has progress
+	checkpoint := &models.GithubJobCollectionCheckpoint{StartedAt: since}
+	assert.Len(t, whereClauses(buildJobsRunClauses(op, &since, checkpoint, "")), 3)
+	checkpoint.LastRunId = 42
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		jobsRunUpdatedSince,
+		"github_created_at > ?",
+		"(id > ? OR github_updated_at > ?)",
+	}, whereClauses(buildJobsRunClauses(op, &since, checkpoint, "")))
+
+	// the runs left are the older ones when the newest are collected first
+	op.RunsOrder = JobsRunsNewestFirst
+	assert.Equal(t, "(id < ? OR github_updated_at > ?)", whereClauses(buildJobsRunClauses(op, &since, checkpoint, ""))[3])
+	op.RunsOrder = ""
+
+	// resumed at the window being collected
+	checkpoint = &models.GithubJobCollectionCheckpoint{StartedAt: since, WindowStart: &createdAfter}
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"github_created_at > ?",
+		"(github_created_at >= ? OR github_updated_at > ?)",
+	}, whereClauses(buildJobsRunClauses(op, nil, checkpoint, "")))
+
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, WorkflowNames: []string{"CI", ".github/workflows/release.yml"}}
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"(name IN ? OR path IN ?)",
+	}, whereClauses(buildJobsRunClauses(op, nil, nil, "")))
+
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, DefaultBranchOnly: true}
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"head_branch = ?",
+	}, whereClauses(buildJobsRunClauses(op, nil, nil, "main")))
+	// the default branch is unknown
+	assert.Equal(t, []string{"repo_id = ? AND connection_id = ?"}, whereClauses(buildJobsRunClauses(op, nil, nil, "")))
+
+	// the runs picked by hand ignore since, the checkpoint and the options selecting the runs
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, RunIDs: []int64{7, 9}, DefaultBranchOnly: true, CompletedRunsOnly: true}
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"id IN ?",
+	}, whereClauses(buildJobsRunClauses(op, &since, checkpoint, "main")))
+
+	// the runs are selected by the start of their latest attempt
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, JobsIncrementalBy: JobsIncrementalByRunStartedAt}
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		jobsRunStartedSince,
+	}, whereClauses(buildJobsRunClauses(op, &since, nil, "")))
+	assert.Nil(t, ValidateTaskOptions(&GithubOptions{ConnectionId: 1, Name: "a/b", JobsIncrementalBy: JobsIncrementalByRunStartedAt}))
+	assert.NotNil(t, ValidateTaskOptions(&GithubOptions{ConnectionId: 1, Name: "a/b", JobsIncrementalBy: "completed_at"}))
+
+	// the runs in progress are excluded
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, CompletedRunsOnly: true}
+	clauses := buildJobsRunClauses(op, &since, nil, "")
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		jobsRunUpdatedSince,
+		"status = ?",
+	}, whereClauses(clauses))
+	assert.Equal(t, []interface{}{"completed"}, clauses[4].Data.(dal.DalClause).Params)
+
+	op = &GithubOptions{ConnectionId: 1, GithubId: 2, ExcludeActors: []string{"*[bot]", "octo_cat"}}
+	clauses = buildJobsRunClauses(op, nil, nil, "")
+	assert.Equal(t, []string{
+		"repo_id = ? AND connection_id = ?",
+		"COALESCE(triggering_actor, '') NOT LIKE ?",
+		"COALESCE(triggering_actor, '') NOT LIKE ?",
+	}, whereClauses(clauses))
+	assert.Equal(t, []interface{}{"%[bot]"}, clauses[3].Data.(dal.DalClause).Params)
+	assert.Equal(t, []interface{}{`octo\_cat`}, clauses[4].Data.(dal.DalClause).Params)
+}
+
+func TestActorLikePattern(t *testing.T) {
+	for _, tc := range []struct {
+		actor   string
+		pattern string
+	}{
+		// the bots, i.e. dependabot[bot] and renovate[bot]
+		{"*[bot]", "%[bot]"},
+		{"dependabot[bot]", "dependabot[bot]"},
+		{"renovate*", "renovate%"},
+		{"*", "%"},
+		{"octo_cat", `octo\_cat`},
+		{"100%", `100\%`},
+		{`a\b`, `a\\b`},
+	} {
+		t.Run(tc.actor, func(t *testing.T) {
+			assert.Equal(t, tc.pattern, actorLikePattern(tc.actor))
+		})
+	}
+}
+
+func TestBuildJobsRunClausesRecollectsUnfinishedJobs(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	op := &GithubOptions{ConnectionId: 1, GithubId: 2}
+	clauses := buildJobsRunClauses(op, &since, nil, "")
+	// a job re-run after the last sync is left queued or in progress by it, so its run is collected again
+	// even though the run itself wasn't updated since
+	where := clauses[3].Data.(dal.DalClause)
+	assert.Equal(t, jobsRunUpdatedSince, where.Expr)
+	assert.Equal(t, []interface{}{&since, 2, uint64(1), StatusCompleted, &since}, where.Params)
+}
+
+func TestJobCollectionStateCheckpoint(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	response := func(runId int64) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Request:    &http.Request{URL: &url.URL{Path: fmt.Sprintf("/repos/a/b/actions/runs/%d/jobs", runId)}},
+		}
+	}
+	for _, runId := range []int64{10, 20, 30} {
+		state.markAttempted(runId)
+		state.markQueued(runId)
+	}
+	assert.Equal(t, int64(0), state.checkpoint())
+
+	// responses arrive out of order, the checkpoint only moves past runs whose predecessors are all done
+	assert.Nil(t, state.afterResponse(response(20)))
+	assert.Equal(t, int64(0), state.checkpoint())
+	assert.Nil(t, state.afterResponse(response(10)))
+	assert.Equal(t, int64(20), state.checkpoint())
+	assert.Nil(t, state.afterResponse(response(30)))
+	assert.Equal(t, int64(30), state.checkpoint())
+}
+
+func TestJobCollectionStateConcurrentRuns(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	processed := 0
+	state.onRunProcessed = func(p int, failures int) {
+		processed = p
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(runId int64) {
+			defer wg.Done()
+			state.markAttempted(runId)
+			res := &http.Response{
+				StatusCode: http.StatusOK,
+				Request:    &http.Request{URL: &url.URL{Path: fmt.Sprintf("/repos/a/b/actions/runs/%d/jobs", runId)}},
+			}
+			if runId%10 == 0 {
+				res.StatusCode = http.StatusNotFound
+				state.skipRun(&SimpleGithubRun{ID: runId}, res)
+			} else {
+				assert.Nil(t, state.afterResponse(res))
+			}
+			if runId%25 == 0 {
+				state.recordFailure(runId, http.StatusBadGateway, "Retry failure")
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+
+	assert.Len(t, state.attemptedRuns, 50)
+	assert.Len(t, state.processedRuns, 50)
+	assert.Equal(t, 50, state.totalRuns)
+	assert.Equal(t, len(state.processedRuns), processed)
+	assert.Equal(t, len(state.failedRunsErrors), len(state.failedRuns))
+}
+
+func TestJobCollectionStateAttributesInterleavedRuns(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	response := func(statusCode int, path string) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader("boom")),
+			Request:    &http.Request{URL: &url.URL{Path: path}},
+		}
+	}
+
+	// both runs are requested before any of the responses arrives
+	state.markAttempted(111)
+	state.markAttempted(222)
+	// the input of the skipped request could not be resolved, so the run is told by the url
+	state.skipRun(nil, response(http.StatusNotFound, "/api/v3/repos/a/b/actions/runs/111/jobs"))
+	assert.Nil(t, state.afterResponse(response(http.StatusBadGateway, "/repos/a/b/actions/runs/222/jobs")))
+
+	assert.Equal(t, []int64{111, 222}, state.failedRuns)
+	assert.Equal(t, http.StatusNotFound, state.failedRunsStatus[111])
+	assert.Equal(t, "404 Not Found - Run likely deleted", state.failedRunsErrors[111])
+	assert.Equal(t, http.StatusBadGateway, state.failedRunsStatus[222])
+	assert.Equal(t, "502 Server Error: boom", state.failedRunsErrors[222])
+}
+
+func TestJobCollectionStateMovedRun(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.markAttempted(333)
+	state.skipRun(&SimpleGithubRun{ID: 333}, &http.Response{
+		StatusCode: http.StatusUnprocessableEntity,
+		Body:       io.NopCloser(strings.NewReader(`{"message": "No workflow run found in this repository"}`)),
+		Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/333/jobs"}},
+	})
+
+	assert.Equal(t, []int64{333}, state.failedRuns)
+	assert.Equal(t, http.StatusUnprocessableEntity, state.failedRunsStatus[333])
+	assert.Equal(t, "422 Unprocessable Entity - Run likely moved or repo renamed", state.failedRunsErrors[333])
+	assert.True(t, state.processedRuns[333])
+}
+
+func TestJobCollectionStateUnavailableForLegalReasons(t *testing.T) {
+	assert.Contains(t, jobsSkipRunOnStatus, http.StatusUnavailableForLegalReasons)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.markAttempted(444)
+	state.markAttempted(555)
+	state.skipRun(&SimpleGithubRun{ID: 444}, &http.Response{
+		StatusCode: http.StatusUnavailableForLegalReasons,
+		Body:       io.NopCloser(strings.NewReader(`{"message": "Repository access blocked"}`)),
+		Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/444/jobs"}},
+	})
+	// the collection goes on with the next run
+	assert.Nil(t, state.afterResponse(&http.Response{
+		StatusCode: http.StatusOK,
+		Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/555/jobs"}},
+	}))
+
+	assert.Equal(t, []int64{444}, state.failedRuns)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, state.failedRunsStatus[444])
+	assert.Equal(t, "451 Unavailable For Legal Reasons - Run blocked by GitHub", state.failedRunsErrors[444])
+	assert.True(t, state.processedRuns[444])
+	assert.True(t, state.processedRuns[555])
+}
+
+func TestJobCollectionStateResponseWithoutRequest(t *testing.T) {
+	logger := new(mocklog.Logger)
+	var warnings []string
+	logger.On("Warn", nil, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		warnings = append(warnings, fmt.Sprintf(args.String(1), args.Get(2).([]interface{})...))
+	})
+	state := newJobCollectionState(logger)
+	state.jobsPath = "repos/a/b/actions/runs/%d/jobs"
+	state.sleep = func(d time.Duration) {}
+
+	// the run is known from the input of the skipped run
+	assert.NotPanics(t, func() {
+		state.skipRun(&SimpleGithubRun{ID: 444}, &http.Response{StatusCode: http.StatusNotFound})
+	})
+	assert.Equal(t, []int64{444}, state.failedRuns)
+	assert.Contains(t, warnings[0], "repos/a/b/actions/runs/444/jobs")
+
+	// but not from the response alone
+	assert.NotPanics(t, func() {
+		assert.Nil(t, state.afterResponse(&http.Response{
+			StatusCode: http.StatusBadGateway,
+			Header:     http.Header{"Etag": []string{`"abc"`}},
+			Body:       io.NopCloser(strings.NewReader("bad gateway")),
+		}))
+		assert.Nil(t, state.afterResponse(&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"1"}},
+		}))
+		assert.False(t, state.recordEtag(&http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": []string{`"abc"`}}}))
+	})
+	assert.Equal(t, []int64{444, 0}, state.failedRuns)
+}
+
+func TestJobCollectionStateSlowestRuns(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	state.now = func() time.Time {
+		return now
+	}
+	response := func(runId int64) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Request:    &http.Request{URL: &url.URL{Path: fmt.Sprintf("/repos/a/b/actions/runs/%d/jobs", runId)}},
+		}
+	}
+
+	// not tracked unless enabled
+	state.markAttempted(1)
+	assert.Nil(t, state.afterResponse(response(1)))
+	assert.Empty(t, state.slowestRuns(10))
+
+	state.trackTimings()
+	for _, runId := range []int64{1, 2, 3} {
+		state.markAttempted(runId)
+	}
+	now = now.Add(time.Second)
+	assert.Nil(t, state.afterResponse(response(1)))
+	now = now.Add(time.Second)
+	// the second page of run 2
+	state.markAttempted(2)
+	assert.Nil(t, state.afterResponse(response(2)))
+	now = now.Add(time.Second)
+	assert.Nil(t, state.afterResponse(response(2)))
+	state.skipRun(&SimpleGithubRun{ID: 3}, &http.Response{StatusCode: http.StatusNotFound, Request: response(3).Request})
+
+	slowest := state.slowestRuns(2)
+	if assert.Len(t, slowest, 2) {
+		assert.Equal(t, int64(2), slowest[0].RunId)
+		assert.Equal(t, 3*time.Second, slowest[0].Duration())
+		assert.Equal(t, 2, slowest[0].Pages)
+		assert.Equal(t, int64(3), slowest[1].RunId)
+		assert.Equal(t, 1, slowest[1].Pages)
+	}
+}
+
+func TestForEachJobsScope(t *testing.T) {
+	data := &GithubTaskData{
+		Options: &GithubOptions{ConnectionId: 1, GithubId: 10, Name: "a/b"},
+		JobsScopes: []*GithubTaskData{
+			{Options: &GithubOptions{ConnectionId: 2, GithubId: 20, Name: "c/d"}},
+			{Options: &GithubOptions{ConnectionId: 3, GithubId: 30, Name: "e/f"}},
+		},
+	}
+	taskCtx := unithelper.DummySubTaskContext(nil)
+	taskCtx.On("GetData").Return(data)
+
+	var connectionIds []uint64
+	err := forEachJobsScope(taskCtx, func(_ plugin.SubTaskContext, scopeData *GithubTaskData) errors.Error {
+		connectionIds = append(connectionIds, scopeData.Options.ConnectionId)
+		if scopeData.Options.ConnectionId == 2 {
+			return errors.Default.New("boom")
+		}
+		return nil
+	})
+	assert.NotNil(t, err)
+	// the scopes after the failing one are not handled
+	assert.Equal(t, []uint64{1, 2}, connectionIds)
+}
+
+func TestJobCollectionResultMerge(t *testing.T) {
+	var result *JobCollectionResult
+	result = result.merge(&JobCollectionResult{TotalRuns: 2, FailedRuns: []int64{1}, Errors: map[int64]string{1: "boom"}, Requests: 4})
+	result = result.merge(nil)
+	result = result.merge(&JobCollectionResult{TotalRuns: 3, FailedRuns: []int64{7}, Errors: map[int64]string{7: "gone"}, Requests: 6})
+	assert.Equal(t, &JobCollectionResult{
+		TotalRuns:  5,
+		FailedRuns: []int64{1, 7},
+		Errors:     map[int64]string{1: "boom", 7: "gone"},
+		Requests:   10,
+	}, result)
+
+	// a run failed by both results is counted once
+	result = result.merge(&JobCollectionResult{FailedRuns: []int64{7, 9}, Errors: map[int64]string{7: "gone", 9: "boom"}})
+	assert.Equal(t, []int64{1, 7, 9}, result.FailedRuns)
+	assert.Len(t, result.Errors, 3)
+}
+
+func TestJobCollectionStateRunFailedOnTwoPages(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.recordFailure(5, http.StatusBadGateway, "Retry failure")
+	state.recordFailure(5, http.StatusInternalServerError, "500 Server Error")
+	result := state.result()
+	assert.Equal(t, []int64{5}, result.FailedRuns)
+	assert.Equal(t, map[int64]string{5: "500 Server Error"}, result.Errors)
+	assert.Equal(t, http.StatusInternalServerError, state.failedRunsStatus[5])
+}
+
+func TestValidateJobsScopes(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b", JobsScopes: []JobsScope{{ConnectionId: 2, GithubId: 20, Name: "c/d"}}}
+	assert.Nil(t, ValidateTaskOptions(op))
+	op.JobsScopes = append(op.JobsScopes, JobsScope{ConnectionId: 3, Name: "e/f"})
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestJobsRawDataParams(t *testing.T) {
+	params, err := jobsRawDataParams(&GithubOptions{ConnectionId: 1, Name: "a/b"})
+	assert.Nil(t, err)
+	assert.Equal(t, GithubApiParams{ConnectionId: 1, Name: "a/b"}, params)
+
+	_, err = jobsRawDataParams(&GithubOptions{Name: "a/b"})
+	assert.NotNil(t, err)
+	_, err = jobsRawDataParams(&GithubOptions{ConnectionId: 1})
+	assert.NotNil(t, err)
+	// params of another shape are rejected
+	assert.NotNil(t, validateRawDataParams(struct{ Name string }{Name: "a/b"}))
+}
+
+func TestJobsRawDataParamsDontCollideAcrossConnections(t *testing.T) {
+	newRawDataSubTask := func(connectionId uint64) *api.RawDataSubTask {
+		params, err := jobsRawDataParams(&GithubOptions{ConnectionId: connectionId, Name: "a/b"})
+		assert.Nil(t, err)
+		rawDataSubTask, err := api.NewRawDataSubTask(api.RawDataSubTaskArgs{
+			Ctx:    unithelper.DummySubTaskContext(nil),
+			Params: params,
+			Table:  RAW_JOB_TABLE,
+		})
+		assert.Nil(t, err)
+		return rawDataSubTask
+	}
+	cloud, enterprise := newRawDataSubTask(1), newRawDataSubTask(2)
+
+	// the raw jobs of both connections share the table, the params keep the collection of one from deleting the
+	// raw jobs of the other
+	assert.Equal(t, cloud.GetTable(), enterprise.GetTable())
+	assert.NotEqual(t, cloud.GetParams(), enterprise.GetParams())
+	assert.Contains(t, cloud.GetParams(), `"ConnectionId":1`)
+	assert.Contains(t, enterprise.GetParams(), `"ConnectionId":2`)
+}
+
+func TestRunIdFromJobsUrl(t *testing.T) {
+	assert.Equal(t, int64(42), runIdFromJobsUrl(&url.URL{Path: "/repos/a/b/actions/runs/42/jobs"}))
+	assert.Equal(t, int64(42), runIdFromJobsUrl(&url.URL{Path: "/api/v3/repos/a/runs/actions/runs/42/jobs"}))
+	assert.Equal(t, int64(0), runIdFromJobsUrl(&url.URL{Path: "/repos/a/b/actions/runs"}))
+}
+
+func TestJobsRateLimitWait(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	maxWait := 5 * time.Minute
+	response := func(statusCode int, header http.Header) *http.Response {
+		return &http.Response{StatusCode: statusCode, Header: header}
+	}
+
+	assert.Equal(t, 30*time.Second, jobsRateLimitWait(response(http.StatusTooManyRequests, http.Header{
+		"Retry-After": []string{"30"},
+	}), 1, now, maxWait))
+	assert.Equal(t, 90*time.Second, jobsRateLimitWait(response(http.StatusForbidden, http.Header{
+		"Retry-After": []string{now.Add(90 * time.Second).Format(http.TimeFormat)},
+	}), 1, now, maxWait))
+	assert.Equal(t, 45*time.Second, jobsRateLimitWait(response(http.StatusForbidden, http.Header{
+		"X-Ratelimit-Remaining": []string{"0"},
+		"X-Ratelimit-Reset":     []string{fmt.Sprintf("%d", now.Add(45*time.Second).Unix())},
+	}), 1, now, maxWait))
+	// capped by the max wait and never negative
+	assert.Equal(t, maxWait, jobsRateLimitWait(response(http.StatusTooManyRequests, http.Header{
+		"Retry-After": []string{"3600"},
+	}), 1, now, maxWait))
+	assert.Equal(t, time.Duration(0), jobsRateLimitWait(response(http.StatusForbidden, http.Header{
+		"X-Ratelimit-Reset": []string{fmt.Sprintf("%d", now.Add(-time.Minute).Unix())},
+	}), 1, now, maxWait))
+	// exponential backoff without headers
+	assert.Equal(t, time.Second, jobsRateLimitWait(response(http.StatusTooManyRequests, http.Header{}), 1, now, maxWait))
+	assert.Equal(t, 4*time.Second, jobsRateLimitWait(response(http.StatusTooManyRequests, http.Header{}), 3, now, maxWait))
+	assert.Equal(t, maxWait, jobsRateLimitWait(response(http.StatusTooManyRequests, http.Header{}), 100, now, maxWait))
+}
+
+func TestJobCollectionStateWaitsForRateLimit(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	var slept []time.Duration
+	state.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+	request := &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/42/jobs"}}
+
+	assert.Nil(t, state.afterResponse(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"30"}},
+		Request:    request,
+	}))
+	// a 403 without rate limit headers is not waited for
+	assert.Nil(t, state.afterResponse(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+		Request:    request,
+	}))
+
+	assert.Equal(t, []time.Duration{30 * time.Second}, slept)
+	// rate limited responses are retried, so they are not failures
+	assert.Empty(t, state.failedRuns)
+}
+
+func TestJobCollectionStateResult(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	for _, runId := range []int64{1, 2, 3} {
+		state.markAttempted(runId)
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Request:    &http.Request{URL: &url.URL{Path: fmt.Sprintf("/repos/a/b/actions/runs/%d/jobs", runId)}},
+		}
+		if runId == 2 {
+			res.StatusCode = http.StatusNotFound
+			state.skipRun(&SimpleGithubRun{ID: runId}, res)
+		} else {
+			assert.Nil(t, state.afterResponse(res))
+		}
+	}
+
+	result := state.result()
+	assert.Equal(t, &JobCollectionResult{
+		TotalRuns:  3,
+		FailedRuns: []int64{2},
+		Errors:     map[int64]string{2: "404 Not Found - Run likely deleted"},
+	}, result)
+
+	// the result doesn't change along with the state
+	state.recordFailure(3, http.StatusBadGateway, "Retry failure")
+	assert.Equal(t, []int64{2}, result.FailedRuns)
+	assert.Len(t, result.Errors, 1)
+}
+
+func TestJobCollectionStateMissingJobsError(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	assert.Nil(t, state.missingJobsError())
+
+	// the deleted, moved and blocked runs have no jobs to miss
+	state.recordFailure(1, http.StatusNotFound, "404 Not Found - Run likely deleted")
+	state.recordFailure(2, http.StatusUnprocessableEntity, "422 Unprocessable Entity - Run likely moved or repo renamed")
+	state.recordFailure(3, http.StatusUnavailableForLegalReasons, "451 Unavailable For Legal Reasons - Run blocked by GitHub")
+	assert.Nil(t, state.missingJobsError())
+
+	state.recordFailure(4, http.StatusBadGateway, "502 Server Error: bad gateway")
+	state.recordFailure(5, 0, "Retry failure: timeout")
+	err := state.missingJobsError()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "the jobs of 2 runs could not be collected, the first one is run 4: 502 Server Error: bad gateway")
+	}
+}
+
+func TestJobCollectionStateSummary(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	op := &GithubOptions{ConnectionId: 1, GithubId: 100, Name: "a/b"}
+	summary, err := json.Marshal(state.summary(op))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"connection_id": 1, "repo_id": 100, "repo": "a/b", "total_runs": 0, "failed_runs": []}`, string(summary))
+
+	for _, runId := range []int64{3, 1, 2} {
+		state.markAttempted(runId)
+		state.mu.Lock()
+		state.markProcessedLocked(runId)
+		state.mu.Unlock()
+	}
+	state.recordFailure(3, http.StatusBadGateway, "502 Server Error: bad gateway")
+	state.recordFailure(1, 0, "Retry failure: timeout")
+	summary, err = json.Marshal(state.summary(op))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"connection_id": 1, "repo_id": 100, "repo": "a/b", "total_runs": 3,
+		"failed_runs": [
+			{"run_id": 3, "http_status": 502, "error": "502 Server Error: bad gateway"},
+			{"run_id": 1, "http_status": 0, "error": "Retry failure: timeout"}
+		]
+	}`, string(summary))
+	// a single line
+	assert.NotContains(t, string(summary), "\n")
+}
+
+func TestTruncateErrorBody(t *testing.T) {
+	body, truncated := truncateErrorBody("boom", 300)
+	assert.Equal(t, "boom", body)
+	assert.False(t, truncated)
+
+	body, truncated = truncateErrorBody(strings.Repeat("x", 400), 300)
+	assert.Equal(t, strings.Repeat("x", 300)+"... (truncated, 400 bytes in total)", body)
+	assert.True(t, truncated)
+
+	// a multi-byte character split by the cut is dropped
+	body, truncated = truncateErrorBody("abécd", 3)
+	assert.Equal(t, "ab... (truncated, 6 bytes in total)", body)
+	assert.True(t, truncated)
+}
+
+type fakeIncrementalStateManager struct {
+	incremental bool
+	since       *time.Time
+}
+
+func (m *fakeIncrementalStateManager) IsIncremental() bool  { return m.incremental }
+func (m *fakeIncrementalStateManager) GetSince() *time.Time { return m.since }
+func (m *fakeIncrementalStateManager) ResetToFullSync()     { m.incremental, m.since = false, nil }
+
+func TestWithIncrementalOverlap(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, since.Add(-10*time.Minute), *withIncrementalOverlap(&since, op))
+	assert.Nil(t, withIncrementalOverlap(nil, op))
+
+	disabled := 0
+	op.IncrementalOverlapSeconds = &disabled
+	assert.Equal(t, &since, withIncrementalOverlap(&since, op))
+
+	negative := -1
+	op.IncrementalOverlapSeconds = &negative
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestCapJobsRuns(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	assert.Nil(t, ValidateTaskOptions(op))
+	runs, capped := capJobsRuns(op, 500)
+	assert.Equal(t, int64(500), runs)
+	assert.False(t, capped)
+
+	op.MaxRunsPerRun = 200
+	runs, capped = capJobsRuns(op, 500)
+	assert.Equal(t, int64(200), runs)
+	assert.True(t, capped)
+	runs, capped = capJobsRuns(op, 200)
+	assert.Equal(t, int64(200), runs)
+	assert.False(t, capped)
+
+	op.MaxRunsPerRun = -1
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestValidateJobsRunIds(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, GithubId: 2, Name: "a/b", RunIDs: []int64{7, 8, 9}}
+	mockDal := new(mockdal.Dal)
+	mockDal.On("Pluck", "id", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*args.Get(1).(*[]int64) = []int64{7, 9}
+	}).Once()
+	err := validateJobsRunIds(mockDal, op)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "[8]")
+
+	mockDal.On("Pluck", "id", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*args.Get(1).(*[]int64) = []int64{7, 8, 9}
+	}).Once()
+	assert.Nil(t, validateJobsRunIds(mockDal, op))
+	mockDal.AssertExpectations(t)
+}
+
+func TestJobsPageQuery(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	query := jobsPageQuery(op, &api.Pager{Page: 2, Size: 100})
+	assert.Equal(t, "2", query.Get("page"))
+	assert.Equal(t, "100", query.Get("per_page"))
+	// the api returns the jobs of the latest attempt by default
+	assert.False(t, query.Has("filter"))
+
+	op.CollectAllAttempts = true
+	query = jobsPageQuery(op, &api.Pager{Page: 1, Size: 100})
+	assert.Equal(t, "all", query.Get("filter"))
+}
+
+func TestJobsRunsOrder(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	assert.Equal(t, "id", jobsRunsOrderBy(op))
+	op.RunsOrder = JobsRunsOldestFirst
+	assert.Equal(t, "id", jobsRunsOrderBy(op))
+	op.RunsOrder = JobsRunsNewestFirst
+	assert.Equal(t, "id DESC", jobsRunsOrderBy(op))
+	assert.Nil(t, ValidateTaskOptions(op))
+
+	// the windows are collected oldest first
+	op.JobsWindowDays = 7
+	assert.NotNil(t, ValidateTaskOptions(op))
+	op.JobsWindowDays = 0
+	op.RunsOrder = "random"
+	assert.NotNil(t, ValidateTaskOptions(op))
+
+	// the newest runs are collected first unless the jobs are collected by windows
+	op.RunsOrder = ""
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, JobsRunsNewestFirst, op.RunsOrder)
+	op.RunsOrder = ""
+	op.JobsWindowDays = 7
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, JobsRunsOldestFirst, op.RunsOrder)
+	op.JobsWindowDays = 0
+
+	// the capped collections carry on with the oldest runs left, so none of them starves
+	op.RunsOrder = ""
+	op.MaxRunsPerRun = 50
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, JobsRunsOldestFirst, op.RunsOrder)
+	op.RunsOrder = JobsRunsNewestFirst
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestLoadJobCollectionCheckpointOfOtherOrder(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, GithubId: 2, RunsOrder: JobsRunsNewestFirst}
+	load := func(saved models.GithubJobCollectionCheckpoint) *models.GithubJobCollectionCheckpoint {
+		mockDal := new(mockdal.Dal)
+		mockDal.On("First", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(0).(*models.GithubJobCollectionCheckpoint) = saved
+		}).Return(nil)
+		checkpoint, err := loadJobCollectionCheckpoint(mockDal, op)
+		assert.Nil(t, err)
+		return checkpoint
+	}
+
+	checkpoint := load(models.GithubJobCollectionCheckpoint{LastRunId: 100, RunsOrder: JobsRunsNewestFirst})
+	assert.Equal(t, int64(100), checkpoint.LastRunId)
+
+	// saved before the order was recorded, the runs above it are left
+	checkpoint = load(models.GithubJobCollectionCheckpoint{LastRunId: 100})
+	assert.Equal(t, int64(0), checkpoint.LastRunId)
+	assert.Equal(t, JobsRunsNewestFirst, checkpoint.RunsOrder)
+}
+
+func TestSaveCappedJobCollectionCheckpoint(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	checkpoint := &models.GithubJobCollectionCheckpoint{ConnectionId: 1, RepoId: 2, LastRunId: 100, StartedAt: since}
+	mockDal := new(mockdal.Dal)
+	mockDal.On("CreateOrUpdate", checkpoint, mock.Anything).Return(nil).Twice()
+
+	assert.Nil(t, saveCappedJobCollectionCheckpoint(mockDal, op, checkpoint, &since, 300))
+	assert.True(t, checkpoint.Capped)
+	assert.Equal(t, &since, checkpoint.Since)
+	assert.Equal(t, int64(300), checkpoint.LastRunId)
+
+	// saved even though no run was collected, so the runs left are not skipped by the next collection
+	assert.Nil(t, saveCappedJobCollectionCheckpoint(mockDal, op, checkpoint, nil, 0))
+	assert.Nil(t, checkpoint.Since)
+	assert.Equal(t, int64(300), checkpoint.LastRunId)
+	mockDal.AssertExpectations(t)
+}
+
+func TestSaveJobCollectionCheckpointNewestFirst(t *testing.T) {
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b", RunsOrder: JobsRunsNewestFirst}
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	checkpoint := &models.GithubJobCollectionCheckpoint{ConnectionId: 1, RepoId: 2, StartedAt: since}
+	mockDal := new(mockdal.Dal)
+	mockDal.On("CreateOrUpdate", checkpoint, mock.Anything).Return(nil).Times(3)
+
+	// the runs are collected from the highest id down, so the checkpoint moves towards the lower ids
+	saveJobCollectionCheckpoint(mockDal, unithelper.DummyLogger(), op, checkpoint, 300)
+	assert.Equal(t, int64(300), checkpoint.LastRunId)
+	saveJobCollectionCheckpoint(mockDal, unithelper.DummyLogger(), op, checkpoint, 200)
+	assert.Equal(t, int64(200), checkpoint.LastRunId)
+	saveJobCollectionCheckpoint(mockDal, unithelper.DummyLogger(), op, checkpoint, 250)
+	assert.Equal(t, int64(200), checkpoint.LastRunId)
+
+	assert.Nil(t, saveCappedJobCollectionCheckpoint(mockDal, op, checkpoint, &since, 100))
+	assert.Equal(t, int64(100), checkpoint.LastRunId)
+	mockDal.AssertExpectations(t)
+
+	// the resume picks the runs below the checkpoint
+	clauses := buildJobsRunClauses(op, nil, checkpoint, "")
+	assert.Equal(t, "(id < ? OR github_updated_at > ?)", clauses[3].Data.(dal.DalClause).Expr)
+	assert.Equal(t, []interface{}{int64(100), since}, clauses[3].Data.(dal.DalClause).Params)
+}
+
+func TestResetJobsIncrementalState(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	logger := unithelper.DummyLogger()
+
+	// a since in the past is trusted
+	stateManager := &fakeIncrementalStateManager{incremental: true, since: &past}
+	assert.False(t, resetJobsIncrementalState(stateManager, &GithubOptions{}, logger, now))
+	assert.True(t, stateManager.IsIncremental())
+
+	// a since in the future falls back to a full sync
+	stateManager = &fakeIncrementalStateManager{incremental: true, since: &future}
+	assert.True(t, resetJobsIncrementalState(stateManager, &GithubOptions{}, logger, now))
+	assert.False(t, stateManager.IsIncremental())
+	assert.Nil(t, stateManager.GetSince())
+
+	// forceFullSync discards a valid state too
+	stateManager = &fakeIncrementalStateManager{incremental: true, since: &past}
+	assert.True(t, resetJobsIncrementalState(stateManager, &GithubOptions{ForceFullSync: true}, logger, now))
+	assert.False(t, stateManager.IsIncremental())
+
+	// nothing to reset in a full sync
+	stateManager = &fakeIncrementalStateManager{}
+	assert.False(t, resetJobsIncrementalState(stateManager, &GithubOptions{ForceFullSync: true}, logger, now))
+}
+
+func TestEstimateJobsPages(t *testing.T) {
+	assert.Equal(t, 1, estimateJobsPages(0, 100))
+	assert.Equal(t, 1, estimateJobsPages(100, 100))
+	assert.Equal(t, 2, estimateJobsPages(101, 100))
+	assert.Equal(t, 3, estimateJobsPages(250, 100))
+	assert.Equal(t, 5, estimateJobsPages(5, 1))
+}
+
+// newJobsRequestsDal returns a dal with the jobs counted by run and the runs to collect, the cursor of the runs honors
+// their limit
+func newJobsRequestsDal(jobsOfRuns map[int64]int, runIds []int64) *mockdal.Dal {
+	mockDal := new(mockdal.Dal)
+	mockDal.On("All", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		jobCounts := args.Get(0).(*[]struct {
+			RunId int64
+			Count int
+		})
+		for runId, count := range jobsOfRuns {
+			*jobCounts = append(*jobCounts, struct {
+				RunId int64
+				Count int
+			}{RunId: runId, Count: count})
+		}
+	}).Return(nil)
+	mockDal.On("Cursor", mock.Anything).Return(func(clauses ...dal.Clause) dal.Rows {
+		limit := len(runIds)
+		for _, clause := range clauses {
+			if clause.Type == dal.LimitClause && clause.Data.(int) < limit {
+				limit = clause.Data.(int)
+			}
+		}
+		rows := new(mockdal.Rows)
+		rows.On("Next").Return(true).Times(limit)
+		rows.On("Next").Return(false)
+		rows.On("Close").Return(nil)
+		next := 0
+		mockDal.On("Fetch", rows, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*SimpleGithubRun).ID = runIds[next]
+			next++
+		}).Return(nil)
+		return rows
+	}, nil)
+	return mockDal
+}
+
+func TestCountJobsRequests(t *testing.T) {
+	jobsOfRuns := map[int64]int{1: 250, 2: 30}
+	runIds := []int64{1, 2, 3}
+
+	// the page size wasn't defaulted yet
+	runs, requests, err := countJobsRequests(newJobsRequestsDal(jobsOfRuns, runIds), &GithubOptions{}, nil, 2, false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), runs)
+	// 3 pages of 100 jobs for run 1, 1 for run 2 and 3, 1 for each failure to retry
+	assert.Equal(t, int64(7), requests)
+
+	runs, requests, err = countJobsRequests(newJobsRequestsDal(jobsOfRuns, runIds), &GithubOptions{JobsPageSize: 50}, nil, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), runs)
+	assert.Equal(t, int64(7), requests)
+
+	// only the first MaxRunsPerRun runs are collected
+	op := &GithubOptions{MaxRunsPerRun: 1}
+	runs, requests, err = countJobsRequests(newJobsRequestsDal(jobsOfRuns, runIds), op, nil, 0, true)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), runs)
+	assert.Equal(t, int64(3), requests)
+}
+
+func TestEmptyRunsNotice(t *testing.T) {
+	notice := emptyRunsNotice("a/b", 0)
+	assert.Contains(t, notice, "no workflow runs of a/b found in _tool_github_runs")
+	assert.Contains(t, notice, `"Collect Workflow Runs"`)
+	assert.Contains(t, notice, `"Extract Workflow Runs"`)
+	// the runs were filtered out, i.e. by the incremental collection
+	assert.Empty(t, emptyRunsNotice("a/b", 3))
+}
+
+func TestActionsApiPath(t *testing.T) {
+	template := "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}/jobs"
+	assert.Equal(t, template, actionsApiPath(&GithubTaskData{}, template))
+	assert.Equal(t, "gateway/"+template, actionsApiPath(&GithubTaskData{ActionsApiPathPrefix: "/gateway/"}, template))
+	assert.Equal(t, int64(42), runIdFromJobsUrl(&url.URL{Path: "/gateway/repos/a/b/actions/runs/42/jobs"}))
+}
+
+func TestGetJobsTotalPages(t *testing.T) {
+	args := &api.ApiCollectorArgs{PageSize: 100}
+	response := func(link string, body string) *http.Response {
+		header := http.Header{}
+		if link != "" {
+			header.Set("Link", link)
+		}
+		return &http.Response{Header: header, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	const jobsPath = "https://api.github.com/repositories/1/actions/runs/42/jobs?per_page=100"
+	body := `{"total_count": 250, "jobs": []}`
+
+	// (a) no Link header
+	res := response("", body)
+	pages, err := getJobsTotalPages(res, args)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pages)
+	// the body is still readable by the collector
+	restBody, _ := io.ReadAll(res.Body)
+	assert.Equal(t, body, string(restBody))
+	// GetTotalPagesFromResponse stops after the first page in this case
+	pages, err = GetTotalPagesFromResponse(response("", body), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pages)
+
+	// (b) a Link header with prev only
+	pages, err = getJobsTotalPages(response(fmt.Sprintf(`<%s&page=1>; rel="prev"`, jobsPath), body), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pages)
+
+	// (c) a normal paginated header wins over total_count
+	pages, err = getJobsTotalPages(response(fmt.Sprintf(`<%s&page=2>; rel="next", <%s&page=4>; rel="last"`, jobsPath, jobsPath), body), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, pages)
+	pages, err = GetTotalPagesFromResponse(response(fmt.Sprintf(`<%s&page=2>; rel="next", <%s&page=4>; rel="last"`, jobsPath, jobsPath), body), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, pages)
+
+	// a single page
+	pages, err = getJobsTotalPages(response("", `{"total_count": 7, "jobs": []}`), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pages)
+	// an unexpected body is not fatal
+	pages, err = getJobsTotalPages(response("", `oops`), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pages)
+	// a bare array has no total_count, only the Link header tells the pages
+	pages, err = getJobsTotalPages(response("", `[{"id": 1}]`), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pages)
+	pages, err = getJobsTotalPages(response(fmt.Sprintf(`<%s&page=2>; rel="next", <%s&page=2>; rel="last"`, jobsPath, jobsPath), `[{"id": 1}]`), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, pages)
+}
+
+func TestParseJobsResponse(t *testing.T) {
+	response := func(body string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/42/jobs"}},
+		}
+	}
+
+	jobs, err := parseJobsResponse(response(`{"total_count": 2, "jobs": [{"id": 1}, {"id": 2}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"id": 1}`), json.RawMessage(`{"id": 2}`)}, jobs)
+
+	jobs, err = parseJobsResponse(response(` [{"id": 1}, {"id": 2}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"id": 1}`), json.RawMessage(`{"id": 2}`)}, jobs)
+
+	jobs, err = parseJobsResponse(response(`[]`))
+	assert.Nil(t, err)
+	assert.Empty(t, jobs)
+	jobs, err = parseJobsResponse(response(`{"total_count": 0, "jobs": []}`))
+	assert.Nil(t, err)
+	assert.Empty(t, jobs)
+
+	_, err = parseJobsResponse(response(`oops`))
+	assert.NotNil(t, err)
+}
+
+func TestJobCollectionStatePartialCollectionError(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.maxFailureRatio = 0.5
+	input := api.NewQueueIterator()
+	input.Push(&SimpleGithubRun{ID: 1})
+	iterator := &partialCollectionIterator{Iterator: input, state: state}
+
+	// too few runs were processed to tell
+	for runId := int64(1); runId < JOBS_FAILURE_RATIO_MIN_RUNS; runId++ {
+		state.recordServerFailure(runId, http.StatusInternalServerError, "500 Server Error")
+		state.mu.Lock()
+		state.markProcessedLocked(runId)
+		state.mu.Unlock()
+	}
+	assert.Nil(t, state.partialCollectionError())
+	assert.True(t, iterator.HasNext())
+
+	state.mu.Lock()
+	state.markProcessedLocked(JOBS_FAILURE_RATIO_MIN_RUNS)
+	state.mu.Unlock()
+	partialErr := state.partialCollectionError()
+	assert.Equal(t, &PartialCollectionError{Processed: 20, Failed: 19, MaxFailureRatio: 0.5}, partialErr)
+	assert.False(t, iterator.HasNext())
+
+	// disabled by default
+	state.maxFailureRatio = 0
+	assert.Nil(t, state.partialCollectionError())
+
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b", JobsMaxFailureRatio: 1.5}
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestJobCollectionStateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.ctx = ctx
+	input := api.NewQueueIterator()
+	input.Push(&SimpleGithubRun{ID: 1})
+	input.Push(&SimpleGithubRun{ID: 2})
+	iterator := &partialCollectionIterator{Iterator: input, state: state}
+
+	assert.True(t, iterator.HasNext())
+	run, err := iterator.Fetch()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), run.(*SimpleGithubRun).ID)
+	state.markAttempted(1)
+	state.markQueued(1)
+	assert.Nil(t, state.afterResponse(&http.Response{
+		StatusCode: http.StatusOK,
+		Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/1/jobs"}},
+	}))
+	assert.Nil(t, state.partialCollectionError())
+
+	// the pipeline is cancelled after the first run, the second one is not requested
+	cancel()
+	assert.False(t, iterator.HasNext())
+	partialErr := state.partialCollectionError()
+	assert.Equal(t, &PartialCollectionError{Processed: 1, Cancelled: true}, partialErr)
+	assert.Equal(t, "the collection of jobs was cancelled after 1 runs, 0 of them failed", partialErr.Error())
+	// the next collection carries on after the first run
+	assert.Equal(t, int64(1), state.checkpoint())
+	assert.Equal(t, 1, state.result().TotalRuns)
+}
+
+func TestJobCollectionStateWarnFailure(t *testing.T) {
+	logger := new(mocklog.Logger)
+	warnings := 0
+	logger.On("Warn", nil, mock.Anything, mock.Anything).Run(func(args mock.Arguments) { warnings++ })
+	state := newJobCollectionState(logger)
+	state.failureLogLimit = 3
+
+	state.mu.Lock()
+	for runId := int64(1); runId <= 3; runId++ {
+		state.warnFailureLocked(runId, "run %d failed", runId)
+	}
+	state.mu.Unlock()
+	// 3 failures and the notice that the next ones are rolled up
+	assert.Equal(t, 4, warnings)
+
+	state.mu.Lock()
+	for runId := int64(4); runId <= 8; runId++ {
+		state.warnFailureLocked(runId, "run %d failed", runId)
+	}
+	state.mu.Unlock()
+	// a single rollup of runs 4 to 6, runs 7 and 8 wait for the next one
+	assert.Equal(t, 5, warnings)
+	logger.AssertCalled(t, "Warn", nil, "%d more runs failed, %d in total: %v", []interface{}{3, 6, []int64{4, 5, 6}})
+	assert.Equal(t, []int64{7, 8}, state.unloggedFailures)
+
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, DEFAULT_JOBS_FAILURE_LOG_LIMIT, op.JobsFailureLogLimit)
+	op.JobsFailureLogLimit = -1
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestJobCollectionStateMaxPagesPerRun(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.maxPagesPerRun = 50
+	args := &api.ApiCollectorArgs{PageSize: 100}
+	// GitHub reports an endless pagination for run 42
+	endless := func(runId int64) *http.Response {
+		header := http.Header{}
+		jobsPath := fmt.Sprintf("https://api.github.com/repositories/1/actions/runs/%d/jobs?per_page=100", runId)
+		header.Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next", <%s&page=1000000>; rel="last"`, jobsPath, jobsPath))
+		return &http.Response{
+			Header:  header,
+			Body:    io.NopCloser(strings.NewReader(`{"total_count": 100000000, "jobs": []}`)),
+			Request: &http.Request{URL: &url.URL{Path: fmt.Sprintf("/repos/a/b/actions/runs/%d/jobs", runId)}},
+		}
+	}
+
+	pages, err := state.totalPages(endless(42), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, pages)
+	assert.Equal(t, []int64{42}, state.failedRuns)
+	assert.Contains(t, state.failedRunsErrors[42], "page cap exceeded")
+
+	// the runs within the cap are not failed
+	pages, err = state.totalPages(&http.Response{
+		Header:  http.Header{},
+		Body:    io.NopCloser(strings.NewReader(`{"total_count": 250, "jobs": []}`)),
+		Request: &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/43/jobs"}},
+	}, args)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []int64{42}, state.failedRuns)
+
+	// -1 doesn't cap them
+	state = newJobCollectionState(unithelper.DummyLogger())
+	state.maxPagesPerRun = -1
+	pages, err = state.totalPages(endless(42), args)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000000, pages)
+	assert.Empty(t, state.failedRuns)
+
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b"}
+	assert.Nil(t, ValidateTaskOptions(op))
+	assert.Equal(t, DEFAULT_JOBS_MAX_PAGES_PER_RUN, op.JobsMaxPagesPerRun)
+	op.JobsMaxPagesPerRun = -2
+	assert.NotNil(t, ValidateTaskOptions(op))
+}
+
+func TestCollectJobsKeepsPagesBeforeFailedPage(t *testing.T) {
+	var rawJobs []string
+	mockDal := new(mockdal.Dal)
+	mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil)
+	mockDal.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockDal.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, row := range args.Get(0).([]*api.RawData) {
+			rawJobs = append(rawJobs, string(row.Data))
+		}
+	}).Return(nil)
+
+	mockInput := new(mockapi.Iterator)
+	mockInput.On("HasNext").Return(true).Once()
+	mockInput.On("HasNext").Return(false)
+	mockInput.On("Fetch").Return(&SimpleGithubRun{ID: 42}, nil).Once()
+	mockInput.On("Close").Return(nil)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.jobsPath = "repos/a/b/actions/runs/%d/jobs"
+	// the 4th page of run 42 still fails after the retries, with the html error page of the server
+	mockApi := new(mockapi.RateLimitedApiClient)
+	mockApi.On("DoGetAsync", "repos/a/b/actions/runs/42/jobs", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		page := args.Get(1).(url.Values).Get("page")
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"total_count": 4, "jobs": [{"id": %s}]}`, page))),
+			Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/42/jobs", RawQuery: "page=" + page}},
+		}
+		if page == "4" {
+			res.StatusCode = http.StatusInternalServerError
+			res.Header.Set("ETag", `"unicorn"`)
+			res.Body = io.NopCloser(strings.NewReader("<html>Unicorn!</html>"))
+			assert.Nil(t, state.afterResponse(res))
+			res.Body = io.NopCloser(strings.NewReader("<html>Unicorn!</html>"))
+		}
+		handler := args.Get(3).(plugin.ApiAsyncCallback)
+		assert.Nil(t, handler(res))
+	}).Times(4)
+	mockApi.On("NextTick", mock.Anything).Run(func(args mock.Arguments) {
+		assert.Nil(t, args.Get(0).(func() errors.Error)())
+	})
+	mockApi.On("HasError").Return(false)
+	mockApi.On("WaitAsync").Return(nil)
+	mockApi.On("SetAfterFunction", mock.Anything).Return()
+
+	collector, err := api.NewApiCollector(api.ApiCollectorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx:    unithelper.DummySubTaskContext(mockDal),
+			Params: GithubApiParams{ConnectionId: 1, Name: "a/b"},
+			Table:  RAW_JOB_TABLE,
+		},
+		ApiClient:   mockApi,
+		Input:       mockInput,
+		PageSize:    1,
+		UrlTemplate: "repos/a/b/actions/runs/{{ .Input.ID }}/jobs",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			return query, nil
+		},
+		GetTotalPages:  state.totalPages,
+		ResponseParser: state.parseResponse,
+		AfterResponse:  state.afterResponse,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, collector.Execute())
+
+	// the jobs of the 3 good pages are kept for the extraction, and the run is recorded as failed to flag it partial
+	assert.ElementsMatch(t, []string{`{"id": 1}`, `{"id": 2}`, `{"id": 3}`}, rawJobs)
+	assert.Equal(t, []int64{42}, state.failedRuns)
+	assert.Contains(t, state.failedRunsErrors[42], "500 Server Error: <html>Unicorn!</html>")
+	// the failed page is requested unconditionally next time
+	assert.Empty(t, state.newEtags)
+	mockApi.AssertExpectations(t)
+}
+
+func TestCollectJobsSkipsDeletedRuns(t *testing.T) {
+	client := newFakeApiClient()
+	client.respond("repos/a/b/actions/runs/41/jobs", 1, http.StatusNotFound, `{"message": "Not Found"}`)
+	client.respond("repos/a/b/actions/runs/42/jobs", 1, http.StatusOK, `{"total_count": 1, "jobs": [{"id": 420}]}`)
+	client.respond("repos/a/b/actions/runs/43/jobs", 1, http.StatusUnprocessableEntity, `{"message": "Unprocessable"}`)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	collector, rawJobs := newFakeJobsCollector(t, client, state, 41, 42, 43)
+	assert.Nil(t, collector.Execute())
+
+	// the deleted and moved runs are skipped, the collection carries on with the others
+	assert.Equal(t, []string{`{"id": 420}`}, *rawJobs)
+	assert.ElementsMatch(t, []int64{41, 43}, state.failedRuns)
+	assert.Equal(t, http.StatusNotFound, state.failedRunsStatus[41])
+	assert.Equal(t, "404 Not Found - Run likely deleted", state.failedRunsErrors[41])
+	assert.Equal(t, http.StatusUnprocessableEntity, state.failedRunsStatus[43])
+	assert.Equal(t, 3, state.totalRuns)
+	assert.Len(t, state.processedRuns, 3)
+	assert.Nil(t, state.missingJobsError())
+	// the framework tells the same runs apart
+	summary := collector.Summary()
+	assert.Equal(t, int64(3), summary.Requests)
+	assert.Equal(t, int64(3), summary.Inputs)
+	assert.ElementsMatch(t, []api.ApiCollectorInputFailure{
+		{ID: 41, Input: &SimpleGithubRun{ID: 41}, StatusCode: http.StatusNotFound},
+		{ID: 43, Input: &SimpleGithubRun{ID: 43}, StatusCode: http.StatusUnprocessableEntity},
+	}, summary.FailedInputs)
+}
+
+func TestCollectJobsSkippedRunsDontStopCollection(t *testing.T) {
+	client := newFakeApiClient()
+	runIds := make([]int64, 0, JOBS_FAILURE_RATIO_MIN_RUNS+1)
+	for runId := int64(1); runId <= JOBS_FAILURE_RATIO_MIN_RUNS; runId++ {
+		client.respond(fmt.Sprintf("repos/a/b/actions/runs/%d/jobs", runId), 1, http.StatusNotFound, `{"message": "Not Found"}`)
+		runIds = append(runIds, runId)
+	}
+	client.respond("repos/a/b/actions/runs/100/jobs", 1, http.StatusOK, `{"total_count": 1, "jobs": [{"id": 1000}]}`)
+	runIds = append(runIds, 100)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	state.maxFailureRatio = 0.1
+	collector, rawJobs := newFakeJobsCollector(t, client, state, runIds...)
+	assert.Nil(t, collector.Execute())
+
+	// the deleted runs are expected, all the requests succeeded
+	assert.Nil(t, state.partialCollectionError())
+	assert.Equal(t, []string{`{"id": 1000}`}, *rawJobs)
+	assert.Len(t, state.failedRuns, JOBS_FAILURE_RATIO_MIN_RUNS)
+	assert.Len(t, state.processedRuns, JOBS_FAILURE_RATIO_MIN_RUNS+1)
+}
+
+func TestCollectJobsFailsRunOnServerError(t *testing.T) {
+	client := newFakeApiClient()
+	client.respond("repos/a/b/actions/runs/42/jobs", 1, http.StatusOK, `{"total_count": 3, "jobs": [{"id": 1}]}`)
+	client.respond("repos/a/b/actions/runs/42/jobs", 2, http.StatusOK, `{"total_count": 3, "jobs": [{"id": 2}]}`)
+	client.respond("repos/a/b/actions/runs/42/jobs", 3, http.StatusBadGateway, "bad gateway")
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	collector, rawJobs := newFakeJobsCollector(t, client, state, 42)
+	err := collector.Execute()
+
+	// the run is reported to CollectJobs, which treats it as a partial success
+	runErr := &api.CollectorRunError{}
+	if assert.True(t, errors.As(err, &runErr)) {
+		assert.Equal(t, int64(42), runErr.RunID)
+		assert.Equal(t, http.StatusBadGateway, runErr.StatusCode)
+	}
+	assert.ElementsMatch(t, []string{`{"id": 1}`, `{"id": 2}`}, *rawJobs)
+	assert.Equal(t, []int64{42}, state.failedRuns)
+	assert.Equal(t, "502 Server Error: bad gateway", state.failedRunsErrors[42])
+	assert.NotNil(t, state.missingJobsError())
+	assert.Equal(t, api.ApiCollectorSummary{
+		Requests:     3,
+		Inputs:       1,
+		FailedInputs: []api.ApiCollectorInputFailure{{ID: 42, Input: &SimpleGithubRun{ID: 42}, StatusCode: http.StatusBadGateway}},
+	}, collector.Summary())
+}
+
+func TestCollectJobsStopsOnMissingPermissions(t *testing.T) {
+	header := http.Header{}
+	header.Set(ACCEPTED_PERMISSIONS_HEADER, "actions=read")
+	client := newFakeApiClient()
+	client.respondWith("repos/a/b/actions/runs/41/jobs", 1, fakeApiResponse{
+		StatusCode: http.StatusForbidden,
+		Body:       `{"message": "Resource not accessible by personal access token"}`,
+		Header:     header,
+	})
+	client.respond("repos/a/b/actions/runs/42/jobs", 1, http.StatusOK, `{"total_count": 1, "jobs": [{"id": 420}]}`)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	collector, rawJobs := newFakeJobsCollector(t, client, state, 41, 42)
+	assert.Nil(t, collector.Execute())
+
+	// the request is not retried, and the next runs are not requested since they would be rejected the same way
+	assert.Equal(t, []string{"repos/a/b/actions/runs/41/jobs?page=1"}, client.requests)
+	assert.Empty(t, *rawJobs)
+	err := state.missingPermissionsError()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, errors.Forbidden, err.GetType())
+		assert.Contains(t, err.Error(), "the token is missing the permissions to read the jobs of run 41, GitHub accepts `actions=read`")
+	}
+	assert.Equal(t, http.StatusForbidden, state.failedRunsStatus[41])
+}
+
+func TestCollectJobsOfArchivedRepo(t *testing.T) {
+	client := newFakeApiClient()
+	client.respond("repos/a/b/actions/runs/41/jobs", 1, http.StatusForbidden,
+		`{"message": "Repository was archived so is read-only.", "documentation_url": "https://docs.github.com/rest"}`)
+	client.respond("repos/a/b/actions/runs/42/jobs", 1, http.StatusOK, `{"total_count": 1, "jobs": [{"id": 420}]}`)
+
+	state := newJobCollectionState(unithelper.DummyLogger())
+	collector, rawJobs := newFakeJobsCollector(t, client, state, 41, 42)
+	assert.Nil(t, collector.Execute())
+
+	// the run rejected for the archival is told apart from the auth failures, and the collection goes on
+	assert.Equal(t, []string{`{"id": 420}`}, *rawJobs)
+	assert.Equal(t, []int64{41}, state.failedRuns)
+	assert.Equal(t, http.StatusForbidden, state.failedRunsStatus[41])
+	assert.Equal(t, "403 Forbidden - Repository is archived: Repository was archived so is read-only.", state.failedRunsErrors[41])
+	assert.Nil(t, state.missingPermissionsError())
+}
+
+func TestJobCollectionStateMissingPermissionsOn404(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	res := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Request:    &http.Request{URL: &url.URL{Path: "/repos/a/b/actions/runs/41/jobs"}},
+	}
+	res.Header.Set(ACCEPTED_PERMISSIONS_HEADER, "actions=read; contents=read")
+	state.skipRun(&SimpleGithubRun{ID: 41}, res)
+
+	// the run isn't taken for a deleted one, so its jobs are not pruned
+	assert.Equal(t, http.StatusForbidden, state.failedRunsStatus[41])
+	err := state.missingPermissionsError()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "GitHub accepts `actions=read` or `contents=read`")
+	}
+}
+
+func TestJobCollectionStateEtags(t *testing.T) {
+	state := newJobCollectionState(unithelper.DummyLogger())
+	// a full sync requests the pages unconditionally
+	header, err := state.etagHeader(100, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, header)
+
+	// the ETags are loaded once per run, when its first page is requested
+	var loadedRuns []int64
+	state.loadEtags = func(runId int64) (map[int]string, errors.Error) {
+		loadedRuns = append(loadedRuns, runId)
+		if runId == 100 {
+			return map[int]string{1: `W/"abc"`}, nil
+		}
+		return map[int]string{}, nil
+	}
+	header, err = state.etagHeader(100, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, `W/"abc"`, header.Get("If-None-Match"))
+	header, err = state.etagHeader(100, 2)
+	assert.Nil(t, err)
+	assert.Nil(t, header)
+	header, err = state.etagHeader(200, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, header)
+	assert.Equal(t, []int64{100, 200}, loadedRuns)
+
+	newResponse := func(statusCode int, page string, etag string) *http.Response {
+		header := http.Header{}
+		if etag != "" {
+			header.Set("ETag", etag)
+		}
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+			Request: &http.Request{URL: &url.URL{
+				Path:     "/repos/a/b/actions/runs/100/jobs",
+				RawQuery: "page=" + page + "&per_page=100",
+			}},
+		}
+	}
+	assert.True(t, state.recordEtag(newResponse(http.StatusNotModified, "1", "")))
+	assert.False(t, state.recordEtag(newResponse(http.StatusOK, "2", `W/"def"`)))
+	assert.False(t, state.recordEtag(newResponse(http.StatusOK, "3", "")))
+	assert.Equal(t, 1, state.notModifiedPages)
+	assert.Equal(t, map[jobPageKey]string{{RunId: 100, Page: 2}: `W/"def"`}, state.newEtags)
+
+	mockDal := new(mockdal.Dal)
+	mockDal.On("CreateOrUpdate", []*models.GithubJobPageEtag{
+		{ConnectionId: 1, RepoId: 2, RunId: 100, Page: 2, Etag: `W/"def"`},
+	}, mock.Anything).Return(nil).Once()
+	assert.Nil(t, saveJobPageEtags(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2}, state.newEtags))
+	assert.Nil(t, saveJobPageEtags(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2}, nil))
+	mockDal.AssertExpectations(t)
+}
+
+func TestLoadJobPageEtags(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	mockDal.On("All", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		// only the ETags of the run are loaded
+		where := args.Get(1).([]dal.Clause)[0].Data.(dal.DalClause)
+		assert.Equal(t, "repo_id = ? AND connection_id = ? AND run_id = ?", where.Expr)
+		assert.Equal(t, []interface{}{2, uint64(1), int64(100)}, where.Params)
+		*args.Get(0).(*[]*models.GithubJobPageEtag) = []*models.GithubJobPageEtag{
+			{ConnectionId: 1, RepoId: 2, RunId: 100, Page: 1, Etag: `W/"abc"`},
+			{ConnectionId: 1, RepoId: 2, RunId: 100, Page: 2, Etag: `W/"def"`},
+		}
+	}).Return(nil).Once()
+	etags, err := loadJobPageEtags(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2}, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{1: `W/"abc"`, 2: `W/"def"`}, etags)
+	mockDal.AssertExpectations(t)
+}
+
+func TestPruneJobPageEtags(t *testing.T) {
+	deletedWhere := func(mockDal *mockdal.Dal) *[]string {
+		var exprs []string
+		mockDal.On("Delete", mock.AnythingOfType("*models.GithubJobPageEtag"), mock.Anything).Run(func(args mock.Arguments) {
+			exprs = append(exprs, args.Get(1).([]dal.Clause)[0].Data.(dal.DalClause).Expr)
+		}).Return(nil)
+		return &exprs
+	}
+
+	// the ETags of the runs which are gone are deleted
+	mockDal := new(mockdal.Dal)
+	exprs := deletedWhere(mockDal)
+	assert.Nil(t, pruneJobPageEtags(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2}))
+	assert.Equal(t, []string{jobPageEtagsOfGoneRuns}, *exprs)
+
+	// along with the ones of the runs created before jobsCreatedDateAfter
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockDal = new(mockdal.Dal)
+	exprs = deletedWhere(mockDal)
+	assert.Nil(t, pruneJobPageEtags(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2, JobsCreatedDateAfter: &createdAfter}))
+	assert.Equal(t, []string{jobPageEtagsOfGoneRuns, jobPageEtagsOfRunsBefore}, *exprs)
+}
+
+func TestJobsWindows(t *testing.T) {
+	date := func(day int, hour int) time.Time {
+		return time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	// all the runs fit in a window
+	assert.Equal(t, []jobsWindow{{}}, jobsWindows(date(1, 10), date(3, 12), 7))
+	// the windows start at the midnight before the first run
+	assert.Equal(t, []jobsWindow{
+		{End: ptr(date(8, 0))},
+		{Start: ptr(date(8, 0)), End: ptr(date(15, 0))},
+		{Start: ptr(date(15, 0))},
+	}, jobsWindows(date(1, 10), date(20, 12), 7))
+	// a run created at the end of a window is in the next one
+	assert.Equal(t, []jobsWindow{
+		{End: ptr(date(2, 0))},
+		{Start: ptr(date(2, 0))},
+	}, jobsWindows(date(1, 10), date(2, 0), 1))
+
+	assert.Equal(t, "1 = 1", jobsWindow{}.clause().Data.(dal.DalClause).Expr)
+	assert.Equal(t, "(github_created_at < ? OR github_created_at IS NULL)",
+		jobsWindow{End: ptr(date(8, 0))}.clause().Data.(dal.DalClause).Expr)
+	assert.Equal(t, "github_created_at >= ? AND github_created_at < ?",
+		jobsWindow{Start: ptr(date(8, 0)), End: ptr(date(15, 0))}.clause().Data.(dal.DalClause).Expr)
+	assert.Equal(t, "github_created_at >= ?", jobsWindow{Start: ptr(date(15, 0))}.clause().Data.(dal.DalClause).Expr)
+
+	op := &GithubOptions{ConnectionId: 1, Name: "a/b", JobsWindowDays: 7, MaxRunsPerRun: 100}
+	assert.NotNil(t, ValidateTaskOptions(op))
+	op.MaxRunsPerRun = 0
+	assert.Nil(t, ValidateTaskOptions(op))
+}
+
+func TestJobsWindowIterator(t *testing.T) {
+	opened := 0
+	iterator := &jobsWindowIterator{open: func() (api.Iterator, errors.Error) {
+		opened++
+		input := api.NewQueueIterator()
+		input.Push(&SimpleGithubRun{ID: 1})
+		return input, nil
+	}}
+	// the window is opened when its collector starts
+	assert.Equal(t, 0, opened)
+	assert.Nil(t, iterator.Close())
+	assert.True(t, iterator.HasNext())
+	run, err := iterator.Fetch()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), run.(*SimpleGithubRun).ID)
+	assert.False(t, iterator.HasNext())
+	assert.Equal(t, 1, opened)
+
+	// the collector fails with the error opening the window
+	iterator = &jobsWindowIterator{open: func() (api.Iterator, errors.Error) {
+		return nil, errors.Default.New("boom")
+	}}
+	assert.True(t, iterator.HasNext())
+	_, err = iterator.Fetch()
+	assert.Equal(t, "boom", err.Error())
+}
@@ -19,6 +19,7 @@ package tasks
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
@@ -49,13 +50,43 @@ var ConvertJobsMeta = plugin.SubTaskMeta{
 	ProductTables: []string{devops.CICDTask{}.TableName()},
 }
 
+// getJobResult maps the conclusion of a job, a step or a check run to the domain result.
+// Only the jobs which ran to their end are a success or a failure: the cancelled, skipped, neutral,
+// stale and action_required ones, i.e. the ones waiting for an approval or superseded, keep the
+// default result, so the dora plugin doesn't turn them into deployments and they don't weigh on the
+// change failure rate.
+func getJobResult(conclusion string) string {
+	switch strings.ToUpper(conclusion) {
+	case StatusSuccess:
+		return devops.RESULT_SUCCESS
+	case StatusFailure, StatusTimedOut, StatusStartUpFailure:
+		return devops.RESULT_FAILURE
+	default:
+		return devops.RESULT_DEFAULT
+	}
+}
+
+// jobStatusRule maps the statuses of jobs and steps to the domain statuses
+var jobStatusRule = &devops.StatusRule{
+	Done:       []string{StatusCompleted, StatusSuccess, StatusFailure, StatusCancelled, StatusTimedOut, StatusStartUpFailure},
+	InProgress: []string{StatusInProgress, StatusQueued, StatusWaiting, StatusPending},
+	Default:    devops.STATUS_OTHER,
+}
+
 type SimpleBranch struct {
 	HeadBranch string `json:"head_branch" gorm:"type:varchar(255)"`
 }
 
-func ConvertJobs(taskCtx plugin.SubTaskContext) (err errors.Error) {
+func ConvertJobs(taskCtx plugin.SubTaskContext) errors.Error {
+	return forEachJobsScope(taskCtx, convertJobs)
+}
+
+func convertJobs(taskCtx plugin.SubTaskContext, data *GithubTaskData) (err errors.Error) {
 	db := taskCtx.GetDal()
-	data := taskCtx.GetData().(*GithubTaskData)
+	rawParams, err := jobsRawDataParams(data.Options)
+	if err != nil {
+		return err
+	}
 	repoId := data.Options.GithubId
 	if err != nil {
 		return err
@@ -74,12 +105,9 @@ func ConvertJobs(taskCtx plugin.SubTaskContext) (err errors.Error) {
 	repoIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
 	converter, err := api.NewDataConverter(api.DataConverterArgs{
 		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
-			Ctx: taskCtx,
-			Params: GithubApiParams{
-				ConnectionId: data.Options.ConnectionId,
-				Name:         data.Options.Name,
-			},
-			Table: RAW_JOB_TABLE,
+			Ctx:    taskCtx,
+			Params: rawParams,
+			Table:  RAW_JOB_TABLE,
 		},
 		InputRowType: reflect.TypeOf(models.GithubJob{}),
 		Input:        cursor,
@@ -91,35 +119,30 @@ func ConvertJobs(taskCtx plugin.SubTaskContext) (err errors.Error) {
 				return nil, nil
 			}
 			createdAt := *line.StartedAt
+			if line.GithubCreatedAt != nil {
+				createdAt = *line.GithubCreatedAt
+			}
 			domainJob := &devops.CICDTask{
 				DomainEntity: domainlayer.DomainEntity{Id: jobIdGen.Generate(data.Options.ConnectionId, line.RunID,
 					line.ID)},
-				Name: line.Name,
+				Name: cicdTaskName(line.Name),
 				TaskDatesInfo: devops.TaskDatesInfo{
 					CreatedDate:  createdAt,
+					QueuedDate:   line.GithubCreatedAt,
 					StartedDate:  line.StartedAt,
 					FinishedDate: line.CompletedAt,
 				},
-				PipelineId:  runIdGen.Generate(data.Options.ConnectionId, line.RepoId, line.RunID),
-				CicdScopeId: repoIdGen.Generate(data.Options.ConnectionId, line.RepoId),
-				Type:        line.Type,
-				Environment: line.Environment,
-				Result: devops.GetResult(&devops.ResultRule{
-					Success: []string{StatusSuccess},
-					Failure: []string{StatusFailure, StatusCancelled, StatusTimedOut, StatusStartUpFailure},
-					Default: devops.RESULT_DEFAULT,
-				}, line.Conclusion),
+				PipelineId:     runIdGen.Generate(data.Options.ConnectionId, line.RepoId, line.RunID),
+				CicdScopeId:    repoIdGen.Generate(data.Options.ConnectionId, line.RepoId),
+				Type:           line.Type,
+				Environment:    line.Environment,
+				Result:         getJobResult(line.Conclusion),
 				OriginalResult: line.Conclusion,
-				Status: devops.GetStatus(&devops.StatusRule{
-					Done:       []string{StatusCompleted, StatusSuccess, StatusFailure, StatusCancelled, StatusTimedOut, StatusStartUpFailure},
-					InProgress: []string{StatusInProgress, StatusQueued, StatusWaiting, StatusPending},
-					Default:    devops.STATUS_OTHER,
-				}, line.Status),
+				Status:         devops.GetStatus(jobStatusRule, line.Status),
 				OriginalStatus: line.Status,
+				Url:            line.HTMLURL,
 			}
-			if line.CompletedAt != nil && line.StartedAt != nil {
-				domainJob.DurationSec = float64(line.CompletedAt.Sub(*line.StartedAt).Milliseconds() / 1e3)
-			}
+			setJobDurations(domainJob)
 			return []interface{}{
 				domainJob,
 			}, nil
@@ -131,3 +154,28 @@ func ConvertJobs(taskCtx plugin.SubTaskContext) (err errors.Error) {
 
 	return converter.Execute()
 }
+
+// MAX_CICD_TASK_NAME_LENGTH is the length of cicd_tasks.name, the names of the jobs, i.e. the ones of a large matrix,
+// can be longer
+const MAX_CICD_TASK_NAME_LENGTH = 255
+
+// cicdTaskName keeps the first MAX_CICD_TASK_NAME_LENGTH characters of the name of the job, the full name stays in
+// _tool_github_jobs
+func cicdTaskName(name string) string {
+	runes := []rune(name)
+	if len(runes) <= MAX_CICD_TASK_NAME_LENGTH {
+		return name
+	}
+	return string(runes[:MAX_CICD_TASK_NAME_LENGTH])
+}
+
+// setJobDurations splits the time of the job between its wait in the queue for a runner, from its creation until it
+// started, and its execution until it completed. The zero timestamps were normalized to nil by the extraction, a
+// duration is left unset when either of its timestamps is unknown, or when they are out of order, rather than being
+// negative or huge. DurationSec of the domain layer isn't nullable, so an unknown execution time is 0
+func setJobDurations(task *devops.CICDTask) {
+	task.QueuedDurationSec = task.CalculateQueueDuration()
+	if task.StartedDate != nil && task.FinishedDate != nil && !task.FinishedDate.Before(*task.StartedDate) {
+		task.DurationSec = float64(task.FinishedDate.Sub(*task.StartedDate).Milliseconds() / 1e3)
+	}
+}
@@ -19,8 +19,10 @@ package tasks
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
@@ -128,3 +130,87 @@ func TestConvertJobs_WithStartedAt(t *testing.T) {
 	   assert.NotNil(t, result)
 	   assert.Equal(t, "test-job", result[0].(*devops.CICDTask).Name)
 }
+
+func TestConvertJobs_QueuedDuration(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 9, 58, 0, 0, time.UTC)
+	startedAt := createdAt.Add(90 * time.Second)
+
+	queued := devops.TaskDatesInfo{QueuedDate: &createdAt, StartedDate: &startedAt}
+	assert.Equal(t, float64(90), *queued.CalculateQueueDuration())
+
+	// jobs extracted before created_at was collected have no queue duration
+	unknown := devops.TaskDatesInfo{StartedDate: &startedAt}
+	assert.Nil(t, unknown.CalculateQueueDuration())
+}
+
+func TestSetJobDurations(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 9, 58, 0, 0, time.UTC)
+	startedAt := createdAt.Add(90 * time.Second)
+	completedAt := startedAt.Add(5 * time.Minute)
+
+	// all the timestamps
+	task := &devops.CICDTask{TaskDatesInfo: devops.TaskDatesInfo{
+		QueuedDate: &createdAt, StartedDate: &startedAt, FinishedDate: &completedAt,
+	}}
+	setJobDurations(task)
+	assert.Equal(t, float64(90), *task.QueuedDurationSec)
+	assert.Equal(t, float64(300), task.DurationSec)
+
+	// none of them
+	task = &devops.CICDTask{}
+	setJobDurations(task)
+	assert.Nil(t, task.QueuedDurationSec)
+	assert.Equal(t, float64(0), task.DurationSec)
+
+	// a job still running, and a job extracted before created_at was collected
+	task = &devops.CICDTask{TaskDatesInfo: devops.TaskDatesInfo{QueuedDate: &createdAt, StartedDate: &startedAt}}
+	setJobDurations(task)
+	assert.Equal(t, float64(90), *task.QueuedDurationSec)
+	assert.Equal(t, float64(0), task.DurationSec)
+	task = &devops.CICDTask{TaskDatesInfo: devops.TaskDatesInfo{StartedDate: &startedAt, FinishedDate: &completedAt}}
+	setJobDurations(task)
+	assert.Nil(t, task.QueuedDurationSec)
+	assert.Equal(t, float64(300), task.DurationSec)
+
+	// the timestamps out of order don't give negative durations
+	task = &devops.CICDTask{TaskDatesInfo: devops.TaskDatesInfo{
+		QueuedDate: &completedAt, StartedDate: &startedAt, FinishedDate: &createdAt,
+	}}
+	setJobDurations(task)
+	assert.Nil(t, task.QueuedDurationSec)
+	assert.Equal(t, float64(0), task.DurationSec)
+}
+
+func TestGetJobResult(t *testing.T) {
+	for _, tc := range []struct {
+		conclusion string
+		result     string
+	}{
+		{"success", devops.RESULT_SUCCESS},
+		{"failure", devops.RESULT_FAILURE},
+		{"timed_out", devops.RESULT_FAILURE},
+		{"startup_failure", devops.RESULT_FAILURE},
+		{"cancelled", devops.RESULT_DEFAULT},
+		{"skipped", devops.RESULT_DEFAULT},
+		{"action_required", devops.RESULT_DEFAULT},
+		{"neutral", devops.RESULT_DEFAULT},
+		{"stale", devops.RESULT_DEFAULT},
+		{"SUCCESS", devops.RESULT_SUCCESS},
+		{"", devops.RESULT_DEFAULT},
+		{"unknown", devops.RESULT_DEFAULT},
+	} {
+		t.Run(tc.conclusion, func(t *testing.T) {
+			assert.Equal(t, tc.result, getJobResult(tc.conclusion))
+		})
+	}
+}
+
+func TestCicdTaskName(t *testing.T) {
+	assert.Equal(t, "build (ubuntu-latest, 1.21)", cicdTaskName("build (ubuntu-latest, 1.21)"))
+
+	// a job of a large matrix, cicd_tasks.name is a varchar(255)
+	name := "test (" + strings.Repeat("é", 300) + ")"
+	truncated := cicdTaskName(name)
+	assert.Equal(t, MAX_CICD_TASK_NAME_LENGTH, utf8.RuneCountInString(truncated))
+	assert.True(t, strings.HasPrefix(name, truncated))
+}
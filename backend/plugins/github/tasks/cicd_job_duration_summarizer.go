@@ -0,0 +1,141 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&SummarizeJobDurationsMeta)
+}
+
+var SummarizeJobDurationsMeta = plugin.SubTaskMeta{
+	Name:             "Summarize Job Durations",
+	EntryPoint:       SummarizeJobDurations,
+	EnabledByDefault: true,
+	Description:      "Compute the p50/p90 durations of the completed jobs of each workflow into github_job_duration_summaries",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubJob{}.TableName(), // durations
+		models.GithubRun{}.TableName(), // workflow of the job
+	},
+	ProductTables: []string{models.GithubJobDurationSummary{}.TableName()},
+}
+
+type githubJobDuration struct {
+	StartedAt       *time.Time
+	CompletedAt     *time.Time
+	GithubUpdatedAt *time.Time
+	WorkflowName    string
+}
+
+// SummarizeJobDurations only computes again the summaries of the workflows with jobs updated since they were
+// summarized, unless it is a full sync
+func SummarizeJobDurations(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	logger := taskCtx.GetLogger()
+
+	clauses := []dal.Clause{
+		dal.Select("DISTINCT r.workflow_id"),
+		dal.From("_tool_github_jobs j"),
+		dal.Join(`JOIN _tool_github_runs r
+			ON r.connection_id = j.connection_id AND r.repo_id = j.repo_id AND r.id = j.run_id`),
+		dal.Where("j.repo_id = ? AND j.connection_id = ? AND j.status = ?",
+			data.Options.GithubId, data.Options.ConnectionId, StatusCompleted),
+	}
+	if syncPolicy := taskCtx.TaskContext().SyncPolicy(); syncPolicy == nil || !syncPolicy.FullSync {
+		clauses = append(clauses,
+			dal.Join(`LEFT JOIN _tool_github_job_duration_summaries s
+				ON s.connection_id = r.connection_id AND s.repo_id = r.repo_id AND s.workflow_id = r.workflow_id`),
+			dal.Where("(s.workflow_id IS NULL OR j.github_updated_at > s.latest_job_updated_at)"),
+		)
+	}
+	var workflowIds []int
+	err := db.Pluck("workflow_id", &workflowIds, clauses...)
+	if err != nil {
+		return err
+	}
+	logger.Info("summarizing the job durations of %d workflows", len(workflowIds))
+
+	for _, workflowId := range workflowIds {
+		var jobs []githubJobDuration
+		err = db.All(
+			&jobs,
+			dal.Select("j.started_at, j.completed_at, j.github_updated_at, r.name AS workflow_name"),
+			dal.From("_tool_github_jobs j"),
+			dal.Join(`JOIN _tool_github_runs r
+				ON r.connection_id = j.connection_id AND r.repo_id = j.repo_id AND r.id = j.run_id`),
+			dal.Where("j.repo_id = ? AND j.connection_id = ? AND j.status = ? AND r.workflow_id = ?",
+				data.Options.GithubId, data.Options.ConnectionId, StatusCompleted, workflowId),
+		)
+		if err != nil {
+			return err
+		}
+		summary := summarizeJobDurations(jobs)
+		summary.ConnectionId = data.Options.ConnectionId
+		summary.RepoId = data.Options.GithubId
+		summary.WorkflowId = workflowId
+		err = db.CreateOrUpdate(summary)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to save the job duration summary")
+		}
+	}
+	return nil
+}
+
+// summarizeJobDurations computes the percentiles of the durations of the jobs which started and completed
+func summarizeJobDurations(jobs []githubJobDuration) *models.GithubJobDurationSummary {
+	summary := &models.GithubJobDurationSummary{}
+	durations := make([]float64, 0, len(jobs))
+	for _, job := range jobs {
+		summary.WorkflowName = job.WorkflowName
+		if job.GithubUpdatedAt != nil && (summary.LatestJobUpdatedAt == nil || job.GithubUpdatedAt.After(*summary.LatestJobUpdatedAt)) {
+			summary.LatestJobUpdatedAt = job.GithubUpdatedAt
+		}
+		if job.StartedAt == nil || job.CompletedAt == nil || job.CompletedAt.Before(*job.StartedAt) {
+			continue
+		}
+		durations = append(durations, job.CompletedAt.Sub(*job.StartedAt).Seconds())
+	}
+	sort.Float64s(durations)
+	summary.JobCount = len(durations)
+	summary.P50DurationSec = percentile(durations, 50)
+	summary.P90DurationSec = percentile(durations, 90)
+	return summary
+}
+
+// percentile returns the nearest-rank percentile of the sorted values, 0 when there is none
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
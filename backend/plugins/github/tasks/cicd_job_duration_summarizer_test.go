@@ -0,0 +1,53 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, float64(0), percentile(nil, 50))
+	assert.Equal(t, float64(7), percentile([]float64{7}, 90))
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, float64(5), percentile(sorted, 50))
+	assert.Equal(t, float64(9), percentile(sorted, 90))
+	assert.Equal(t, float64(1), percentile(sorted, 0))
+}
+
+func TestSummarizeJobDurations(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	job := func(minutes int) githubJobDuration {
+		completedAt := start.Add(time.Duration(minutes) * time.Minute)
+		return githubJobDuration{StartedAt: &start, CompletedAt: &completedAt, GithubUpdatedAt: &completedAt, WorkflowName: "CI"}
+	}
+	jobs := []githubJobDuration{job(3), job(1), job(10), job(2)}
+	// skipped jobs never started, they are counted for the watermark only
+	skippedAt := start.Add(time.Hour)
+	jobs = append(jobs, githubJobDuration{CompletedAt: &skippedAt, GithubUpdatedAt: &skippedAt, WorkflowName: "CI"})
+
+	summary := summarizeJobDurations(jobs)
+	assert.Equal(t, "CI", summary.WorkflowName)
+	assert.Equal(t, 4, summary.JobCount)
+	assert.Equal(t, float64(120), summary.P50DurationSec)
+	assert.Equal(t, float64(600), summary.P90DurationSec)
+	assert.Equal(t, &skippedAt, summary.LatestJobUpdatedAt)
+}
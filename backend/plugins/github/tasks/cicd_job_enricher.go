@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+// JobEnricher adds data of an external source to the jobs while they are extracted, i.e. the test results uploaded
+// as artifacts, without forking the extractor. Enrichers are registered by RegisterJobEnricher, usually in the init
+// function of their package
+type JobEnricher interface {
+	// EnrichJob is called for every extracted job along with the raw data it was extracted from. It may update the
+	// job, and returns the records to save along with it, i.e. the rows of a table of its own
+	EnrichJob(taskCtx plugin.SubTaskContext, job *models.GithubJob, raw json.RawMessage) ([]interface{}, errors.Error)
+}
+
+var JobEnrichers []JobEnricher
+
+func RegisterJobEnricher(enricher JobEnricher) {
+	JobEnrichers = append(JobEnrichers, enricher)
+}
+
+// enrichJob runs the registered enrichers on the job in the order they were registered, it returns the records
+// they produced
+func enrichJob(taskCtx plugin.SubTaskContext, job *models.GithubJob, raw json.RawMessage) ([]interface{}, errors.Error) {
+	var results []interface{}
+	for _, enricher := range JobEnrichers {
+		records, err := enricher.EnrichJob(taskCtx, job, raw)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to enrich job %d with %T", job.ID, enricher))
+		}
+		results = append(results, records...)
+	}
+	return results, nil
+}
@@ -0,0 +1,70 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type jobTestCount struct {
+	JobId     int
+	TestCount int
+}
+
+type testCountEnricher struct{}
+
+func (testCountEnricher) EnrichJob(_ plugin.SubTaskContext, job *models.GithubJob, raw json.RawMessage) ([]interface{}, errors.Error) {
+	if job.Name != "test" {
+		return nil, nil
+	}
+	return []interface{}{&jobTestCount{JobId: job.ID, TestCount: 42}}, nil
+}
+
+type failingEnricher struct{}
+
+func (failingEnricher) EnrichJob(_ plugin.SubTaskContext, _ *models.GithubJob, _ json.RawMessage) ([]interface{}, errors.Error) {
+	return nil, errors.Default.New("the source is down")
+}
+
+func TestEnrichJob(t *testing.T) {
+	defer func(enrichers []JobEnricher) { JobEnrichers = enrichers }(JobEnrichers)
+	JobEnrichers = nil
+
+	// nothing to do without enrichers
+	results, err := enrichJob(nil, &models.GithubJob{ID: 1, Name: "test"}, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+
+	RegisterJobEnricher(testCountEnricher{})
+	results, err = enrichJob(nil, &models.GithubJob{ID: 1, Name: "test"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{&jobTestCount{JobId: 1, TestCount: 42}}, results)
+	results, err = enrichJob(nil, &models.GithubJob{ID: 2, Name: "build"}, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+
+	RegisterJobEnricher(failingEnricher{})
+	_, err = enrichJob(nil, &models.GithubJob{ID: 1, Name: "test"}, nil)
+	assert.NotNil(t, err)
+}
@@ -19,86 +19,89 @@ package tasks
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/plugins/github/models"
+	"gorm.io/datatypes"
 )
 
 func init() {
 	RegisterSubtaskMeta(&ExtractJobsMeta)
 }
 
+// DEFAULT_JOBS_EXTRACT_BATCH_SIZE is the default number of rows of each table saved at once by the extraction of the jobs
+const DEFAULT_JOBS_EXTRACT_BATCH_SIZE = 500
+
+// MAX_JOBS_EXTRACT_BATCH_SIZE keeps the placeholders of a batch of jobs, one per column, under the 65535 MySQL allows
+const MAX_JOBS_EXTRACT_BATCH_SIZE = 1000
+
 var ExtractJobsMeta = plugin.SubTaskMeta{
 	Name:             "Extract Jobs",
 	EntryPoint:       ExtractJobs,
 	EnabledByDefault: true,
-	Description:      "Extract raw run data into tool layer table github_jobs",
+	Description:      "Extract raw run data into tool layer table github_jobs and github_job_steps",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
-	DependencyTables: []string{RAW_JOB_TABLE},
-	ProductTables:    []string{models.GithubJob{}.TableName()},
+	DependencyTables: []string{
+		RAW_JOB_TABLE,
+		models.GithubRun{}.TableName(), // event, head sha and name
+	},
+	ProductTables: []string{models.GithubJob{}.TableName(), models.GithubJobStep{}.TableName()},
 }
 
 func ExtractJobs(taskCtx plugin.SubTaskContext) errors.Error {
-	data := taskCtx.GetData().(*GithubTaskData)
+	return forEachJobsScope(taskCtx, extractJobs)
+}
+
+func extractJobs(taskCtx plugin.SubTaskContext, data *GithubTaskData) errors.Error {
+	rawParams, err := jobsRawDataParams(data.Options)
+	if err != nil {
+		return err
+	}
 	repoId := data.Options.GithubId
+	runs := newJobRunLoader(taskCtx.GetDal(), data.Options)
 
 	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
 		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
-			Ctx: taskCtx,
-			Params: GithubApiParams{
-				ConnectionId: data.Options.ConnectionId,
-				Name:         data.Options.Name,
-			},
-			Table: RAW_JOB_TABLE,
+			Ctx:    taskCtx,
+			Params: rawParams,
+			Table:  RAW_JOB_TABLE,
 		},
+		// the jobs, their steps and enrichments are upserted in batches of as many rows per table
+		BatchSize: data.Options.JobsExtractBatchSize,
 		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
-			githubJob := &models.GithubJob{}
-			err := errors.Convert(json.Unmarshal(row.Data, githubJob))
+			githubJobResult, err := extractJob(data, repoId, row.Data)
+			if err != nil || githubJobResult == nil {
+				return nil, err
+			}
+			run, err := runs.load(githubJobResult.RunID)
 			if err != nil {
 				return nil, err
 			}
-
+			copyRunToJob(githubJobResult, run)
 			results := make([]interface{}, 0, 1)
-			
-			// Handle zero time values to avoid MySQL datetime errors
-			var startedAt, completedAt *time.Time
-			if githubJob.StartedAt != nil && !githubJob.StartedAt.IsZero() && githubJob.StartedAt.Year() > 0 {
-				startedAt = githubJob.StartedAt
+			results = append(results, githubJobResult)
+
+			enriched, err := enrichJob(taskCtx, githubJobResult, row.Data)
+			if err != nil {
+				return nil, err
 			}
-			if githubJob.CompletedAt != nil && !githubJob.CompletedAt.IsZero() && githubJob.CompletedAt.Year() > 0 {
-				completedAt = githubJob.CompletedAt
+			results = append(results, enriched...)
+
+			steps, err := extractJobSteps(githubJobResult)
+			if err != nil {
+				return nil, err
 			}
-			
-			githubJobResult := &models.GithubJob{
-				ConnectionId:  data.Options.ConnectionId,
-				RepoId:        repoId,
-				ID:            githubJob.ID,
-				RunID:         githubJob.RunID,
-				RunURL:        githubJob.RunURL,
-				NodeID:        githubJob.NodeID,
-				HeadSha:       githubJob.HeadSha,
-				URL:           githubJob.URL,
-				HTMLURL:       githubJob.HTMLURL,
-				Status:        strings.ToUpper(githubJob.Status),
-				Conclusion:    strings.ToUpper(githubJob.Conclusion),
-				StartedAt:     startedAt,
-				CompletedAt:   completedAt,
-				Name:          githubJob.Name,
-				Steps:         githubJob.Steps,
-				CheckRunURL:   githubJob.CheckRunURL,
-				Labels:        githubJob.Labels,
-				RunnerID:      githubJob.RunID,
-				RunnerName:    githubJob.RunnerName,
-				RunnerGroupID: githubJob.RunnerGroupID,
-				Type:          data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, githubJob.Name),
-				Environment:   data.RegexEnricher.ReturnNameIfOmittedOrMatched(devops.PRODUCTION, githubJob.Name),
+			for _, step := range steps {
+				results = append(results, step)
 			}
-			results = append(results, githubJobResult)
 			return results, nil
 		},
 	})
@@ -109,3 +112,219 @@ func ExtractJobs(taskCtx plugin.SubTaskContext) errors.Error {
 
 	return extractor.Execute()
 }
+
+// extractJob builds the job of the raw data, nil when its conclusion is filtered out. The primary key only depends on
+// the raw data, so extracting a job collected twice, i.e. within the incremental overlap, updates the same row
+func extractJob(data *GithubTaskData, repoId int, raw json.RawMessage) (*models.GithubJob, errors.Error) {
+	githubJob := &models.GithubJob{}
+	err := errors.Convert(json.Unmarshal(raw, githubJob))
+	if err != nil {
+		return nil, err
+	}
+	if !isJobConclusionAllowed(data.Options.JobConclusions, githubJob.Conclusion) {
+		return nil, nil
+	}
+
+	// Handle zero time values to avoid MySQL datetime errors
+	createdAt := api.NormalizeNullableTime(githubJob.GithubCreatedAt)
+	startedAt := api.NormalizeNullableTime(githubJob.StartedAt)
+	completedAt := api.NormalizeNullableTime(githubJob.CompletedAt)
+
+	job := &models.GithubJob{
+		ConnectionId:    data.Options.ConnectionId,
+		RepoId:          repoId,
+		ID:              githubJob.ID,
+		RunID:           githubJob.RunID,
+		RunAttempt:      githubJob.RunAttempt,
+		RunURL:          githubJob.RunURL,
+		NodeID:          githubJob.NodeID,
+		HeadSha:         githubJob.HeadSha,
+		URL:             githubJob.URL,
+		HTMLURL:         githubJob.HTMLURL,
+		Status:          strings.ToUpper(githubJob.Status),
+		Conclusion:      strings.ToUpper(githubJob.Conclusion),
+		GithubCreatedAt: createdAt,
+		StartedAt:       startedAt,
+		CompletedAt:     completedAt,
+		Name:            githubJob.Name,
+		Steps:           githubJob.Steps,
+		CheckRunURL:     githubJob.CheckRunURL,
+		Labels:          normalizeJobLabels(githubJob.Labels),
+		RunnerID:        githubJob.RunnerID,
+		RunnerName:      githubJob.RunnerName,
+		RunnerGroupID:   githubJob.RunnerGroupID,
+		RunnerGroupName: githubJob.RunnerGroupName,
+		Type:            data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, githubJob.Name),
+		Environment:     data.RegexEnricher.ReturnNameIfOmittedOrMatched(devops.PRODUCTION, githubJob.Name),
+		GithubUpdatedAt: jobUpdatedAt(startedAt, completedAt),
+		DurationSec:     jobDurationSec(startedAt, completedAt),
+		WorkflowName:    githubJob.WorkflowName,
+	}
+	job.NoRunnerAvailable = noRunnerAvailable(job)
+	if data.Options.KeepRawOnExtract {
+		job.RawPayload = datatypes.JSON(raw)
+	}
+	if data.Options.ParseJobMatrix {
+		job.Matrix = parseJobMatrix(job.Name)
+	}
+	return job, nil
+}
+
+// noRunnerAvailable tells whether the job was likely cancelled while waiting for a runner matching its labels, i.e.
+// when no self-hosted runner has them or all of them are offline. GitHub doesn't tell why a job was cancelled, so it is
+// guessed: the job was queued, it has a started_at, which GitHub sets when the job leaves the queue even if it is
+// cancelled, yet no runner was ever assigned to it. A job cancelled while it was running has a runner, and a job whose
+// run was cancelled before it was queued has no created_at
+func noRunnerAvailable(job *models.GithubJob) bool {
+	return job.Conclusion == StatusCancelled && job.RunnerID == 0 && job.RunnerName == "" &&
+		job.GithubCreatedAt != nil && job.StartedAt != nil
+}
+
+// githubJobRun holds the fields of a run copied onto its jobs
+type githubJobRun struct {
+	ID           int
+	Event        string
+	HeadSha      string
+	Name         string
+	DisplayTitle string
+	URL          string
+}
+
+// jobRunLoader loads the event, head sha, name, title and url of the run of the jobs being extracted, so the jobs don't
+// need to be joined with their runs at query time. The raw jobs of a page of the api belong to the same run and are
+// stored together, so only the last run is kept instead of all the runs of the repo
+type jobRunLoader struct {
+	db     dal.Dal
+	op     *GithubOptions
+	lastId int
+	last   *githubJobRun
+}
+
+func newJobRunLoader(db dal.Dal, op *GithubOptions) *jobRunLoader {
+	return &jobRunLoader{db: db, op: op, lastId: -1}
+}
+
+// load returns the run of the id, nil when it was not collected
+func (l *jobRunLoader) load(runId int) (*githubJobRun, errors.Error) {
+	if runId == l.lastId {
+		return l.last, nil
+	}
+	var runs []*githubJobRun
+	err := l.db.All(
+		&runs,
+		dal.Select("id, event, head_sha, name, display_title, url"),
+		dal.From(&models.GithubRun{}),
+		dal.Where("repo_id = ? AND connection_id = ? AND id = ?", l.op.GithubId, l.op.ConnectionId, runId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to load the run %d of the jobs", runId))
+	}
+	l.lastId = runId
+	l.last = nil
+	if len(runs) > 0 {
+		l.last = runs[0]
+	}
+	return l.last, nil
+}
+
+// copyRunToJob copies the event, name and title of the run onto the job, and its head sha and url when the job has
+// none, so the job can still be told apart once its run is deleted. The job is left as is when its run was not
+// collected
+func copyRunToJob(job *models.GithubJob, run *githubJobRun) {
+	if run == nil {
+		return
+	}
+	job.Event = run.Event
+	job.RunName = run.Name
+	job.RunDisplayTitle = run.DisplayTitle
+	if job.HeadSha == "" {
+		job.HeadSha = run.HeadSha
+	}
+	if job.RunURL == "" {
+		job.RunURL = run.URL
+	}
+}
+
+// jobMatrixName matches the names GitHub gives to the jobs of a matrix, the name of the job followed by the values of
+// the matrix in parentheses
+var jobMatrixName = regexp.MustCompile(`^.+ \((.+)\)$`)
+
+// parseJobMatrix returns the values of the matrix of the job as a JSON array, nil when the name is not the one of a
+// matrix job
+func parseJobMatrix(name string) datatypes.JSON {
+	match := jobMatrixName.FindStringSubmatch(name)
+	if match == nil {
+		return nil
+	}
+	values := strings.Split(match[1], ",")
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+	}
+	matrix, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return datatypes.JSON(matrix)
+}
+
+// jobUpdatedAt derives when the job was last updated from its timestamps, nil when it never started
+func jobUpdatedAt(startedAt, completedAt *time.Time) *time.Time {
+	if completedAt != nil && (startedAt == nil || completedAt.After(*startedAt)) {
+		return completedAt
+	}
+	return startedAt
+}
+
+// jobDurationSec is how many seconds the job ran, nil when it didn't start or complete, or when it completed before it
+// started, i.e. a zero completed_at normalized to nil or the clocks of the runners were off
+func jobDurationSec(startedAt, completedAt *time.Time) *float64 {
+	if startedAt == nil || completedAt == nil || completedAt.Before(*startedAt) {
+		return nil
+	}
+	duration := float64(completedAt.Sub(*startedAt).Milliseconds() / 1e3)
+	return &duration
+}
+
+// normalizeJobLabels stores the labels of the jobs without a runner, i.e. queued ones, as an empty array instead of null
+func normalizeJobLabels(labels datatypes.JSON) datatypes.JSON {
+	if len(labels) == 0 || string(labels) == "null" {
+		return datatypes.JSON("[]")
+	}
+	return labels
+}
+
+// isJobConclusionAllowed tells if a job with the conclusion should be extracted, an empty allow-list keeps all jobs
+func isJobConclusionAllowed(allowed []string, conclusion string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, c := range allowed {
+		if strings.EqualFold(c, conclusion) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractJobSteps parses the nested steps of a job, a job without steps yields nothing
+func extractJobSteps(job *models.GithubJob) ([]*models.GithubJobStep, errors.Error) {
+	if len(job.Steps) == 0 {
+		return nil, nil
+	}
+	var steps []*models.GithubJobStep
+	err := errors.Convert(json.Unmarshal(job.Steps, &steps))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to parse steps of job %d", job.ID))
+	}
+	for _, step := range steps {
+		step.ConnectionId = job.ConnectionId
+		step.RepoId = job.RepoId
+		step.JobId = job.ID
+		step.RunId = job.RunID
+		step.Status = strings.ToUpper(step.Status)
+		step.Conclusion = strings.ToUpper(step.Conclusion)
+		step.StartedAt = api.NormalizeNullableTime(step.StartedAt)
+		step.CompletedAt = api.NormalizeNullableTime(step.CompletedAt)
+	}
+	return steps, nil
+}
@@ -19,19 +19,28 @@ package tasks
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockcontext "github.com/apache/incubator-devlake/mocks/core/context"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
 	"github.com/apache/incubator-devlake/plugins/github/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestExtractJobs_ZeroTimeHandling(t *testing.T) {
 	// Test data with zero time values that would cause MySQL errors
-	year0Time := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)  // Year 0000 time from JSON parsing
-	year1Time := time.Time{}  // Go's zero time (year 0001)
+	year0Time := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC) // Year 0000 time from JSON parsing
+	year1Time := time.Time{}                              // Go's zero time (year 0001)
 	validTime := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
-	
+
 	testCases := []struct {
 		name        string
 		inputJob    *models.GithubJob
@@ -92,14 +101,8 @@ func TestExtractJobs_ZeroTimeHandling(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			// Simulate the extraction logic
-			var startedAt, completedAt *time.Time
-			if tc.inputJob.StartedAt != nil && !tc.inputJob.StartedAt.IsZero() && tc.inputJob.StartedAt.Year() > 0 {
-				startedAt = tc.inputJob.StartedAt
-			}
-			if tc.inputJob.CompletedAt != nil && !tc.inputJob.CompletedAt.IsZero() && tc.inputJob.CompletedAt.Year() > 0 {
-				completedAt = tc.inputJob.CompletedAt
-			}
+			startedAt := api.NormalizeNullableTime(tc.inputJob.StartedAt)
+			completedAt := api.NormalizeNullableTime(tc.inputJob.CompletedAt)
 
 			assert.Equal(t, tc.expectStart, startedAt, "StartedAt should match expected value")
 			assert.Equal(t, tc.expectEnd, completedAt, "CompletedAt should match expected value")
@@ -129,16 +132,525 @@ func TestExtractJobs_RawDataProcessing(t *testing.T) {
 	assert.Equal(t, 0, githubJob.StartedAt.Year()) // But it has year 0000
 	assert.Nil(t, githubJob.CompletedAt)
 
-	// Simulate the extraction logic
-	var startedAt, completedAt *time.Time
-	if githubJob.StartedAt != nil && !githubJob.StartedAt.IsZero() && githubJob.StartedAt.Year() > 0 {
-		startedAt = githubJob.StartedAt
-	}
-	if githubJob.CompletedAt != nil && !githubJob.CompletedAt.IsZero() && githubJob.CompletedAt.Year() > 0 {
-		completedAt = githubJob.CompletedAt
-	}
+	startedAt := api.NormalizeNullableTime(githubJob.StartedAt)
+	completedAt := api.NormalizeNullableTime(githubJob.CompletedAt)
 
 	// Year 0000 time should be converted to nil, null should remain nil
 	assert.Nil(t, startedAt)
 	assert.Nil(t, completedAt)
 }
+
+func TestExtractJobs_CreatedAtZeroTimeHandling(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	validTime := time.Date(2024, 3, 1, 9, 58, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		createdAt     string
+		expectCreated *time.Time
+	}{
+		{name: "null created_at should remain nil", createdAt: `null`, expectCreated: nil},
+		{name: "year 0000 created_at should become nil", createdAt: `"0000-01-01T00:00:00Z"`, expectCreated: nil},
+		{name: "year 0001 created_at should become nil", createdAt: `"0001-01-01T00:00:00Z"`, expectCreated: nil},
+		{name: "valid created_at should be preserved", createdAt: `"2024-03-01T09:58:00Z"`, expectCreated: &validTime},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := json.RawMessage(`{"id": 123, "run_id": 456, "status": "completed", "conclusion": "success",
+				"created_at": ` + tc.createdAt + `, "started_at": "2024-03-01T10:00:00Z"}`)
+			job, err := extractJob(data, 2, raw)
+			assert.Nil(t, err)
+			if tc.expectCreated == nil {
+				assert.Nil(t, job.GithubCreatedAt)
+			} else {
+				assert.True(t, tc.expectCreated.Equal(*job.GithubCreatedAt))
+			}
+		})
+	}
+}
+
+func TestExtractJobSteps(t *testing.T) {
+	job := &models.GithubJob{
+		ConnectionId: 1,
+		RepoId:       2,
+		ID:           123,
+		RunID:        456,
+		Steps: []byte(`[
+			{"name": "Set up job", "status": "completed", "conclusion": "success", "number": 1,
+			 "started_at": "2023-01-15T10:30:00Z", "completed_at": "2023-01-15T10:30:05Z"},
+			{"name": "Post Run", "status": "queued", "conclusion": null, "number": 2,
+			 "started_at": "0000-01-01T00:00:00Z", "completed_at": null}
+		]`),
+	}
+
+	steps, err := extractJobSteps(job)
+	assert.Nil(t, err)
+	assert.Len(t, steps, 2)
+	assert.Equal(t, uint64(1), steps[0].ConnectionId)
+	assert.Equal(t, 2, steps[0].RepoId)
+	assert.Equal(t, 123, steps[0].JobId)
+	assert.Equal(t, 456, steps[0].RunId)
+	assert.Equal(t, 1, steps[0].Number)
+	assert.Equal(t, "SUCCESS", steps[0].Conclusion)
+	assert.NotNil(t, steps[0].StartedAt)
+	assert.NotNil(t, steps[0].CompletedAt)
+	assert.Equal(t, "QUEUED", steps[1].Status)
+	assert.Nil(t, steps[1].StartedAt)
+	assert.Nil(t, steps[1].CompletedAt)
+}
+
+func TestExtractJobSteps_NullSteps(t *testing.T) {
+	for _, raw := range []string{`{"id": 1}`, `{"id": 1, "steps": null}`, `{"id": 1, "steps": []}`} {
+		var job models.GithubJob
+		assert.NoError(t, json.Unmarshal([]byte(raw), &job))
+		steps, err := extractJobSteps(&job)
+		assert.Nil(t, err)
+		assert.Empty(t, steps)
+	}
+}
+
+func TestIsJobConclusionAllowed(t *testing.T) {
+	assert.True(t, isJobConclusionAllowed(nil, "skipped"))
+	assert.True(t, isJobConclusionAllowed([]string{}, ""))
+	assert.True(t, isJobConclusionAllowed([]string{"success", "failure"}, "failure"))
+	assert.True(t, isJobConclusionAllowed([]string{"SUCCESS"}, "success"))
+	assert.False(t, isJobConclusionAllowed([]string{"success", "failure"}, "skipped"))
+	assert.False(t, isJobConclusionAllowed([]string{"success"}, ""))
+}
+
+func TestNormalizeJobLabels(t *testing.T) {
+	for raw, expected := range map[string]string{
+		`{"id": 1}`:                            `[]`,
+		`{"id": 1, "labels": null}`:            `[]`,
+		`{"id": 1, "labels": ["self-hosted"]}`: `["self-hosted"]`,
+	} {
+		var job models.GithubJob
+		assert.NoError(t, json.Unmarshal([]byte(raw), &job))
+		assert.Equal(t, expected, string(normalizeJobLabels(job.Labels)))
+	}
+
+	// runner info is left empty until a runner picks the job up
+	var job models.GithubJob
+	assert.NoError(t, json.Unmarshal([]byte(`{"id": 1, "run_id": 2, "runner_id": 3, "runner_name": "runner-1",
+		"runner_group_id": 4, "runner_group_name": "Default"}`), &job))
+	assert.Equal(t, 3, job.RunnerID)
+	assert.Equal(t, "Default", job.RunnerGroupName)
+	job = models.GithubJob{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"id": 1, "runner_id": null, "runner_group_name": null}`), &job))
+	assert.Equal(t, 0, job.RunnerID)
+	assert.Equal(t, "", job.RunnerGroupName)
+}
+
+func TestJobUpdatedAt(t *testing.T) {
+	startedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	completedAt := startedAt.Add(5 * time.Minute)
+	assert.Nil(t, jobUpdatedAt(nil, nil))
+	assert.Equal(t, &startedAt, jobUpdatedAt(&startedAt, nil))
+	assert.Equal(t, &completedAt, jobUpdatedAt(&startedAt, &completedAt))
+	// a re-run job starts after the previous attempt completed
+	rerunStartedAt := completedAt.Add(time.Hour)
+	assert.Equal(t, &rerunStartedAt, jobUpdatedAt(&rerunStartedAt, &completedAt))
+}
+
+func TestJobDurationSec(t *testing.T) {
+	startedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	completedAt := startedAt.Add(5*time.Minute + 30*time.Second)
+	duration := jobDurationSec(&startedAt, &completedAt)
+	if assert.NotNil(t, duration) {
+		assert.Equal(t, float64(330), *duration)
+	}
+	// the job is queued or running
+	assert.Nil(t, jobDurationSec(nil, nil))
+	assert.Nil(t, jobDurationSec(&startedAt, nil))
+	assert.Nil(t, jobDurationSec(nil, &completedAt))
+	assert.Nil(t, jobDurationSec(&completedAt, &startedAt))
+}
+
+func TestExtractJobDurationSec(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	job, err := extractJob(data, 2, json.RawMessage(`{"id": 1, "run_id": 10, "status": "completed",
+		"started_at": "2024-03-01T10:00:00Z", "completed_at": "2024-03-01T10:01:05Z"}`))
+	assert.Nil(t, err)
+	if assert.NotNil(t, job.DurationSec) {
+		assert.Equal(t, float64(65), *job.DurationSec)
+	}
+
+	// the zero completed_at of a job still in progress is normalized to null, and so is the duration
+	job, err = extractJob(data, 2, json.RawMessage(`{"id": 2, "run_id": 10, "status": "in_progress",
+		"started_at": "2024-03-01T10:00:00Z", "completed_at": "0001-01-01T00:00:00Z"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, job.CompletedAt)
+	assert.Nil(t, job.DurationSec)
+}
+
+func TestExtractJobTwiceIsUpserted(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	raw := json.RawMessage(`{"id": 123, "run_id": 456, "name": "build", "status": "completed", "conclusion": "success",
+		"started_at": "2024-03-01T10:00:00Z", "completed_at": "2024-03-01T10:05:00Z"}`)
+
+	// the job is collected again within the incremental overlap
+	first, err := extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	second, err := extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+
+	jobType := reflect.TypeOf(models.GithubJob{})
+	primaryKey := []reflect.StructField{}
+	for _, name := range []string{"ConnectionId", "RepoId", "ID"} {
+		field, _ := jobType.FieldByName(name)
+		primaryKey = append(primaryKey, field)
+	}
+	mockDal := new(mockdal.Dal)
+	mockDal.On("GetPrimaryKeyFields", mock.Anything).Return(primaryKey)
+	mockDal.On("CreateOrUpdate", mock.MatchedBy(func(jobs []*models.GithubJob) bool {
+		return len(jobs) == 1
+	}), mock.Anything).Return(nil).Once()
+	mockRes := new(mockcontext.BasicRes)
+	mockRes.On("GetDal").Return(mockDal)
+	mockRes.On("GetLogger").Return(unithelper.DummyLogger())
+
+	batch, err := api.NewBatchSave(mockRes, reflect.TypeOf(first), 10)
+	assert.Nil(t, err)
+	assert.Nil(t, batch.Add(first))
+	assert.Nil(t, batch.Add(second))
+	assert.Nil(t, batch.Flush())
+	mockDal.AssertExpectations(t)
+}
+
+func TestExtractJobTwiceKeepsLatest(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	// the job is returned again by a later collection after it was re-run on the same attempt
+	first, err := extractJob(data, 2, json.RawMessage(`{"id": 123, "run_id": 456, "name": "build",
+		"status": "completed", "conclusion": "failure", "completed_at": "2024-03-01T10:05:00Z"}`))
+	assert.Nil(t, err)
+	second, err := extractJob(data, 2, json.RawMessage(`{"id": 123, "run_id": 456, "name": "build",
+		"status": "completed", "conclusion": "success", "completed_at": "2024-03-01T10:15:00Z"}`))
+	assert.Nil(t, err)
+
+	jobType := reflect.TypeOf(models.GithubJob{})
+	primaryKey := []reflect.StructField{}
+	for _, name := range []string{"ConnectionId", "RepoId", "ID"} {
+		field, _ := jobType.FieldByName(name)
+		primaryKey = append(primaryKey, field)
+	}
+	var saved []*models.GithubJob
+	mockDal := new(mockdal.Dal)
+	mockDal.On("GetPrimaryKeyFields", mock.Anything).Return(primaryKey)
+	mockDal.On("CreateOrUpdate", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).([]*models.GithubJob)...)
+	})
+	mockRes := new(mockcontext.BasicRes)
+	mockRes.On("GetDal").Return(mockDal)
+	mockRes.On("GetLogger").Return(unithelper.DummyLogger())
+
+	// within a batch the latest values replace the previous ones
+	batch, err := api.NewBatchSave(mockRes, reflect.TypeOf(first), 10)
+	assert.Nil(t, err)
+	assert.Nil(t, batch.Add(first))
+	assert.Nil(t, batch.Add(second))
+	assert.Nil(t, batch.Flush())
+	assert.Len(t, saved, 1)
+	assert.Equal(t, StatusSuccess, saved[0].Conclusion)
+
+	// across the batches the row is upserted rather than inserted again
+	saved = nil
+	assert.Nil(t, batch.Add(first))
+	assert.Nil(t, batch.Flush())
+	assert.Nil(t, batch.Add(second))
+	assert.Nil(t, batch.Flush())
+	assert.Len(t, saved, 2)
+	assert.Equal(t, StatusSuccess, saved[1].Conclusion)
+	mockDal.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestExtractJobGraphqlKeys(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	job, err := extractJob(data, 2, json.RawMessage(`{"id": 123, "run_id": 456, "node_id": "CR_kwDOABCD",
+		"check_run_url": "https://api.github.com/repos/a/b/check-runs/123"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "CR_kwDOABCD", job.NodeID)
+	assert.Equal(t, "https://api.github.com/repos/a/b/check-runs/123", job.CheckRunURL)
+}
+
+func TestExtractJobAttempts(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1, CollectAllAttempts: true},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+
+	// the run was re-run, each attempt has its own jobs
+	first, err := extractJob(data, 2, json.RawMessage(`{"id": 123, "run_id": 456, "run_attempt": 1, "name": "build",
+		"status": "completed", "conclusion": "failure"}`))
+	assert.Nil(t, err)
+	second, err := extractJob(data, 2, json.RawMessage(`{"id": 789, "run_id": 456, "run_attempt": 2, "name": "build",
+		"status": "completed", "conclusion": "success"}`))
+	assert.Nil(t, err)
+
+	assert.Equal(t, first.RunID, second.RunID)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, 1, first.RunAttempt)
+	assert.Equal(t, "FAILURE", first.Conclusion)
+	assert.Equal(t, 2, second.RunAttempt)
+	assert.Equal(t, "SUCCESS", second.Conclusion)
+}
+
+func TestExtractJobKeepRawOnExtract(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	raw := json.RawMessage(`{"id": 123, "run_id": 456, "name": "build", "status": "completed", "conclusion": "success"}`)
+
+	job, err := extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	assert.Nil(t, job.RawPayload)
+
+	data.Options.KeepRawOnExtract = true
+	job, err = extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(raw), string(job.RawPayload))
+}
+
+func TestJobRunLoader(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	mockDal.On("All", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs := args.Get(0).(*[]*githubJobRun)
+		runId := args.Get(1).([]dal.Clause)[2].Data.(dal.DalClause).Params[2].(int)
+		if runId == 456 {
+			*runs = []*githubJobRun{{ID: 456, Event: "push"}}
+		}
+	}).Return(nil).Twice()
+	loader := newJobRunLoader(mockDal, &GithubOptions{ConnectionId: 1, GithubId: 2})
+
+	// the jobs of a run are extracted one after the other, the run is loaded once for all of them
+	for i := 0; i < 3; i++ {
+		run, err := loader.load(456)
+		assert.Nil(t, err)
+		assert.Equal(t, "push", run.Event)
+	}
+	// the run was not collected
+	run, err := loader.load(789)
+	assert.Nil(t, err)
+	assert.Nil(t, run)
+	run, err = loader.load(789)
+	assert.Nil(t, err)
+	assert.Nil(t, run)
+	mockDal.AssertExpectations(t)
+}
+
+func TestCopyRunToJob(t *testing.T) {
+	run := &githubJobRun{ID: 456, Event: "pull_request", HeadSha: "run-sha", Name: "CI", DisplayTitle: "Fix the build",
+		URL: "https://api.github.com/repos/a/b/actions/runs/456"}
+
+	job := &models.GithubJob{RunID: 456, HeadSha: "job-sha", RunURL: "https://ghe.example.com/api/v3/repos/a/b/actions/runs/456"}
+	copyRunToJob(job, run)
+	assert.Equal(t, "pull_request", job.Event)
+	assert.Equal(t, "job-sha", job.HeadSha)
+	assert.Equal(t, "CI", job.RunName)
+	assert.Equal(t, "Fix the build", job.RunDisplayTitle)
+	assert.Equal(t, "https://ghe.example.com/api/v3/repos/a/b/actions/runs/456", job.RunURL)
+
+	job = &models.GithubJob{RunID: 456}
+	copyRunToJob(job, run)
+	assert.Equal(t, "run-sha", job.HeadSha)
+	assert.Equal(t, "https://api.github.com/repos/a/b/actions/runs/456", job.RunURL)
+
+	// the run was not collected
+	job = &models.GithubJob{RunID: 789, HeadSha: "job-sha"}
+	copyRunToJob(job, nil)
+	assert.Empty(t, job.Event)
+	assert.Empty(t, job.RunName)
+	assert.Equal(t, "job-sha", job.HeadSha)
+}
+
+func TestParseJobMatrix(t *testing.T) {
+	testCases := []struct {
+		name   string
+		expect string
+	}{
+		{name: "build (ubuntu-latest, 1.20)", expect: `["ubuntu-latest", "1.20"]`},
+		{name: "test (windows-latest)", expect: `["windows-latest"]`},
+		{name: "Run tests / e2e (mysql, 8.0, true)", expect: `["mysql", "8.0", "true"]`},
+		{name: "build", expect: ""},
+		{name: "(ubuntu-latest)", expect: ""},
+		{name: "build ()", expect: ""},
+		{name: "deploy (prod) now", expect: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matrix := parseJobMatrix(tc.name)
+			if tc.expect == "" {
+				assert.Nil(t, matrix)
+			} else {
+				assert.JSONEq(t, tc.expect, string(matrix))
+			}
+		})
+	}
+
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	raw := json.RawMessage(`{"id": 123, "run_id": 456, "name": "build (ubuntu-latest, 1.20)"}`)
+	job, err := extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	assert.Nil(t, job.Matrix)
+	data.Options.ParseJobMatrix = true
+	job, err = extractJob(data, 2, raw)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `["ubuntu-latest", "1.20"]`, string(job.Matrix))
+}
+
+func TestExtractJobNoRunnerAvailable(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	testCases := []struct {
+		name     string
+		raw      string
+		expected bool
+	}{
+		{
+			name: "cancelled while waiting for a runner",
+			raw: `{"id": 1, "status": "completed", "conclusion": "cancelled", "labels": ["self-hosted", "gpu"],
+				"created_at": "2024-03-01T10:00:00Z", "started_at": "2024-03-02T10:00:00Z",
+				"completed_at": "2024-03-02T10:00:00Z", "runner_id": null, "runner_name": null}`,
+			expected: true,
+		},
+		{
+			name: "cancelled while running",
+			raw: `{"id": 2, "status": "completed", "conclusion": "cancelled", "labels": ["self-hosted", "gpu"],
+				"created_at": "2024-03-01T10:00:00Z", "started_at": "2024-03-01T10:01:00Z",
+				"completed_at": "2024-03-01T10:05:00Z", "runner_id": 7, "runner_name": "gpu-1"}`,
+			expected: false,
+		},
+		{
+			name: "cancelled before it was queued",
+			raw: `{"id": 3, "status": "completed", "conclusion": "cancelled", "created_at": null,
+				"started_at": "2024-03-01T10:00:00Z", "runner_id": 0}`,
+			expected: false,
+		},
+		{
+			name: "failed without a runner",
+			raw: `{"id": 4, "status": "completed", "conclusion": "failure",
+				"created_at": "2024-03-01T10:00:00Z", "started_at": "2024-03-01T10:00:00Z", "runner_id": 0}`,
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := extractJob(data, 2, json.RawMessage(tc.raw))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, job.NoRunnerAvailable)
+		})
+	}
+}
+
+func TestExtractJobLongMatrixName(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	name := "build (" + strings.Repeat("ubuntu-latest, ", 12) + strings.Repeat("x", 12) + ")"
+	assert.Len(t, name, 200)
+	raw := fmt.Sprintf(`{"id": 1, "run_id": 2, "status": "completed", "conclusion": "success", "name": %q}`, name)
+	job, err := extractJob(data, 2, json.RawMessage(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, name, job.Name)
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, StatusSuccess, job.Conclusion)
+}
+
+func TestExtractJobWorkflowName(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	job, err := extractJob(data, 2, json.RawMessage(`{"id": 1, "run_id": 2, "name": "build", "workflow_name": "CI"}`))
+	assert.Nil(t, err)
+	if assert.NotNil(t, job.WorkflowName) {
+		assert.Equal(t, "CI", *job.WorkflowName)
+	}
+
+	// older GitHub Enterprise Servers don't return it
+	job, err = extractJob(data, 2, json.RawMessage(`{"id": 1, "run_id": 2, "name": "build"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, job.WorkflowName)
+}
+
+func TestExtractJobsOfLargeRunInBatches(t *testing.T) {
+	data := &GithubTaskData{
+		Options:       &GithubOptions{ConnectionId: 1},
+		RegexEnricher: api.NewRegexEnricher(),
+	}
+	assert.Nil(t, ValidateTaskOptions(data.Options))
+	assert.Equal(t, DEFAULT_JOBS_EXTRACT_BATCH_SIZE, data.Options.JobsExtractBatchSize)
+
+	jobType := reflect.TypeOf(models.GithubJob{})
+	primaryKey := []reflect.StructField{}
+	for _, name := range []string{"ConnectionId", "RepoId", "ID"} {
+		field, _ := jobType.FieldByName(name)
+		primaryKey = append(primaryKey, field)
+	}
+	extract := func(batchSize int) []int {
+		var batches []int
+		var zeroTimes int
+		mockDal := new(mockdal.Dal)
+		mockDal.On("GetPrimaryKeyFields", mock.Anything).Return(primaryKey)
+		mockDal.On("CreateOrUpdate", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			jobs := args.Get(0).([]*models.GithubJob)
+			batches = append(batches, len(jobs))
+			for _, job := range jobs {
+				if job.StartedAt != nil && job.StartedAt.IsZero() {
+					zeroTimes++
+				}
+			}
+		})
+		mockRes := new(mockcontext.BasicRes)
+		mockRes.On("GetDal").Return(mockDal)
+		mockRes.On("GetLogger").Return(unithelper.DummyLogger())
+
+		batch, err := api.NewBatchSave(mockRes, reflect.TypeOf(&models.GithubJob{}), batchSize)
+		assert.Nil(t, err)
+		// a run with 500 jobs, the ones which didn't start have a zero started_at
+		for i := 1; i <= 500; i++ {
+			startedAt := "2024-03-01T10:00:00Z"
+			if i%2 == 0 {
+				startedAt = "0001-01-01T00:00:00Z"
+			}
+			job, err := extractJob(data, 2, json.RawMessage(fmt.Sprintf(
+				`{"id": %d, "run_id": 1, "name": "shard %d", "status": "queued", "started_at": %q}`, i, i, startedAt)))
+			assert.Nil(t, err)
+			assert.Nil(t, batch.Add(job))
+		}
+		assert.Nil(t, batch.Close())
+		// the zero times are normalized row by row within the batches
+		assert.Equal(t, 0, zeroTimes)
+		return batches
+	}
+
+	// a single round trip to the database with the default batch size, instead of one per job
+	assert.Equal(t, []int{500}, extract(data.Options.JobsExtractBatchSize))
+	assert.Equal(t, []int{200, 200, 100}, extract(200))
+
+	data.Options.JobsExtractBatchSize = MAX_JOBS_EXTRACT_BATCH_SIZE + 1
+	assert.NotNil(t, ValidateTaskOptions(data.Options))
+}
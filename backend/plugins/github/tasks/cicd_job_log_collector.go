@@ -0,0 +1,183 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectJobLogsMeta)
+}
+
+// DEFAULT_JOB_LOG_MAX_BYTES is the default size of the log tail kept for a failed job
+const DEFAULT_JOB_LOG_MAX_BYTES = 16 * 1024
+
+// MAX_JOB_LOG_MAX_BYTES is the most a TEXT column can hold
+const MAX_JOB_LOG_MAX_BYTES = 65535
+
+var CollectJobLogsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Job Logs",
+	EntryPoint:       CollectJobLogs,
+	EnabledByDefault: false,
+	Description:      "Collect the log tails of the failed jobs from Github action api into github_job_logs",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubJob{}.TableName()},
+	ProductTables:    []string{models.GithubJobLog{}.TableName()},
+	SkipOnFail:       true,
+}
+
+func CollectJobLogs(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	logger := taskCtx.GetLogger()
+
+	// logs never change once the job failed, only collect the jobs without a log
+	var jobs []*models.GithubJob
+	err := db.All(
+		&jobs,
+		dal.Select("j.id, j.run_id"),
+		dal.From("_tool_github_jobs j"),
+		dal.Join(`LEFT JOIN _tool_github_job_logs l
+			ON l.connection_id = j.connection_id AND l.repo_id = j.repo_id AND l.job_id = j.id`),
+		dal.Where(
+			"j.repo_id = ? AND j.connection_id = ? AND j.conclusion = ? AND l.job_id IS NULL",
+			data.Options.GithubId, data.Options.ConnectionId, "FAILURE",
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	maxBytes := data.Options.JobLogMaxBytes
+	if maxBytes < 1 || maxBytes > MAX_JOB_LOG_MAX_BYTES {
+		maxBytes = DEFAULT_JOB_LOG_MAX_BYTES
+	}
+	taskCtx.SetProgress(0, len(jobs))
+	for _, job := range jobs {
+		job := job
+		data.ApiClient.SubmitBlocking(func() errors.Error {
+			path := actionsApiPath(data, fmt.Sprintf("repos/%s/actions/jobs/%d/logs", data.Options.Name, job.ID))
+			jobLog, err := downloadJobLog(taskCtx.GetContext(), data.ApiClient.ApiClient, path, maxBytes)
+			if err != nil {
+				return err
+			}
+			if jobLog == nil {
+				logger.Warn(nil, "log of job %d is not available, it might have expired. Skipping...", job.ID)
+			} else {
+				jobLog.ConnectionId = data.Options.ConnectionId
+				jobLog.RepoId = data.Options.GithubId
+				jobLog.JobId = job.ID
+				jobLog.RunId = job.RunID
+				err = db.CreateOrUpdate(jobLog)
+				if err != nil {
+					return err
+				}
+			}
+			taskCtx.IncProgress(1)
+			return nil
+		})
+	}
+	return data.ApiClient.WaitAsync()
+}
+
+// downloadJobLog requests the log of a job and keeps the last maxBytes of it. The logs endpoint of
+// github.com redirects to a short-lived download url, which is followed through the proxy of the connection
+// without the credentials, see newDownloadClient, while some GitHub Enterprise Server versions respond with
+// the log directly. Nil is returned when the log doesn't exist anymore
+func downloadJobLog(ctx context.Context, apiClient *api.ApiClient, path string, maxBytes int) (*models.GithubJobLog, errors.Error) {
+	logUrl, err := api.GetURIStringPointer(apiClient.GetEndpoint(), path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, e := http.NewRequestWithContext(ctx, http.MethodGet, *logUrl, nil)
+	if e != nil {
+		return nil, errors.Convert(e)
+	}
+	for name, value := range apiClient.GetHeaders() {
+		req.Header.Set(name, value)
+	}
+	if authFunc := apiClient.GetAuthFunction(); authFunc != nil {
+		err = authFunc(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res, e := newDownloadClient(apiClient).Do(req)
+	if e != nil {
+		return nil, errors.Default.Wrap(e, fmt.Sprintf("failed to download the log of %s", *logUrl))
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return readJobLogTail(res.Body, *logUrl, maxBytes)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, nil
+	default:
+		return nil, errors.HttpStatus(res.StatusCode).New(fmt.Sprintf("unexpected status %d requesting %s", res.StatusCode, *logUrl))
+	}
+}
+
+// readJobLogTail streams the log and only keeps the last maxBytes in memory
+func readJobLogTail(body io.Reader, logUrl string, maxBytes int) (*models.GithubJobLog, errors.Error) {
+	buf := make([]byte, 0, 2*maxBytes)
+	chunk := make([]byte, 32*1024)
+	size := int64(0)
+	for {
+		n, e := body.Read(chunk)
+		if n > 0 {
+			size += int64(n)
+			buf = append(buf, chunk[:n]...)
+			if len(buf) > 2*maxBytes {
+				buf = append(buf[:0], buf[len(buf)-maxBytes:]...)
+			}
+		}
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, errors.Default.Wrap(e, fmt.Sprintf("failed to read the log of %s", logUrl))
+		}
+	}
+	if len(buf) > maxBytes {
+		buf = buf[len(buf)-maxBytes:]
+	}
+	return &models.GithubJobLog{
+		LogUrl: logUrl,
+		// the cut might split a multi-byte character
+		LogTail:     strings.ToValidUTF8(string(buf), ""),
+		LogSize:     size,
+		Truncated:   size > int64(maxBytes),
+		CollectedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,86 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadJobLogTail(t *testing.T) {
+	jobLog, err := readJobLogTail(strings.NewReader("short log"), "logs", 16)
+	assert.Nil(t, err)
+	assert.Equal(t, "short log", jobLog.LogTail)
+	assert.Equal(t, int64(9), jobLog.LogSize)
+	assert.False(t, jobLog.Truncated)
+
+	log := strings.Repeat("a", 100*1024) + "the end"
+	jobLog, err = readJobLogTail(strings.NewReader(log), "logs", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "aaathe end", jobLog.LogTail)
+	assert.Equal(t, int64(len(log)), jobLog.LogSize)
+	assert.True(t, jobLog.Truncated)
+}
+
+func TestDownloadJobLogFollowsRedirectWithoutCredentials(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		// the blob storage may redirect again to the blob itself
+		if r.URL.Path == "/signed" {
+			http.Redirect(w, r, "/blob", http.StatusTemporaryRedirect)
+			return
+		}
+		_, _ = w.Write([]byte("##[error]Process completed with exit code 1."))
+	}))
+	defer storage.Close()
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/a/b/actions/jobs/1/logs":
+			http.Redirect(w, r, storage.URL+"/signed", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+
+	apiClient := &api.ApiClient{}
+	apiClient.Setup(github.URL, nil, 0)
+	apiClient.SetAuthFunction(func(req *http.Request) errors.Error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+
+	jobLog, err := downloadJobLog(context.Background(), apiClient, "repos/a/b/actions/jobs/1/logs", 1024)
+	assert.Nil(t, err)
+	if assert.NotNil(t, jobLog) {
+		assert.Equal(t, "##[error]Process completed with exit code 1.", jobLog.LogTail)
+		assert.Equal(t, github.URL+"/repos/a/b/actions/jobs/1/logs", jobLog.LogUrl)
+	}
+
+	jobLog, err = downloadJobLog(context.Background(), apiClient, "repos/a/b/actions/jobs/2/logs", 1024)
+	assert.Nil(t, err)
+	assert.Nil(t, jobLog)
+}
@@ -0,0 +1,134 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertJobStepsMeta)
+}
+
+// RAW_JOB_STEP_TABLE owns the domain records converted from the job steps, which are extracted from RAW_JOB_TABLE,
+// so converting jobs and converting steps don't delete the records of each other
+const RAW_JOB_STEP_TABLE = "github_api_job_steps"
+
+var ConvertJobStepsMeta = plugin.SubTaskMeta{
+	Name:             "Convert Job Steps",
+	EntryPoint:       ConvertJobSteps,
+	EnabledByDefault: false,
+	Description:      "Convert tool layer table github_job_steps into domain layer table cicd_tasks",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubJobStep{}.TableName(), // cursor and generator
+		models.GithubJob{}.TableName(),     // job name
+		models.GithubRun{}.TableName(),     // id generator
+	},
+	ProductTables: []string{devops.CICDTask{}.TableName()},
+}
+
+type githubJobStepWithJob struct {
+	models.GithubJobStep
+	JobName        string
+	JobEnvironment string
+}
+
+func ConvertJobSteps(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	cursor, err := db.Cursor(
+		dal.Select("s.*, j.name AS job_name, j.environment AS job_environment"),
+		dal.From("_tool_github_job_steps s"),
+		dal.Join(`LEFT JOIN _tool_github_jobs j
+			ON j.connection_id = s.connection_id AND j.repo_id = s.repo_id AND j.id = s.job_id`),
+		dal.Where("s.repo_id = ? AND s.connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	stepIdGen := didgen.NewDomainIdGenerator(&models.GithubJobStep{})
+	runIdGen := didgen.NewDomainIdGenerator(&models.GithubRun{})
+	repoIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
+	var converter *api.DataConverter
+	converter, err = api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_JOB_STEP_TABLE,
+		},
+		InputRowType: reflect.TypeOf(githubJobStepWithJob{}),
+		Input:        cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			line := inputRow.(*githubJobStepWithJob)
+			// the origin is copied to the results, keep the id of the job raw data but mark the step converter as owner
+			line.RawDataTable = converter.GetTable()
+			line.RawDataParams = converter.GetParams()
+
+			// steps which never ran have no started_at, just like jobs
+			if line.StartedAt == nil {
+				return nil, nil
+			}
+			domainStep := &devops.CICDTask{
+				DomainEntity: domainlayer.DomainEntity{
+					Id: stepIdGen.Generate(data.Options.ConnectionId, line.RepoId, line.JobId, line.Number),
+				},
+				Name: fmt.Sprintf("%s / %s", line.JobName, line.Name),
+				TaskDatesInfo: devops.TaskDatesInfo{
+					CreatedDate:  *line.StartedAt,
+					StartedDate:  line.StartedAt,
+					FinishedDate: line.CompletedAt,
+				},
+				PipelineId:     runIdGen.Generate(data.Options.ConnectionId, line.RepoId, line.RunId),
+				CicdScopeId:    repoIdGen.Generate(data.Options.ConnectionId, line.RepoId),
+				Type:           data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, line.Name),
+				Environment:    line.JobEnvironment,
+				Result:         getJobResult(line.Conclusion),
+				OriginalResult: line.Conclusion,
+				Status:         devops.GetStatus(jobStatusRule, line.Status),
+				OriginalStatus: line.Status,
+			}
+			if line.CompletedAt != nil && !line.CompletedAt.Before(*line.StartedAt) {
+				domainStep.DurationSec = float64(line.CompletedAt.Sub(*line.StartedAt).Milliseconds() / 1e3)
+			}
+			return []interface{}{
+				domainStep,
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
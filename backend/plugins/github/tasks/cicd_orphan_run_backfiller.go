@@ -0,0 +1,152 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&BackfillOrphanRunsMeta)
+}
+
+const RAW_ORPHAN_RUN_TABLE = "github_api_orphan_runs"
+
+var BackfillOrphanRunsMeta = plugin.SubTaskMeta{
+	Name:             "Backfill Orphan Runs",
+	EntryPoint:       BackfillOrphanRuns,
+	EnabledByDefault: true,
+	Description:      "Collect the runs referenced by the jobs but missing in github_runs from Github action api, one by one",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubJob{}.TableName(), // cursor
+		models.GithubRun{}.TableName(), // cursor
+	},
+	ProductTables: []string{RAW_ORPHAN_RUN_TABLE},
+}
+
+// orphanRunCounts counts the outcome of the requests of the orphan runs, the responses are handled concurrently
+type orphanRunCounts struct {
+	backfilled int64
+	deleted    int64
+}
+
+// afterResponse counts the orphan runs found on Github as backfilled, and the ones answered with a 404 as deleted,
+// the latter are skipped just like the other collectors of runs do
+func (c *orphanRunCounts) afterResponse(logger log.Logger) plugin.ApiClientAfterResponse {
+	return func(res *http.Response) errors.Error {
+		if res.StatusCode == http.StatusUnauthorized {
+			return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+		}
+		if res.StatusCode == http.StatusNotFound {
+			atomic.AddInt64(&c.deleted, 1)
+			logger.Warn(nil, "GitHub run not found (404) at %s, likely deleted. Skipping...", res.Request.URL.Path)
+			return api.ErrIgnoreAndContinue
+		}
+		if res.StatusCode < http.StatusBadRequest {
+			atomic.AddInt64(&c.backfilled, 1)
+		}
+		return nil
+	}
+}
+
+// BackfillOrphanRuns fetches the runs which are referenced by the run_id of github_jobs but missing in github_runs,
+// i.e. the ones which fell out of the window of CollectRuns while their jobs were collected, and extracts them into
+// github_runs so the jobs can be joined with their runs
+func BackfillOrphanRuns(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	logger := taskCtx.GetLogger()
+	rawDataSubTaskArgs := api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_ORPHAN_RUN_TABLE,
+	}
+
+	// the raw runs are kept in incremental mode, so the runs backfilled by the previous collections are extracted again
+	apiCollector, err := api.NewStatefulApiCollector(rawDataSubTaskArgs)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := db.Cursor(
+		dal.Select("DISTINCT j.run_id AS id"),
+		dal.From("_tool_github_jobs j"),
+		dal.Join(`LEFT JOIN _tool_github_runs r
+			ON r.connection_id = j.connection_id AND r.repo_id = j.repo_id AND r.id = j.run_id`),
+		dal.Where("j.repo_id = ? AND j.connection_id = ? AND r.id IS NULL", data.Options.GithubId, data.Options.ConnectionId),
+	)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+	if err != nil {
+		return err
+	}
+
+	counts := &orphanRunCounts{}
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:      data.ApiClient,
+		Input:          iterator,
+		UrlTemplate:    actionsApiPath(data, "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}"),
+		ResponseParser: api.GetRawMessageDirectFromResponse,
+		AfterResponse:  counts.afterResponse(logger),
+	})
+	if err != nil {
+		return err
+	}
+	err = apiCollector.Execute()
+	if err != nil {
+		return err
+	}
+	logger.Info("backfilled %d orphan runs, %d orphan runs were confirmed deleted", counts.backfilled, counts.deleted)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: rawDataSubTaskArgs,
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			githubRun, err := extractRun(data, data.Options.GithubId, row.Data)
+			if err != nil {
+				return nil, err
+			}
+			runPullRequests, err := extractRunPullRequests(githubRun, row.Data)
+			if err != nil {
+				return nil, err
+			}
+			return append([]interface{}{githubRun}, runPullRequests...), nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return extractor.Execute()
+}
@@ -0,0 +1,61 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphanRunCounts(t *testing.T) {
+	counts := &orphanRunCounts{}
+	afterResponse := counts.afterResponse(unithelper.DummyLogger())
+	respond := func(statusCode int) error {
+		return afterResponse(&http.Response{
+			StatusCode: statusCode,
+			Request:    &http.Request{URL: &url.URL{Path: "/repos/apache/devlake/actions/runs/1"}},
+		})
+	}
+
+	assert.Nil(t, respond(http.StatusOK))
+	assert.Nil(t, respond(http.StatusOK))
+	assert.Equal(t, api.ErrIgnoreAndContinue, respond(http.StatusNotFound))
+	// server errors are retried by the collector and are neither backfilled nor deleted
+	assert.Nil(t, respond(http.StatusBadGateway))
+	assert.NotNil(t, respond(http.StatusUnauthorized))
+
+	assert.Equal(t, int64(2), counts.backfilled)
+	assert.Equal(t, int64(1), counts.deleted)
+}
+
+func TestExtractRun(t *testing.T) {
+	data := &GithubTaskData{Options: &GithubOptions{ConnectionId: 1}, RegexEnricher: api.NewRegexEnricher()}
+	run, err := extractRun(data, 2, []byte(`{"id": 456, "name": "build", "head_branch": "main",
+		"created_at": "2024-03-01T00:00:00Z", "run_started_at": "0001-01-01T00:00:00Z"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 456, run.ID)
+	assert.Equal(t, 2, run.RepoId)
+	assert.Equal(t, uint64(1), run.ConnectionId)
+	assert.NotNil(t, run.GithubCreatedAt)
+	assert.Nil(t, run.RunStartedAt)
+}
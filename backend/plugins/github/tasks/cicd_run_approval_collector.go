@@ -0,0 +1,124 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectRunApprovalsMeta)
+}
+
+const RAW_RUN_APPROVAL_TABLE = "github_api_run_approvals"
+
+var CollectRunApprovalsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Run Approvals",
+	EntryPoint:       CollectRunApprovals,
+	EnabledByDefault: false,
+	Description:      "Collect the reviews of the deployments of workflow runs from Github action api, supports both timeFilter and diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubRun{}.TableName()},
+	ProductTables:    []string{RAW_RUN_APPROVAL_TABLE},
+	SkipOnFail:       true,
+}
+
+// CollectRunApprovals collects the approvals of the completed runs, the response is empty for the runs which didn't
+// deploy to an environment protected by required reviewers
+func CollectRunApprovals(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	logger := taskCtx.GetLogger()
+
+	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_RUN_APPROVAL_TABLE,
+	})
+	if err != nil {
+		return err
+	}
+
+	clauses := []dal.Clause{
+		dal.Select("id"),
+		dal.From(&models.GithubRun{}),
+		dal.Where(
+			// the runs keep the status of the api, which is lowercase
+			"repo_id = ? AND connection_id = ? AND status = ?",
+			data.Options.GithubId, data.Options.ConnectionId, strings.ToLower(StatusCompleted),
+		),
+	}
+	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
+		clauses = append(clauses, dal.Where("github_updated_at > ?", apiCollector.GetSince()))
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:      data.ApiClient,
+		Input:          iterator,
+		UrlTemplate:    actionsApiPath(data, "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}/approvals"),
+		ResponseParser: api.GetRawMessageArrayFromResponse,
+		AfterResponse: func(res *http.Response) errors.Error {
+			if res.StatusCode == http.StatusUnauthorized {
+				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+			}
+			// Handle 404 errors gracefully (run might have been deleted)
+			if res.StatusCode == http.StatusNotFound {
+				logger.Warn(nil, "GitHub run not found (404) at %s, likely deleted. Skipping...", res.Request.URL.Path)
+				return api.ErrIgnoreAndContinue
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.Execute()
+	if err != nil {
+		// a run still failing on the server side after the retries is skipped, just like CollectJobs does
+		runErr := &api.CollectorRunError{}
+		if !errors.As(err, &runErr) {
+			return err
+		}
+		logger.Warn(nil, "failed to collect the approvals of run %d (status %d) at %s: %s",
+			runErr.RunID, runErr.StatusCode, runErr.URL, runErr.Error())
+	}
+	return nil
+}
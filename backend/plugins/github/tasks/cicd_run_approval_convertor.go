@@ -0,0 +1,127 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertRunApprovalsMeta)
+}
+
+var ConvertRunApprovalsMeta = plugin.SubTaskMeta{
+	Name:             "Convert Run Approvals",
+	EntryPoint:       ConvertRunApprovals,
+	EnabledByDefault: false,
+	Description:      "Convert tool layer table github_run_approvals into domain layer table cicd_tasks",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		RAW_RUN_APPROVAL_TABLE,
+		models.GithubRunApproval{}.TableName(), // cursor and generator
+		models.GithubRun{}.TableName(),         // id generator
+	},
+	ProductTables: []string{devops.CICDTask{}.TableName()},
+}
+
+// runApprovalTaskType is the type of the tasks standing for the wait of a run for an approval, it is neither a
+// DEPLOYMENT nor a BUILD, so the approvals don't count as deployments nor weigh on the duration of the builds
+const runApprovalTaskType = "APPROVAL"
+
+func ConvertRunApprovals(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	cursor, err := db.Cursor(
+		dal.From(&models.GithubRunApproval{}),
+		dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUN_APPROVAL_TABLE,
+		},
+		InputRowType: reflect.TypeOf(models.GithubRunApproval{}),
+		Input:        cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			return []interface{}{convertRunApproval(inputRow.(*models.GithubRunApproval))}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+// convertRunApproval turns the approval into a task of the pipeline of the run, lasting from when the run started
+// waiting for it until it was given
+func convertRunApproval(approval *models.GithubRunApproval) *devops.CICDTask {
+	approvalIdGen := didgen.NewDomainIdGenerator(&models.GithubRunApproval{})
+	runIdGen := didgen.NewDomainIdGenerator(&models.GithubRun{})
+	repoIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
+	task := &devops.CICDTask{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: approvalIdGen.Generate(approval.ConnectionId, approval.RepoId, approval.RunId, approval.EnvironmentId),
+		},
+		Name:           "approval of " + approval.Environment,
+		PipelineId:     runIdGen.Generate(approval.ConnectionId, approval.RepoId, int(approval.RunId)),
+		CicdScopeId:    repoIdGen.Generate(approval.ConnectionId, approval.RepoId),
+		Type:           runApprovalTaskType,
+		Status:         devops.STATUS_DONE,
+		OriginalStatus: approval.State,
+		Result:         devops.RESULT_DEFAULT,
+		OriginalResult: approval.State,
+		TaskDatesInfo: devops.TaskDatesInfo{
+			StartedDate:  approval.WaitStartedAt,
+			FinishedDate: approval.ApprovedAt,
+		},
+	}
+	switch strings.ToLower(approval.State) {
+	case "approved":
+		task.Result = devops.RESULT_SUCCESS
+	case "rejected":
+		task.Result = devops.RESULT_FAILURE
+	}
+	if approval.WaitStartedAt != nil {
+		task.CreatedDate = *approval.WaitStartedAt
+	}
+	if approval.WaitStartedAt != nil && approval.ApprovedAt != nil {
+		task.DurationSec = float64(approval.ApprovedAt.Sub(*approval.WaitStartedAt).Milliseconds() / 1e3)
+	}
+	return task
+}
@@ -0,0 +1,163 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractRunApprovalsMeta)
+}
+
+var ExtractRunApprovalsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Run Approvals",
+	EntryPoint:       ExtractRunApprovals,
+	EnabledByDefault: false,
+	Description:      "Extract raw run approvals data into tool layer table github_run_approvals",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		RAW_RUN_APPROVAL_TABLE,
+		models.GithubJob{}.TableName(), // wait of the approval
+	},
+	ProductTables: []string{models.GithubRunApproval{}.TableName()},
+}
+
+type githubRawRunApproval struct {
+	Environments []struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"environments"`
+	State string `json:"state"`
+	User  *struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Comment string `json:"comment"`
+}
+
+type githubJobWait struct {
+	GithubCreatedAt *time.Time
+	StartedAt       *time.Time
+}
+
+func ExtractRunApprovals(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUN_APPROVAL_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			run := &SimpleGithubRun{}
+			err := errors.Convert(json.Unmarshal(row.Input, run))
+			if err != nil {
+				return nil, err
+			}
+			var jobs []githubJobWait
+			err = db.All(&jobs,
+				dal.Select("github_created_at, started_at"),
+				dal.From(&models.GithubJob{}),
+				dal.Where("repo_id = ? AND connection_id = ? AND run_id = ?",
+					data.Options.GithubId, data.Options.ConnectionId, run.ID),
+			)
+			if err != nil {
+				return nil, err
+			}
+			waitStartedAt, approvedAt := runApprovalWait(jobs)
+			approvals, err := extractRunApprovals(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]interface{}, 0, len(approvals))
+			for _, approval := range approvals {
+				approval.ConnectionId = data.Options.ConnectionId
+				approval.RepoId = data.Options.GithubId
+				approval.RunId = run.ID
+				if approval.State == "approved" {
+					// the jobs gated by a rejected deployment never start, so their wait is unknown
+					approval.WaitStartedAt = waitStartedAt
+					approval.ApprovedAt = approvedAt
+				}
+				results = append(results, approval)
+			}
+			return results, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractRunApprovals parses a review of the deployments of a run, it is split by environment since a single review
+// may approve several of them at once
+func extractRunApprovals(body json.RawMessage) ([]*models.GithubRunApproval, errors.Error) {
+	rawApproval := &githubRawRunApproval{}
+	err := errors.Convert(json.Unmarshal(body, rawApproval))
+	if err != nil {
+		return nil, err
+	}
+	approver := ""
+	if rawApproval.User != nil {
+		// the user is missing when the account was deleted
+		approver = rawApproval.User.Login
+	}
+	approvals := make([]*models.GithubRunApproval, 0, len(rawApproval.Environments))
+	for _, environment := range rawApproval.Environments {
+		approvals = append(approvals, &models.GithubRunApproval{
+			EnvironmentId: environment.Id,
+			Environment:   environment.Name,
+			State:         rawApproval.State,
+			Approver:      approver,
+			Comment:       rawApproval.Comment,
+		})
+	}
+	return approvals, nil
+}
+
+// runApprovalWait returns when the run started waiting for the approval and when it was approved. A job gated by
+// an environment is created when the run reaches it and only starts once the deployment is approved, so the job
+// which waited the longest to start is taken as the gated one. Both are nil when none of the jobs started
+func runApprovalWait(jobs []githubJobWait) (*time.Time, *time.Time) {
+	var waitStartedAt, approvedAt *time.Time
+	var longest time.Duration
+	for _, job := range jobs {
+		if job.GithubCreatedAt == nil || job.StartedAt == nil {
+			continue
+		}
+		wait := job.StartedAt.Sub(*job.GithubCreatedAt)
+		if waitStartedAt == nil || wait > longest {
+			waitStartedAt, approvedAt, longest = job.GithubCreatedAt, job.StartedAt, wait
+		}
+	}
+	return waitStartedAt, approvedAt
+}
@@ -0,0 +1,108 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	mockplugin "github.com/apache/incubator-devlake/mocks/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRunApprovals(t *testing.T) {
+	approvals, err := extractRunApprovals([]byte(`{
+		"environments": [{"id": 161088068, "name": "staging"}, {"id": 161088069, "name": "production"}],
+		"state": "approved",
+		"user": {"login": "octocat", "id": 1},
+		"comment": "Ship it!"
+	}`))
+	assert.Nil(t, err)
+	assert.Len(t, approvals, 2)
+	assert.Equal(t, &models.GithubRunApproval{
+		EnvironmentId: 161088069,
+		Environment:   "production",
+		State:         "approved",
+		Approver:      "octocat",
+		Comment:       "Ship it!",
+	}, approvals[1])
+
+	// the user of a deleted account is missing
+	approvals, err = extractRunApprovals([]byte(`{"environments": [{"id": 1, "name": "production"}], "state": "rejected"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "", approvals[0].Approver)
+	assert.Equal(t, "rejected", approvals[0].State)
+}
+
+func TestRunApprovalWait(t *testing.T) {
+	at := func(minutes int) *time.Time {
+		date := time.Date(2024, 3, 1, 10, minutes, 0, 0, time.UTC)
+		return &date
+	}
+	waitStartedAt, approvedAt := runApprovalWait([]githubJobWait{
+		{GithubCreatedAt: at(0), StartedAt: at(1)},
+		// gated by the environment
+		{GithubCreatedAt: at(5), StartedAt: at(45)},
+		{GithubCreatedAt: at(46), StartedAt: at(47)},
+		{GithubCreatedAt: at(48)},
+	})
+	assert.Equal(t, at(5), waitStartedAt)
+	assert.Equal(t, at(45), approvedAt)
+
+	// none of the jobs started
+	waitStartedAt, approvedAt = runApprovalWait([]githubJobWait{{GithubCreatedAt: at(0)}})
+	assert.Nil(t, waitStartedAt)
+	assert.Nil(t, approvedAt)
+}
+
+func TestConvertRunApproval(t *testing.T) {
+	mockMeta := mockplugin.NewPluginMeta(t)
+	mockMeta.On("RootPkgPath").Return("github.com/apache/incubator-devlake/plugins/github")
+	mockMeta.On("Name").Return("github").Maybe()
+	assert.NoError(t, plugin.RegisterPlugin("github", mockMeta))
+
+	waitStartedAt := time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC)
+	approvedAt := time.Date(2024, 3, 1, 10, 45, 0, 0, time.UTC)
+	task := convertRunApproval(&models.GithubRunApproval{
+		ConnectionId:  1,
+		RepoId:        2,
+		RunId:         3,
+		EnvironmentId: 4,
+		Environment:   "production",
+		State:         "approved",
+		WaitStartedAt: &waitStartedAt,
+		ApprovedAt:    &approvedAt,
+	})
+	assert.Equal(t, "github:GithubRunApproval:1:2:3:4", task.Id)
+	assert.Equal(t, "github:GithubRun:1:2:3", task.PipelineId)
+	assert.Equal(t, "approval of production", task.Name)
+	assert.Equal(t, runApprovalTaskType, task.Type)
+	assert.Equal(t, devops.RESULT_SUCCESS, task.Result)
+	assert.Equal(t, devops.STATUS_DONE, task.Status)
+	assert.Equal(t, float64(2400), task.DurationSec)
+	assert.Equal(t, waitStartedAt, task.CreatedDate)
+
+	// the wait of a rejected deployment is unknown
+	task = convertRunApproval(&models.GithubRunApproval{ConnectionId: 1, RepoId: 2, RunId: 3, EnvironmentId: 4, State: "rejected"})
+	assert.Equal(t, devops.RESULT_FAILURE, task.Result)
+	assert.Nil(t, task.StartedDate)
+	assert.Equal(t, float64(0), task.DurationSec)
+}
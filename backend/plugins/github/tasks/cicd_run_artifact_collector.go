@@ -0,0 +1,143 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectRunArtifactsMeta)
+}
+
+const RAW_RUN_ARTIFACT_TABLE = "github_api_run_artifacts"
+
+var CollectRunArtifactsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Run Artifacts",
+	EntryPoint:       CollectRunArtifacts,
+	EnabledByDefault: false,
+	Description:      "Collect the artifacts metadata of workflow runs from Github action api, supports both timeFilter and diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubRun{}.TableName()},
+	ProductTables:    []string{RAW_RUN_ARTIFACT_TABLE},
+	SkipOnFail:       true,
+}
+
+type githubRawRunArtifactsResult struct {
+	TotalCount int64             `json:"total_count"`
+	Artifacts  []json.RawMessage `json:"artifacts"`
+}
+
+func CollectRunArtifacts(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	logger := taskCtx.GetLogger()
+
+	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_RUN_ARTIFACT_TABLE,
+	})
+	if err != nil {
+		return err
+	}
+
+	clauses := []dal.Clause{
+		dal.Select("id"),
+		dal.From(&models.GithubRun{}),
+		dal.Where(
+			"repo_id = ? AND connection_id = ?",
+			data.Options.GithubId, data.Options.ConnectionId,
+		),
+	}
+	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
+		clauses = append(clauses, dal.Where("github_updated_at > ?", apiCollector.GetSince()))
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubRun{}))
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Input:       iterator,
+		UrlTemplate: actionsApiPath(data, "repos/{{ .Params.Name }}/actions/runs/{{ .Input.ID }}/artifacts"),
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			body := &githubRawRunArtifactsResult{}
+			err := api.UnmarshalResponse(res, body)
+			if err != nil {
+				return nil, err
+			}
+			return body.Artifacts, nil
+		},
+		AfterResponse: func(res *http.Response) errors.Error {
+			if res.StatusCode == http.StatusUnauthorized {
+				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+			}
+			return nil
+		},
+		// the run might have been deleted since it was collected
+		SkipInputOnStatus: []int{http.StatusNotFound},
+		OnInputSkipped: func(input interface{}, res *http.Response) {
+			logger.Warn(nil, "GitHub run not found (404) at %s, likely deleted. Skipping...", res.Request.URL.Path)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.Execute()
+	if err != nil {
+		// a run still failing on the server side after the retries is skipped, just like CollectJobs does
+		runErr := &api.CollectorRunError{}
+		if !errors.As(err, &runErr) {
+			return err
+		}
+		logger.Warn(nil, "failed to collect the artifacts of run %d (status %d) at %s: %s",
+			runErr.RunID, runErr.StatusCode, runErr.URL, runErr.Error())
+	}
+	return nil
+}
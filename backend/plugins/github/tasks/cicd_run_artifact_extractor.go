@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractRunArtifactsMeta)
+}
+
+var ExtractRunArtifactsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Run Artifacts",
+	EntryPoint:       ExtractRunArtifacts,
+	EnabledByDefault: false,
+	Description:      "Extract raw run artifacts data into tool layer table github_run_artifacts",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_RUN_ARTIFACT_TABLE},
+	ProductTables:    []string{models.GithubRunArtifact{}.TableName()},
+}
+
+func ExtractRunArtifacts(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUN_ARTIFACT_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			run := &SimpleGithubRun{}
+			err := errors.Convert(json.Unmarshal(row.Input, run))
+			if err != nil {
+				return nil, err
+			}
+			artifact, err := extractRunArtifact(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			artifact.ConnectionId = data.Options.ConnectionId
+			artifact.RepoId = data.Options.GithubId
+			artifact.RunId = run.ID
+			return []interface{}{artifact}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractRunArtifact parses an artifact of the artifacts api, the download url of an expired artifact only answers
+// with a 410 so it is not kept
+func extractRunArtifact(body json.RawMessage) (*models.GithubRunArtifact, errors.Error) {
+	artifact := &models.GithubRunArtifact{}
+	err := errors.Convert(json.Unmarshal(body, artifact))
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Expired {
+		artifact.ArchiveDownloadURL = ""
+	}
+	artifact.GithubCreatedAt = api.NormalizeNullableTime(artifact.GithubCreatedAt)
+	artifact.ExpiresAt = api.NormalizeNullableTime(artifact.ExpiresAt)
+	return artifact, nil
+}
@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRunArtifact(t *testing.T) {
+	artifact, err := extractRunArtifact([]byte(`{
+		"id": 11, "name": "coverage", "size_in_bytes": 556, "expired": false,
+		"archive_download_url": "https://api.github.com/repos/o/r/actions/artifacts/11/zip",
+		"created_at": "2024-03-01T10:00:00Z", "expires_at": "2024-05-30T10:00:00Z"
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), artifact.ID)
+	assert.Equal(t, "coverage", artifact.Name)
+	assert.Equal(t, int64(556), artifact.SizeInBytes)
+	assert.Equal(t, "https://api.github.com/repos/o/r/actions/artifacts/11/zip", artifact.ArchiveDownloadURL)
+	assert.Equal(t, "2024-03-01T10:00:00Z", artifact.GithubCreatedAt.UTC().Format("2006-01-02T15:04:05Z"))
+
+	// the download url of an expired artifact is gone
+	artifact, err = extractRunArtifact([]byte(`{
+		"id": 12, "name": "logs", "size_in_bytes": 1024, "expired": true,
+		"archive_download_url": "https://api.github.com/repos/o/r/actions/artifacts/12/zip",
+		"created_at": "2024-01-01T10:00:00Z", "expires_at": null
+	}`))
+	assert.Nil(t, err)
+	assert.True(t, artifact.Expired)
+	assert.Empty(t, artifact.ArchiveDownloadURL)
+	assert.Nil(t, artifact.ExpiresAt)
+}
@@ -52,13 +52,16 @@ var CollectRunsMeta = plugin.SubTaskMeta{
 	EnabledByDefault: true,
 	Description:      "Collect Runs data from Github action api, supports both timeFilter and diffSync.",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
-	DependencyTables: []string{},
+	DependencyTables: []string{ACTIONS_ACCESS_CHECK},
 	ProductTables:    []string{RAW_RUN_TABLE},
 	SkipOnFail:       true, // Allow other subtasks to continue if workflow run collection fails
 }
 
 func CollectRuns(taskCtx plugin.SubTaskContext) errors.Error {
 	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
 	log := taskCtx.GetLogger()
 	collector, err := helper.NewStatefulApiCollectorForFinalizableEntity(helper.FinalizableApiCollectorArgs{
 		RawDataSubTaskArgs: helper.RawDataSubTaskArgs{
@@ -74,7 +77,7 @@ func CollectRuns(taskCtx plugin.SubTaskContext) errors.Error {
 			PageSize:    PAGE_SIZE,
 			Concurrency: 10,
 			FinalizableApiCollectorCommonArgs: helper.FinalizableApiCollectorCommonArgs{
-				UrlTemplate: "repos/{{ .Params.Name }}/actions/runs",
+				UrlTemplate: actionsApiPath(data, "repos/{{ .Params.Name }}/actions/runs"),
 				Query: func(reqData *helper.RequestData, createdAfter *time.Time) (url.Values, errors.Error) {
 					query := url.Values{}
 					// GitHub API returns only the first 34 pages (with a size of 30) when specifying status=compleleted, try the following API request to verify the problem.
@@ -45,6 +45,7 @@ var ConvertRunsMeta = plugin.SubTaskMeta{
 		//models.GithubRepo{}.TableName(), // config will not regard as dependency
 		models.GithubRun{}.TableName(),
 		RAW_RUN_TABLE,
+		RAW_ORPHAN_RUN_TABLE, // runs backfilled after the jobs
 	},
 	ProductTables: []string{
 		devops.CICDPipeline{}.TableName(),
@@ -123,6 +124,12 @@ func ConvertRuns(taskCtx plugin.SubTaskContext) errors.Error {
 			if line.GithubUpdatedAt != nil && line.RunStartedAt != nil {
 				domainPipeline.DurationSec = float64(line.GithubUpdatedAt.Sub(*line.RunStartedAt).Milliseconds() / 1e3)
 			}
+			if line.RunAttempt <= 1 {
+				// run_started_at is the start of the latest attempt, so the queue delay of a re-run would include
+				// the duration of its previous attempts
+				domainPipeline.QueuedDate = line.GithubCreatedAt
+				domainPipeline.QueuedDurationSec = domainPipeline.CalculateQueueDuration()
+			}
 
 			domainPipelineCommit := &devops.CiCDPipelineCommit{
 				PipelineId: runIdGen.Generate(
@@ -38,7 +38,7 @@ var ExtractRunsMeta = plugin.SubTaskMeta{
 	Description:      "Extract raw run data into tool layer table github_runs",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
 	DependencyTables: []string{RAW_RUN_TABLE},
-	ProductTables:    []string{models.GithubRun{}.TableName()},
+	ProductTables:    []string{models.GithubRun{}.TableName(), models.GithubRunPullRequest{}.TableName()},
 }
 
 func ExtractRuns(taskCtx plugin.SubTaskContext) errors.Error {
@@ -55,28 +55,15 @@ func ExtractRuns(taskCtx plugin.SubTaskContext) errors.Error {
 			Table: RAW_RUN_TABLE,
 		},
 		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
-			githubRun := &models.GithubRun{}
-			err := errors.Convert(json.Unmarshal(row.Data, githubRun))
+			githubRun, err := extractRun(data, repoId, row.Data)
 			if err != nil {
 				return nil, err
 			}
-			
-			// Handle zero time values to avoid MySQL datetime errors
-			if githubRun.GithubCreatedAt != nil && (githubRun.GithubCreatedAt.IsZero() || githubRun.GithubCreatedAt.Year() == 0) {
-				githubRun.GithubCreatedAt = nil
-			}
-			if githubRun.GithubUpdatedAt != nil && (githubRun.GithubUpdatedAt.IsZero() || githubRun.GithubUpdatedAt.Year() == 0) {
-				githubRun.GithubUpdatedAt = nil
-			}
-			if githubRun.RunStartedAt != nil && (githubRun.RunStartedAt.IsZero() || githubRun.RunStartedAt.Year() == 0) {
-				githubRun.RunStartedAt = nil
+			runPullRequests, err := extractRunPullRequests(githubRun, row.Data)
+			if err != nil {
+				return nil, err
 			}
-			
-			githubRun.RepoId = repoId
-			githubRun.ConnectionId = data.Options.ConnectionId
-			githubRun.Type = data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, githubRun.Name)
-			githubRun.Environment = data.RegexEnricher.ReturnNameIfOmittedOrMatched(devops.PRODUCTION, githubRun.Name, githubRun.HeadBranch)
-			return []interface{}{githubRun}, nil
+			return append([]interface{}{githubRun}, runPullRequests...), nil
 		},
 	})
 
@@ -86,3 +73,76 @@ func ExtractRuns(taskCtx plugin.SubTaskContext) errors.Error {
 
 	return extractor.Execute()
 }
+
+// extractRun converts a raw run returned by the actions api into a GithubRun of the repo, it is shared by the
+// extractors of the runs listed by CollectRuns and the ones backfilled one by one
+func extractRun(data *GithubTaskData, repoId int, raw json.RawMessage) (*models.GithubRun, errors.Error) {
+	githubRun := &models.GithubRun{}
+	err := errors.Convert(json.Unmarshal(raw, githubRun))
+	if err != nil {
+		return nil, err
+	}
+	actor := &struct {
+		TriggeringActor struct {
+			Login string `json:"login"`
+		} `json:"triggering_actor"`
+	}{}
+	err = errors.Convert(json.Unmarshal(raw, actor))
+	if err != nil {
+		return nil, err
+	}
+	githubRun.TriggeringActor = actor.TriggeringActor.Login
+
+	// Handle zero time values to avoid MySQL datetime errors
+	if githubRun.GithubCreatedAt != nil && (githubRun.GithubCreatedAt.IsZero() || githubRun.GithubCreatedAt.Year() == 0) {
+		githubRun.GithubCreatedAt = nil
+	}
+	if githubRun.GithubUpdatedAt != nil && (githubRun.GithubUpdatedAt.IsZero() || githubRun.GithubUpdatedAt.Year() == 0) {
+		githubRun.GithubUpdatedAt = nil
+	}
+	if githubRun.RunStartedAt != nil && (githubRun.RunStartedAt.IsZero() || githubRun.RunStartedAt.Year() == 0) {
+		githubRun.RunStartedAt = nil
+	}
+
+	githubRun.RepoId = repoId
+	githubRun.ConnectionId = data.Options.ConnectionId
+	githubRun.Type = data.RegexEnricher.ReturnNameIfMatched(devops.DEPLOYMENT, githubRun.Name)
+	githubRun.Environment = data.RegexEnricher.ReturnNameIfOmittedOrMatched(devops.PRODUCTION, githubRun.Name, githubRun.HeadBranch)
+	return githubRun, nil
+}
+
+// extractRunPullRequests returns the links of the run to the pull requests it was triggered for, the array is
+// empty for the runs triggered by a push, and for the pull requests opened from a fork
+func extractRunPullRequests(githubRun *models.GithubRun, raw json.RawMessage) ([]interface{}, errors.Error) {
+	body := &struct {
+		PullRequests []struct {
+			ID     int `json:"id"`
+			Number int `json:"number"`
+			Head   struct {
+				Ref string `json:"ref"`
+				Sha string `json:"sha"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		} `json:"pull_requests"`
+	}{}
+	err := errors.Convert(json.Unmarshal(raw, body))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, 0, len(body.PullRequests))
+	for _, pr := range body.PullRequests {
+		results = append(results, &models.GithubRunPullRequest{
+			ConnectionId:      githubRun.ConnectionId,
+			RunId:             githubRun.ID,
+			PullRequestId:     pr.ID,
+			RepoId:            githubRun.RepoId,
+			PullRequestNumber: pr.Number,
+			HeadRef:           pr.Head.Ref,
+			HeadSha:           pr.Head.Sha,
+			BaseRef:           pr.Base.Ref,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRunPullRequests(t *testing.T) {
+	githubRun := &models.GithubRun{ConnectionId: 1, RepoId: 2, ID: 456}
+
+	// a run triggered by a pull request
+	raw := json.RawMessage(`{"id": 456, "event": "pull_request", "pull_requests": [{
+		"url": "https://api.github.com/repos/a/b/pulls/7", "id": 1001, "number": 7,
+		"head": {"ref": "feature", "sha": "abc", "repo": {"id": 2}},
+		"base": {"ref": "main", "sha": "def", "repo": {"id": 2}}
+	}]}`)
+	results, err := extractRunPullRequests(githubRun, raw)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{&models.GithubRunPullRequest{
+		ConnectionId:      1,
+		RunId:             456,
+		PullRequestId:     1001,
+		RepoId:            2,
+		PullRequestNumber: 7,
+		HeadRef:           "feature",
+		HeadSha:           "abc",
+		BaseRef:           "main",
+	}}, results)
+
+	// a run triggered by a push
+	results, err = extractRunPullRequests(githubRun, json.RawMessage(`{"id": 456, "event": "push", "pull_requests": []}`))
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+	results, err = extractRunPullRequests(githubRun, json.RawMessage(`{"id": 456, "event": "push"}`))
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+}
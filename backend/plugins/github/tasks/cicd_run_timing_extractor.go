@@ -0,0 +1,104 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractRunTimingMeta)
+}
+
+var ExtractRunTimingMeta = plugin.SubTaskMeta{
+	Name:             "Extract Run Timing",
+	EntryPoint:       ExtractRunTiming,
+	EnabledByDefault: false,
+	Description:      "Extract raw run timing data into tool layer table github_run_timings",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_RUN_TIMING_TABLE},
+	ProductTables:    []string{models.GithubRunTiming{}.TableName()},
+}
+
+type githubBillableTiming struct {
+	TotalMs int64 `json:"total_ms"`
+}
+
+type githubRawRunTiming struct {
+	Billable struct {
+		Ubuntu  githubBillableTiming `json:"UBUNTU"`
+		Windows githubBillableTiming `json:"WINDOWS"`
+		Macos   githubBillableTiming `json:"MACOS"`
+	} `json:"billable"`
+	RunDurationMs int64 `json:"run_duration_ms"`
+}
+
+func ExtractRunTiming(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUN_TIMING_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			run := &SimpleGithubRun{}
+			err := errors.Convert(json.Unmarshal(row.Input, run))
+			if err != nil {
+				return nil, err
+			}
+			timing, err := extractRunTiming(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			timing.ConnectionId = data.Options.ConnectionId
+			timing.RepoId = data.Options.GithubId
+			timing.RunId = run.ID
+			return []interface{}{timing}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractRunTiming parses the response of the timing api, OSes which were not used are missing in it
+func extractRunTiming(body json.RawMessage) (*models.GithubRunTiming, errors.Error) {
+	rawTiming := &githubRawRunTiming{}
+	err := errors.Convert(json.Unmarshal(body, rawTiming))
+	if err != nil {
+		return nil, err
+	}
+	return &models.GithubRunTiming{
+		UbuntuBillableMs:  rawTiming.Billable.Ubuntu.TotalMs,
+		WindowsBillableMs: rawTiming.Billable.Windows.TotalMs,
+		MacosBillableMs:   rawTiming.Billable.Macos.TotalMs,
+		RunDurationMs:     rawTiming.RunDurationMs,
+	}, nil
+}
@@ -0,0 +1,39 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRunTiming(t *testing.T) {
+	timing, err := extractRunTiming([]byte(`{
+		"billable": {
+			"UBUNTU": {"total_ms": 180000, "jobs": 1, "job_runs": [{"job_id": 1, "duration_ms": 180000}]},
+			"MACOS": {"total_ms": 240000, "jobs": 4}
+		},
+		"run_duration_ms": 500000
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(180000), timing.UbuntuBillableMs)
+	assert.Equal(t, int64(0), timing.WindowsBillableMs)
+	assert.Equal(t, int64(240000), timing.MacosBillableMs)
+	assert.Equal(t, int64(500000), timing.RunDurationMs)
+}
@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectRunnersMeta)
+}
+
+const RAW_RUNNER_TABLE = "github_api_runners"
+
+const (
+	RunnerScopeRepo = "repo"
+	RunnerScopeOrg  = "org"
+)
+
+var CollectRunnersMeta = plugin.SubTaskMeta{
+	Name:             "Collect Runners",
+	EntryPoint:       CollectRunners,
+	EnabledByDefault: false,
+	Description:      "Collect the self-hosted runners of the repo and of its organization from Github action api, does not support either timeFilter or diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{ACTIONS_ACCESS_CHECK},
+	ProductTables:    []string{RAW_RUNNER_TABLE},
+	SkipOnFail:       true,
+}
+
+// runnerScope is where the runners are listed, the repo or its organization
+type runnerScope struct {
+	Scope string
+	Path  string
+}
+
+// runnerScopes returns the repo and the organization owning it, the runners of a repo owned by a user are only
+// listed by the repo since the organization api answers with a 404
+func runnerScopes(repoName string) []*runnerScope {
+	scopes := []*runnerScope{{Scope: RunnerScopeRepo, Path: "repos/" + repoName}}
+	if owner, _, ok := strings.Cut(repoName, "/"); ok && owner != "" {
+		scopes = append(scopes, &runnerScope{Scope: RunnerScopeOrg, Path: "orgs/" + owner})
+	}
+	return scopes
+}
+
+func CollectRunners(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+	if skipActionsDisabled(taskCtx, data) {
+		return nil
+	}
+	logger := taskCtx.GetLogger()
+
+	iterator := api.NewQueueIterator()
+	for _, scope := range runnerScopes(data.Options.Name) {
+		iterator.Push(scope)
+	}
+
+	collector, err := api.NewApiCollector(api.ApiCollectorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUNNER_TABLE,
+		},
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Incremental: false,
+		Input:       iterator,
+		UrlTemplate: actionsApiPath(data, "{{ .Input.Path }}/actions/runners"),
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			body := &struct {
+				Runners []json.RawMessage `json:"runners"`
+			}{}
+			err := api.UnmarshalResponse(res, body)
+			if err != nil {
+				return nil, err
+			}
+			return body.Runners, nil
+		},
+		AfterResponse: func(res *http.Response) errors.Error {
+			return ignoreRunnersForbidden(logger, res)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return collector.Execute()
+}
+
+// ignoreRunnersForbidden skips the runners the token is not allowed to list, listing them requires the admin
+// access to the repo, or to the organization. A 403 of the rate limit is left to the retries
+func ignoreRunnersForbidden(logger log.Logger, res *http.Response) errors.Error {
+	if res.StatusCode == http.StatusForbidden && !isJobsRateLimited(res) {
+		logger.Warn(nil, "the token is not allowed to list the runners at %s, skipping them. Listing the runners "+
+			"requires the `repo` scope and the admin access to the repository, and the `admin:org` scope for the runners "+
+			"of the organization. Fine-grained tokens need the `Administration` permission of the repository, or the "+
+			"`Self-hosted runners` one of the organization", res.Request.URL.Path)
+		return api.ErrIgnoreAndContinue
+	}
+	return ignoreHTTPStatus404(res)
+}
@@ -0,0 +1,104 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"gorm.io/datatypes"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractRunnersMeta)
+}
+
+var ExtractRunnersMeta = plugin.SubTaskMeta{
+	Name:             "Extract Runners",
+	EntryPoint:       ExtractRunners,
+	EnabledByDefault: false,
+	Description:      "Extract raw runners data into tool layer table github_runners",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_RUNNER_TABLE},
+	ProductTables:    []string{models.GithubRunner{}.TableName()},
+}
+
+type githubRawRunner struct {
+	models.GithubRunner
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+func ExtractRunners(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_RUNNER_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			scope := &runnerScope{}
+			err := errors.Convert(json.Unmarshal(row.Input, scope))
+			if err != nil {
+				return nil, err
+			}
+			runner, err := extractRunner(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			runner.ConnectionId = data.Options.ConnectionId
+			runner.RepoId = data.Options.GithubId
+			runner.Scope = scope.Scope
+			return []interface{}{runner}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractRunner parses a runner of the runners api, only the names of its labels are kept
+func extractRunner(body json.RawMessage) (*models.GithubRunner, errors.Error) {
+	rawRunner := &githubRawRunner{}
+	err := errors.Convert(json.Unmarshal(body, rawRunner))
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(rawRunner.Labels))
+	for _, label := range rawRunner.Labels {
+		labels = append(labels, label.Name)
+	}
+	labelsJson, err := errors.Convert01(json.Marshal(labels))
+	if err != nil {
+		return nil, err
+	}
+	runner := rawRunner.GithubRunner
+	runner.Labels = datatypes.JSON(labelsJson)
+	return &runner, nil
+}
@@ -0,0 +1,73 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRunner(t *testing.T) {
+	runner, err := extractRunner([]byte(`{
+		"id": 23, "name": "MBP", "os": "macos", "status": "online", "busy": true,
+		"labels": [
+			{"id": 5, "name": "self-hosted", "type": "read-only"},
+			{"id": 7, "name": "gpu", "type": "custom"}
+		]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(23), runner.ID)
+	assert.Equal(t, "MBP", runner.Name)
+	assert.Equal(t, "macos", runner.Os)
+	assert.Equal(t, "online", runner.Status)
+	assert.True(t, runner.Busy)
+	assert.JSONEq(t, `["self-hosted", "gpu"]`, string(runner.Labels))
+}
+
+func TestRunnerScopes(t *testing.T) {
+	assert.Equal(t, []*runnerScope{
+		{Scope: RunnerScopeRepo, Path: "repos/apache/incubator-devlake"},
+		{Scope: RunnerScopeOrg, Path: "orgs/apache"},
+	}, runnerScopes("apache/incubator-devlake"))
+}
+
+func TestIgnoreRunnersForbidden(t *testing.T) {
+	logger := unithelper.DummyLogger()
+	newResponse := func(statusCode int, header http.Header) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+			Request:    &http.Request{URL: &url.URL{Path: "/orgs/apache/actions/runners"}},
+		}
+	}
+
+	// missing admin access
+	assert.Equal(t, api.ErrIgnoreAndContinue, ignoreRunnersForbidden(logger, newResponse(http.StatusForbidden, http.Header{})))
+	// the organization api of a repo owned by a user
+	assert.Equal(t, api.ErrIgnoreAndContinue, ignoreRunnersForbidden(logger, newResponse(http.StatusNotFound, http.Header{})))
+	// the rate limit is left to the retries
+	rateLimited := http.Header{}
+	rateLimited.Set("X-RateLimit-Remaining", "0")
+	assert.Nil(t, ignoreRunnersForbidden(logger, newResponse(http.StatusForbidden, rateLimited)))
+	assert.Nil(t, ignoreRunnersForbidden(logger, newResponse(http.StatusOK, http.Header{})))
+}
@@ -0,0 +1,102 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectWorkflowContentsMeta)
+}
+
+const RAW_WORKFLOW_CONTENT_TABLE = "github_api_workflow_contents"
+
+var CollectWorkflowContentsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Workflow Contents",
+	EntryPoint:       CollectWorkflowContents,
+	EnabledByDefault: true,
+	Description:      "Collect the YAML files of the workflows from Github contents api, does not support either timeFilter or diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{WORKFLOWS_EXTRACTED},
+	ProductTables:    []string{RAW_WORKFLOW_CONTENT_TABLE},
+	SkipOnFail:       true,
+}
+
+type SimpleGithubWorkflow struct {
+	ID   int
+	Path string
+}
+
+func CollectWorkflowContents(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+	logger := taskCtx.GetLogger()
+
+	cursor, err := db.Cursor(
+		dal.Select("id, path"),
+		dal.From(&models.GithubWorkflow{}),
+		dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+	)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubWorkflow{}))
+	if err != nil {
+		return err
+	}
+
+	collector, err := api.NewApiCollector(api.ApiCollectorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_WORKFLOW_CONTENT_TABLE,
+		},
+		ApiClient:      data.ApiClient,
+		Input:          iterator,
+		Incremental:    false,
+		UrlTemplate:    "repos/{{ .Params.Name }}/contents/{{ .Input.Path }}",
+		ResponseParser: api.GetRawMessageDirectFromResponse,
+		AfterResponse: func(res *http.Response) errors.Error {
+			if res.StatusCode == http.StatusUnauthorized {
+				return errors.Unauthorized.New("authentication failed, please check your AccessToken")
+			}
+			// the dynamic workflows, i.e. code scanning, have no file, and the file of a deleted workflow is gone.
+			// 403 means the token has no permission on the contents, the workflows are still usable without them
+			if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusForbidden {
+				logger.Warn(nil, "workflow file not available (%d) at %s. Skipping...", res.StatusCode, res.Request.URL.Path)
+				return api.ErrIgnoreAndContinue
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return collector.Execute()
+}
@@ -0,0 +1,108 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractWorkflowContentsMeta)
+}
+
+var ExtractWorkflowContentsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Workflow Contents",
+	EntryPoint:       ExtractWorkflowContents,
+	EnabledByDefault: true,
+	Description:      "Extract the YAML files of the workflows into the content of tool layer table github_workflows",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_WORKFLOW_CONTENT_TABLE},
+	// the workflows are updated in place, they are created by ExtractWorkflows
+	ProductTables: []string{models.GithubWorkflow{}.TableName()},
+}
+
+type githubRawContent struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+func ExtractWorkflowContents(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_WORKFLOW_CONTENT_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			workflow := &SimpleGithubWorkflow{}
+			err := errors.Convert(json.Unmarshal(row.Input, workflow))
+			if err != nil {
+				return nil, err
+			}
+			content, err := decodeWorkflowContent(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			err = db.UpdateColumn(
+				&models.GithubWorkflow{},
+				"content", content,
+				dal.Where("connection_id = ? AND repo_id = ? AND id = ?",
+					data.Options.ConnectionId, data.Options.GithubId, workflow.ID),
+			)
+			return nil, err
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// decodeWorkflowContent returns the YAML of the file returned by the contents api, which is base64 encoded.
+// The files larger than 1MB come without content, they are stored empty
+func decodeWorkflowContent(raw json.RawMessage) (string, errors.Error) {
+	file := &githubRawContent{}
+	err := errors.Convert(json.Unmarshal(raw, file))
+	if err != nil {
+		return "", err
+	}
+	if file.Encoding != "base64" {
+		return file.Content, nil
+	}
+	// the content is split into lines of 60 characters
+	content, err := errors.Convert01(base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", "")))
+	if err != nil {
+		return "", errors.Default.Wrap(err, "failed to decode the workflow file")
+	}
+	return string(content), nil
+}
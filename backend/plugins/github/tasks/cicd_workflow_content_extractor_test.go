@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeWorkflowContent(t *testing.T) {
+	yaml := "name: CI\non:\n  push:\n    branches: [main]\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(yaml))
+	// GitHub wraps the base64 content
+	raw, _ := json.Marshal(map[string]string{"encoding": "base64", "content": encoded[:10] + "\n" + encoded[10:] + "\n"})
+	content, err := decodeWorkflowContent(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, yaml, content)
+
+	// files larger than 1MB come without content
+	content, err = decodeWorkflowContent(json.RawMessage(`{"encoding": "none", "content": ""}`))
+	assert.Nil(t, err)
+	assert.Empty(t, content)
+
+	_, err = decodeWorkflowContent(json.RawMessage(`{"encoding": "base64", "content": "not base64!"}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,96 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractWorkflowsMeta)
+}
+
+// WORKFLOWS_EXTRACTED is not a table, the collector of the contents of the workflows depends on it to run after the
+// workflows are extracted. It can't depend on the workflows table, which is a product of ExtractWorkflowContents too
+const WORKFLOWS_EXTRACTED = "github_workflows_extracted"
+
+var ExtractWorkflowsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Workflows",
+	EntryPoint:       ExtractWorkflows,
+	EnabledByDefault: true,
+	Description:      "Extract raw workflow data into tool layer table github_workflows",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_WORKFLOW_TABLE},
+	ProductTables:    []string{models.GithubWorkflow{}.TableName(), WORKFLOWS_EXTRACTED},
+}
+
+func ExtractWorkflows(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	// the contents are extracted by ExtractWorkflowContents later, keep the previous ones in the meantime
+	var existing []*models.GithubWorkflow
+	err := db.All(
+		&existing,
+		dal.Select("id, content"),
+		dal.From(&models.GithubWorkflow{}),
+		dal.Where("repo_id = ? AND connection_id = ?", data.Options.GithubId, data.Options.ConnectionId),
+	)
+	if err != nil {
+		return err
+	}
+	contents := make(map[int]string, len(existing))
+	for _, workflow := range existing {
+		contents[workflow.ID] = workflow.Content
+	}
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_WORKFLOW_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			workflow := &models.GithubWorkflow{}
+			err := errors.Convert(json.Unmarshal(row.Data, workflow))
+			if err != nil {
+				return nil, err
+			}
+			workflow.ConnectionId = data.Options.ConnectionId
+			workflow.RepoId = data.Options.GithubId
+			workflow.GithubCreatedAt = api.NormalizeNullableTime(workflow.GithubCreatedAt)
+			workflow.GithubUpdatedAt = api.NormalizeNullableTime(workflow.GithubUpdatedAt)
+			workflow.Content = contents[workflow.ID]
+			return []interface{}{workflow}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
@@ -0,0 +1,178 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertDeploymentsMeta)
+}
+
+var ConvertDeploymentsMeta = plugin.SubTaskMeta{
+	Name:             "Convert Deployments",
+	EntryPoint:       ConvertDeployments,
+	EnabledByDefault: true,
+	Description:      "Convert the successful deployments in tool layer table github_deployments into domain layer table deployment",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		models.GithubDeployment{}.TableName(),
+		models.GithubDeploymentStatus{}.TableName(),
+		models.GithubEnvironment{}.TableName(),
+	},
+	ProductTables: []string{devops.CicdDeploymentCommit{}.TableName(), devops.CICDDeployment{}.TableName()},
+}
+
+var deploymentResultRule = &devops.ResultRule{
+	Success: []string{StatusSuccess, StatusInactive, StatusActive},
+	Failure: []string{StatusError, StatusFailure},
+	Default: devops.RESULT_DEFAULT,
+}
+
+// githubDeploymentWithStatus is a deployment along with its latest status, the deployments api doesn't return the state
+type githubDeploymentWithStatus struct {
+	models.GithubDeployment
+	LatestState       string
+	LatestCreatedDate *time.Time
+}
+
+func ConvertDeployments(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, RAW_DEPLOYMENT_TABLE)
+	cursor, err := db.Cursor(
+		dal.Select("d.*, s.state AS latest_state, s.created_date AS latest_created_date"),
+		dal.From("_tool_github_deployments d"),
+		dal.Join(`INNER JOIN _tool_github_deployment_statuses s
+			ON s.connection_id = d.connection_id AND s.id = (
+				SELECT MAX(s2.id) FROM _tool_github_deployment_statuses s2
+				WHERE s2.connection_id = d.connection_id AND s2.deployment_id = d.database_id
+			)`),
+		dal.Where("d.connection_id = ? AND d.github_id = ?", data.Options.ConnectionId, data.Options.GithubId),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	environments, err := loadEnvironmentNames(db, data)
+	if err != nil {
+		return err
+	}
+	deploymentIdGen := didgen.NewDomainIdGenerator(&models.GithubDeployment{})
+	deploymentScopeIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		InputRowType:       reflect.TypeOf(githubDeploymentWithStatus{}),
+		Input:              cursor,
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			githubDeployment := inputRow.(*githubDeploymentWithStatus)
+			result := devops.GetResult(deploymentResultRule, githubDeployment.LatestState)
+			if result != devops.RESULT_SUCCESS {
+				return nil, nil
+			}
+			finishedDate := githubDeployment.UpdatedDate
+			if githubDeployment.LatestCreatedDate != nil {
+				finishedDate = *githubDeployment.LatestCreatedDate
+			}
+			deploymentCommit := &devops.CicdDeploymentCommit{
+				DomainEntity: domainlayer.DomainEntity{
+					Id: deploymentIdGen.Generate(githubDeployment.ConnectionId, githubDeployment.Id),
+				},
+				CicdScopeId:         deploymentScopeIdGen.Generate(githubDeployment.ConnectionId, githubDeployment.GithubId),
+				Name:                githubDeployment.CommitOid,
+				Result:              result,
+				Status:              devops.STATUS_DONE,
+				OriginalStatus:      githubDeployment.LatestState,
+				Environment:         deploymentEnvironment(environments, githubDeployment.Environment),
+				OriginalEnvironment: githubDeployment.Environment,
+				TaskDatesInfo: devops.TaskDatesInfo{
+					CreatedDate:  githubDeployment.CreatedDate,
+					StartedDate:  &githubDeployment.CreatedDate,
+					FinishedDate: &finishedDate,
+				},
+				CommitSha:    githubDeployment.CommitOid,
+				RefName:      githubDeployment.RefName,
+				RepoId:       deploymentScopeIdGen.Generate(githubDeployment.ConnectionId, githubDeployment.GithubId),
+				RepoUrl:      githubDeployment.RepositoryUrl,
+				DisplayTitle: githubDeployment.Description,
+				Url:          githubDeployment.Url,
+			}
+
+			durationSec := float64(finishedDate.Sub(githubDeployment.CreatedDate).Milliseconds() / 1e3)
+			deploymentCommit.DurationSec = &durationSec
+
+			if data.RegexEnricher != nil {
+				if data.RegexEnricher.ReturnNameIfMatched(devops.ENV_NAME_PATTERN, githubDeployment.Environment) != "" {
+					deploymentCommit.Environment = devops.PRODUCTION
+				}
+			}
+
+			deploymentCommit.CicdDeploymentId = deploymentCommit.Id
+			return []interface{}{
+				deploymentCommit,
+				deploymentCommit.ToDeployment(),
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+// loadEnvironmentNames returns the names of the environments configured in the repo by their lowercase names
+func loadEnvironmentNames(db dal.Dal, data *GithubTaskData) (map[string]string, errors.Error) {
+	var names []string
+	err := db.Pluck("name", &names,
+		dal.From(&models.GithubEnvironment{}),
+		dal.Where("connection_id = ? AND repo_id = ?", data.Options.ConnectionId, data.Options.GithubId),
+	)
+	if err != nil {
+		return nil, err
+	}
+	environments := make(map[string]string, len(names))
+	for _, name := range names {
+		environments[strings.ToLower(name)] = name
+	}
+	return environments, nil
+}
+
+// deploymentEnvironment buckets a deployment into the environment of the repo it was made to. The names of the
+// environments are case-insensitive, the deployments made by the workflows carry the name as written in the workflow,
+// so `Production` and `production` are the same environment. The deployments to an environment that isn't configured,
+// or which was deleted, keep their own name
+func deploymentEnvironment(environments map[string]string, name string) string {
+	if environment, ok := environments[strings.ToLower(name)]; ok {
+		return environment
+	}
+	return name
+}
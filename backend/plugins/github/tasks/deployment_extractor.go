@@ -0,0 +1,112 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractDeploymentsMeta)
+}
+
+var ExtractDeploymentsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Deployments",
+	EntryPoint:       ExtractDeployments,
+	EnabledByDefault: true,
+	Description:      "Extract raw deployment data into tool layer table github_deployments",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_DEPLOYMENT_TABLE},
+	ProductTables:    []string{models.GithubDeployment{}.TableName()},
+}
+
+type GithubApiDeployment struct {
+	Id          uint            `json:"id"`
+	NodeId      string          `json:"node_id"`
+	Sha         string          `json:"sha"`
+	Ref         string          `json:"ref"`
+	Environment string          `json:"environment"`
+	Description string          `json:"description"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func ExtractDeployments(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	// the api returns the api urls only, the links of the deployments are built from the repo
+	repo := &models.GithubRepo{}
+	err := db.First(repo, dal.Where("connection_id = ? AND github_id = ?", data.Options.ConnectionId, data.Options.GithubId))
+	if err != nil && !db.IsErrorNotFound(err) {
+		return err
+	}
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_DEPLOYMENT_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			apiDeployment := &GithubApiDeployment{}
+			err := errors.Convert(json.Unmarshal(row.Data, apiDeployment))
+			if err != nil {
+				return nil, err
+			}
+			githubDeployment := &models.GithubDeployment{
+				ConnectionId: data.Options.ConnectionId,
+				GithubId:     data.Options.GithubId,
+				// the node id is used by the graphql plugin as well, so both generate the same domain ids
+				Id:             apiDeployment.NodeId,
+				DatabaseId:     apiDeployment.Id,
+				CommitOid:      apiDeployment.Sha,
+				RefName:        apiDeployment.Ref,
+				Environment:    apiDeployment.Environment,
+				Description:    apiDeployment.Description,
+				Payload:        string(apiDeployment.Payload),
+				RepositoryName: data.Options.Name,
+				CreatedDate:    apiDeployment.CreatedAt,
+				UpdatedDate:    apiDeployment.UpdatedAt,
+			}
+			if repo.HTMLUrl != "" {
+				githubDeployment.RepositoryUrl = repo.HTMLUrl
+				githubDeployment.Url = strings.TrimSuffix(repo.HTMLUrl, "/") + "/deployments/" + url.PathEscape(apiDeployment.Environment)
+			}
+			return []interface{}{githubDeployment}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
@@ -0,0 +1,130 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectDeploymentStatusesMeta)
+}
+
+const RAW_DEPLOYMENT_STATUS_TABLE = "github_api_deployment_statuses"
+
+// deploymentFinalStates are the states of the deployment statuses which are not followed by another outcome,
+// INACTIVE replaces SUCCESS when a newer deployment to the environment succeeded
+var deploymentFinalStates = []string{StatusSuccess, StatusFailure, StatusError, StatusInactive}
+
+type SimpleGithubDeployment struct {
+	DatabaseId uint
+}
+
+var CollectDeploymentStatusesMeta = plugin.SubTaskMeta{
+	Name:             "Collect Deployment Statuses",
+	EntryPoint:       CollectDeploymentStatuses,
+	EnabledByDefault: true,
+	Description:      "Collect statuses of the deployments from Github api, supports both timeFilter and diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubDeployment{}.TableName()},
+	ProductTables:    []string{RAW_DEPLOYMENT_STATUS_TABLE},
+	SkipOnFail:       true,
+}
+
+func CollectDeploymentStatuses(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	apiCollector, err := api.NewStatefulApiCollector(api.RawDataSubTaskArgs{
+		Ctx: taskCtx,
+		Params: GithubApiParams{
+			ConnectionId: data.Options.ConnectionId,
+			Name:         data.Options.Name,
+		},
+		Table: RAW_DEPLOYMENT_STATUS_TABLE,
+	})
+	if err != nil {
+		return err
+	}
+
+	clauses := []dal.Clause{
+		dal.Select("database_id"),
+		dal.From(&models.GithubDeployment{}),
+		dal.Where(
+			"github_id = ? AND connection_id = ?",
+			data.Options.GithubId, data.Options.ConnectionId,
+		),
+	}
+	if apiCollector.IsIncremental() && apiCollector.GetSince() != nil {
+		// deployments updated since the last collection, or still waiting for their outcome
+		clauses = append(clauses, dal.Where(
+			`(updated_date > ? OR database_id NOT IN (
+				SELECT deployment_id FROM _tool_github_deployment_statuses
+				WHERE github_id = ? AND connection_id = ? AND state IN ?
+			))`,
+			apiCollector.GetSince(), data.Options.GithubId, data.Options.ConnectionId, deploymentFinalStates,
+		))
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	iterator, err := api.NewDalCursorIterator(db, cursor, reflect.TypeOf(SimpleGithubDeployment{}))
+	if err != nil {
+		return err
+	}
+
+	err = apiCollector.InitCollector(api.ApiCollectorArgs{
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Input:       iterator,
+		UrlTemplate: "repos/{{ .Params.Name }}/deployments/{{ .Input.DatabaseId }}/statuses",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			var items []json.RawMessage
+			err := api.UnmarshalResponse(res, &items)
+			if err != nil {
+				return nil, err
+			}
+			return items, nil
+		},
+		// the deployment might have been deleted
+		AfterResponse: ignoreHTTPStatus404,
+	})
+	if err != nil {
+		return err
+	}
+
+	return apiCollector.Execute()
+}
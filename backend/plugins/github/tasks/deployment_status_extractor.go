@@ -0,0 +1,79 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractDeploymentStatusesMeta)
+}
+
+var ExtractDeploymentStatusesMeta = plugin.SubTaskMeta{
+	Name:             "Extract Deployment Statuses",
+	EntryPoint:       ExtractDeploymentStatuses,
+	EnabledByDefault: true,
+	Description:      "Extract raw deployment status data into tool layer table github_deployment_statuses",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_DEPLOYMENT_STATUS_TABLE},
+	ProductTables:    []string{models.GithubDeploymentStatus{}.TableName()},
+}
+
+func ExtractDeploymentStatuses(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_DEPLOYMENT_STATUS_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			deployment := &SimpleGithubDeployment{}
+			err := errors.Convert(json.Unmarshal(row.Input, deployment))
+			if err != nil {
+				return nil, err
+			}
+			status := &models.GithubDeploymentStatus{}
+			err = errors.Convert(json.Unmarshal(row.Data, status))
+			if err != nil {
+				return nil, err
+			}
+			status.ConnectionId = data.Options.ConnectionId
+			status.GithubId = data.Options.GithubId
+			status.DeploymentId = deployment.DatabaseId
+			status.State = strings.ToUpper(status.State)
+			return []interface{}{status}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+func init() {
+	RegisterSubtaskMeta(&CollectEnvironmentsMeta)
+}
+
+const RAW_ENVIRONMENT_TABLE = "github_api_environments"
+
+var CollectEnvironmentsMeta = plugin.SubTaskMeta{
+	Name:             "Collect Environments",
+	EntryPoint:       CollectEnvironments,
+	EnabledByDefault: true,
+	Description:      "Collect the deployment environments of the repo from Github api, does not support either timeFilter or diffSync.",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{},
+	ProductTables:    []string{RAW_ENVIRONMENT_TABLE},
+	SkipOnFail:       true,
+}
+
+func CollectEnvironments(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	collector, err := api.NewApiCollector(api.ApiCollectorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_ENVIRONMENT_TABLE,
+		},
+		ApiClient:   data.ApiClient,
+		PageSize:    100,
+		Incremental: false,
+		UrlTemplate: "repos/{{ .Params.Name }}/environments",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			query.Set("per_page", fmt.Sprintf("%v", reqData.Pager.Size))
+			return query, nil
+		},
+		GetTotalPages: GetTotalPagesFromResponse,
+		// a repo without environments answers with an empty list, the api isn't available for the private repos
+		// of the free plans and answers with a 404 then
+		ResponseParser: parseEnvironmentsResponse,
+		AfterResponse:  ignoreHTTPStatus404,
+	})
+	if err != nil {
+		return err
+	}
+	return collector.Execute()
+}
+
+// parseEnvironmentsResponse returns the environments of a page of the environments api
+func parseEnvironmentsResponse(res *http.Response) ([]json.RawMessage, errors.Error) {
+	body := &struct {
+		Environments []json.RawMessage `json:"environments"`
+	}{}
+	err := api.UnmarshalResponse(res, body)
+	if err != nil {
+		return nil, err
+	}
+	return body.Environments, nil
+}
@@ -0,0 +1,98 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractEnvironmentsMeta)
+}
+
+var ExtractEnvironmentsMeta = plugin.SubTaskMeta{
+	Name:             "Extract Environments",
+	EntryPoint:       ExtractEnvironments,
+	EnabledByDefault: true,
+	Description:      "Extract raw environments data into tool layer table github_environments",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_ENVIRONMENT_TABLE},
+	ProductTables:    []string{models.GithubEnvironment{}.TableName()},
+}
+
+const environmentWaitTimerRule = "wait_timer"
+
+func ExtractEnvironments(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_ENVIRONMENT_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			environment, err := extractEnvironment(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			environment.ConnectionId = data.Options.ConnectionId
+			environment.RepoId = data.Options.GithubId
+			return []interface{}{environment}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractEnvironment parses an environment of the environments api, its protection rules are kept as returned and the
+// minutes of its wait timer are taken out of them
+func extractEnvironment(body json.RawMessage) (*models.GithubEnvironment, errors.Error) {
+	environment := &models.GithubEnvironment{}
+	err := errors.Convert(json.Unmarshal(body, environment))
+	if err != nil {
+		return nil, err
+	}
+	rules := []struct {
+		Type      string `json:"type"`
+		WaitTimer int    `json:"wait_timer"`
+	}{}
+	if len(environment.ProtectionRules) > 0 {
+		err = errors.Convert(json.Unmarshal(environment.ProtectionRules, &rules))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range rules {
+		if rule.Type == environmentWaitTimerRule {
+			environment.WaitTimer = rule.WaitTimer
+		}
+	}
+	return environment, nil
+}
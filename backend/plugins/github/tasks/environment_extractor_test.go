@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractEnvironment(t *testing.T) {
+	environment, err := extractEnvironment([]byte(`{
+		"id": 161088068, "name": "production",
+		"created_at": "2020-11-23T22:00:40Z", "updated_at": "2020-11-23T22:00:40Z",
+		"protection_rules": [
+			{"id": 3736, "type": "wait_timer", "wait_timer": 30},
+			{"id": 3755, "type": "required_reviewers", "reviewers": [{"type": "User", "reviewer": {"login": "octocat"}}]},
+			{"id": 3756, "type": "branch_policy"}
+		]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(161088068), environment.ID)
+	assert.Equal(t, "production", environment.Name)
+	assert.Equal(t, 30, environment.WaitTimer)
+	assert.Contains(t, string(environment.ProtectionRules), "required_reviewers")
+	assert.Equal(t, "2020-11-23T22:00:40Z", environment.GithubCreatedAt.UTC().Format("2006-01-02T15:04:05Z"))
+
+	environment, err = extractEnvironment([]byte(`{"id": 1, "name": "staging", "protection_rules": []}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, environment.WaitTimer)
+
+	// the environments without any protection rule don't return them
+	environment, err = extractEnvironment([]byte(`{"id": 2, "name": "review"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, environment.WaitTimer)
+}
+
+func TestParseEnvironmentsResponse(t *testing.T) {
+	newResponse := func(body string) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	environments, err := parseEnvironmentsResponse(newResponse(`{"total_count": 0}`))
+	assert.Nil(t, err)
+	assert.Empty(t, environments)
+
+	environments, err = parseEnvironmentsResponse(newResponse(`{"total_count": 2, "environments": [{"id": 1}, {"id": 2}]}`))
+	assert.Nil(t, err)
+	assert.Len(t, environments, 2)
+}
+
+func TestDeploymentEnvironment(t *testing.T) {
+	environments := map[string]string{"production": "Production", "staging": "staging"}
+	assert.Equal(t, "Production", deploymentEnvironment(environments, "production"))
+	assert.Equal(t, "Production", deploymentEnvironment(environments, "PRODUCTION"))
+	assert.Equal(t, "staging", deploymentEnvironment(environments, "Staging"))
+	assert.Equal(t, "github-pages", deploymentEnvironment(environments, "github-pages"))
+	assert.Equal(t, "qa", deploymentEnvironment(map[string]string{}, "qa"))
+}
@@ -0,0 +1,238 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeApiResponse is the response fakeApiClient answers a request with
+type fakeApiResponse struct {
+	StatusCode int
+	Body       string
+	Header     http.Header
+}
+
+// fakeApiClient is an in-memory api.RateLimitedApiClient, it answers the requests synchronously with the responses
+// registered by path and page, and a 404 for the others. Like the real client, the after function can skip the
+// response of a request, and a response with an error status fails the collection once the retries are used up,
+// which the fake doesn't simulate
+type fakeApiClient struct {
+	mu        sync.Mutex
+	responses map[string]fakeApiResponse
+	requests  []string
+	after     plugin.ApiClientAfterResponse
+	maxRetry  int
+	err       errors.Error
+}
+
+var _ api.RateLimitedApiClient = (*fakeApiClient)(nil)
+
+func newFakeApiClient() *fakeApiClient {
+	return &fakeApiClient{responses: map[string]fakeApiResponse{}}
+}
+
+func fakeApiRequestKey(path string, page string) string {
+	return fmt.Sprintf("%s?page=%s", strings.TrimPrefix(path, "/"), page)
+}
+
+// respond registers the response of the page of path
+func (c *fakeApiClient) respond(path string, page int, statusCode int, body string) {
+	c.respondWith(path, page, fakeApiResponse{StatusCode: statusCode, Body: body})
+}
+
+// respondWith registers the response of the page of path, along with its headers
+func (c *fakeApiClient) respondWith(path string, page int, response fakeApiResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[fakeApiRequestKey(path, fmt.Sprintf("%d", page))] = response
+}
+
+// lookup returns the response registered for the request, and records the request
+func (c *fakeApiClient) lookup(path string, query url.Values) fakeApiResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := fakeApiRequestKey(path, query.Get("page"))
+	c.requests = append(c.requests, key)
+	response, ok := c.responses[key]
+	if !ok {
+		response = fakeApiResponse{StatusCode: http.StatusNotFound, Body: `{"message": "Not Found"}`}
+	}
+	return response
+}
+
+func (c *fakeApiClient) fail(err errors.Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+func (c *fakeApiClient) DoGetAsync(path string, query url.Values, _ http.Header, handler plugin.ApiAsyncCallback) {
+	response := c.lookup(path, query)
+	header := http.Header{}
+	for name, values := range response.Header {
+		header[name] = values
+	}
+	res := &http.Response{
+		StatusCode: response.StatusCode,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(response.Body)),
+		Request:    &http.Request{URL: &url.URL{Path: "/" + strings.TrimPrefix(path, "/"), RawQuery: query.Encode()}},
+	}
+	if c.after != nil {
+		err := c.after(res)
+		if err == api.ErrIgnoreAndContinue {
+			return
+		}
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		// the after function may have read the body
+		res.Body = io.NopCloser(strings.NewReader(response.Body))
+	}
+	if res.StatusCode >= api.HttpMinStatusRetryCode {
+		c.fail(errors.HttpStatus(res.StatusCode).WrapRaw(&api.RetryExceededError{
+			Method:     http.MethodGet,
+			Path:       path,
+			Query:      query,
+			Retry:      c.maxRetry,
+			StatusCode: res.StatusCode,
+			LastError:  response.Body,
+		}))
+		return
+	}
+	if err := handler(res); err != nil {
+		c.fail(err)
+	}
+}
+
+func (c *fakeApiClient) DoPostAsync(path string, query url.Values, _ interface{}, header http.Header, handler plugin.ApiAsyncCallback) {
+	c.DoGetAsync(path, query, header, handler)
+}
+
+func (c *fakeApiClient) WaitAsync() errors.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *fakeApiClient) HasError() bool {
+	return c.WaitAsync() != nil
+}
+
+func (c *fakeApiClient) NextTick(task func() errors.Error) {
+	if err := task(); err != nil {
+		c.fail(err)
+	}
+}
+
+func (c *fakeApiClient) GetNumOfWorkers() int {
+	return 1
+}
+
+func (c *fakeApiClient) GetAfterFunction() plugin.ApiClientAfterResponse {
+	return c.after
+}
+
+func (c *fakeApiClient) SetAfterFunction(callback plugin.ApiClientAfterResponse) {
+	c.after = callback
+}
+
+func (c *fakeApiClient) Reset(time.Duration) {}
+
+func (c *fakeApiClient) GetTickInterval() time.Duration {
+	return 0
+}
+
+func (c *fakeApiClient) GetMaxRetry() int {
+	return c.maxRetry
+}
+
+func (c *fakeApiClient) SetMaxRetry(maxRetry int) {
+	c.maxRetry = maxRetry
+}
+
+func (c *fakeApiClient) Release() {}
+
+// newFakeJobsCollector returns a collector of the jobs of the runs of repo a/b wired to state like the one of
+// CollectJobs, it collects from client and keeps the raw jobs in memory instead of the database
+func newFakeJobsCollector(t *testing.T, client *fakeApiClient, state *jobCollectionState, runIds ...int64) (*api.ApiCollector, *[]string) {
+	var rawJobs []string
+	var rawJobsMu sync.Mutex
+	mockDal := new(mockdal.Dal)
+	mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil)
+	mockDal.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockDal.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		rawJobsMu.Lock()
+		defer rawJobsMu.Unlock()
+		for _, row := range args.Get(0).([]*api.RawData) {
+			rawJobs = append(rawJobs, string(row.Data))
+		}
+	}).Return(nil)
+
+	input := api.NewQueueIterator()
+	for _, runId := range runIds {
+		input.Push(&SimpleGithubRun{ID: runId})
+	}
+	state.jobsPath = "repos/a/b/actions/runs/%d/jobs"
+	collector, err := api.NewApiCollector(api.ApiCollectorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx:    unithelper.DummySubTaskContext(mockDal),
+			Params: GithubApiParams{ConnectionId: 1, Name: "a/b"},
+			Table:  RAW_JOB_TABLE,
+		},
+		ApiClient:   client,
+		Input:       &partialCollectionIterator{Iterator: input, state: state},
+		PageSize:    1,
+		UrlTemplate: "repos/a/b/actions/runs/{{ .Input.ID }}/jobs",
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			query := url.Values{}
+			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
+			if input, ok := reqData.Input.(*SimpleGithubRun); ok {
+				state.markAttempted(input.ID)
+			}
+			return query, nil
+		},
+		GetTotalPages:     state.totalPages,
+		ResponseParser:    state.parseResponse,
+		AfterResponse:     state.afterResponse,
+		SkipInputOnStatus: jobsSkipRunOnStatus,
+		OnInputSkipped:    state.skipRun,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return collector, &rawJobs
+}
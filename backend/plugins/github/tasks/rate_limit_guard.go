@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// DEFAULT_RATE_LIMIT_MIN_REMAINING is the number of requests left in the primary rate limit below which the
+// requests are paused until the limit is reset
+const DEFAULT_RATE_LIMIT_MIN_REMAINING = 50
+
+// rateLimitGuard pauses the requests once the primary rate limit is almost used up, until it is reset, so the
+// subtasks and the repos collected after it don't all fail. It wraps the transport of the api client, so all the
+// subtasks share it whatever their AfterResponse is. The remaining requests are per token, with several tokens
+// the requests are paused as soon as one of them runs low
+type rateLimitGuard struct {
+	next         http.RoundTripper
+	minRemaining int
+	logger       log.Logger
+	mu           sync.Mutex
+	pauseUntil   time.Time
+	now          func() time.Time
+	sleep        func(ctx context.Context, d time.Duration)
+}
+
+// GuardRateLimit pauses the requests of the api client when less than minRemaining requests are left, 0 disables it
+func GuardRateLimit(apiClient *api.ApiAsyncClient, minRemaining int, logger log.Logger) {
+	if minRemaining <= 0 {
+		return
+	}
+	apiClient.WrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return newRateLimitGuard(next, minRemaining, logger)
+	})
+}
+
+func newRateLimitGuard(next http.RoundTripper, minRemaining int, logger log.Logger) *rateLimitGuard {
+	return &rateLimitGuard{
+		next:         next,
+		minRemaining: minRemaining,
+		logger:       logger,
+		now:          time.Now,
+		sleep: func(ctx context.Context, d time.Duration) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(d):
+			}
+		},
+	}
+}
+
+func (g *rateLimitGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	g.mu.Lock()
+	wait := g.pauseUntil.Sub(g.now())
+	g.mu.Unlock()
+	if wait > 0 {
+		g.sleep(req.Context(), wait)
+	}
+	res, err := g.next.RoundTrip(req)
+	if err == nil {
+		g.observe(res)
+	}
+	return res, err
+}
+
+// observe schedules a pause until the reset of the rate limit when the response tells that it is almost used up
+func (g *rateLimitGuard) observe(res *http.Response) {
+	remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining >= g.minRemaining {
+		return
+	}
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	resetAt := time.Unix(reset, 0)
+	// the limit is reset hourly, a later reset is a clock skew
+	if maxResetAt := g.now().Add(time.Hour); resetAt.After(maxResetAt) {
+		resetAt = maxResetAt
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !resetAt.After(g.pauseUntil) {
+		return
+	}
+	g.pauseUntil = resetAt
+	g.logger.Warn(nil, "only %d requests left in the rate limit, pausing the requests until it is reset at %s",
+		remaining, resetAt.Format(time.RFC3339))
+}
@@ -0,0 +1,98 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRateLimitGuard(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	reset := now.Add(20 * time.Minute)
+	remaining := []int{100, 10, 4999}
+	requests := 0
+	guard := newRateLimitGuard(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Remaining", fmt.Sprint(remaining[requests]))
+		header.Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		requests++
+		return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+	}), 50, unithelper.DummyLogger())
+	guard.now = func() time.Time { return now }
+	var slept []time.Duration
+	guard.sleep = func(ctx context.Context, d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/a/b/actions/runs", nil)
+	for range remaining {
+		_, err := guard.RoundTrip(req)
+		assert.Nil(t, err)
+	}
+	// only the request after the one leaving 10 requests is paused, until the reset
+	assert.Equal(t, []time.Duration{20 * time.Minute}, slept)
+
+	// responses without the headers, i.e. from GitHub Enterprise Server with rate limiting disabled, are not paused
+	guard.observe(&http.Response{Header: http.Header{}})
+	assert.True(t, reset.Equal(guard.pauseUntil))
+}
+
+func TestGuardRateLimit(t *testing.T) {
+	reset := time.Now().Add(20 * time.Minute)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	apiClient := &api.ApiClient{}
+	apiClient.Setup(server.URL, nil, time.Second)
+	asyncClient := &api.ApiAsyncClient{ApiClient: apiClient}
+
+	// disabled
+	GuardRateLimit(asyncClient, 0, unithelper.DummyLogger())
+	assert.Nil(t, asyncClient.GetHttpClient().Transport)
+
+	// the requests sent by the client go through the guard, which pauses the next ones
+	GuardRateLimit(asyncClient, 50, unithelper.DummyLogger())
+	guard, ok := asyncClient.GetHttpClient().Transport.(*rateLimitGuard)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, http.DefaultTransport, guard.next)
+	res, err := asyncClient.Get("rate_limit", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, reset.Unix(), guard.pauseUntil.Unix())
+}
@@ -69,6 +69,10 @@ func ConvertRelease(taskCtx plugin.SubTaskContext) errors.Error {
 		RawDataSubTaskArgs: *rawDataSubTaskArgs,
 		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
 			githubRelease := inputRow.(*models.GithubRelease)
+			if githubRelease.PublishedAt == nil {
+				// the drafts are only kept in the tool table until they are published
+				return nil, nil
+			}
 			release := &devops.CicdRelease{
 				DomainEntity: domainlayer.DomainEntity{
 					Id: releaseIdGen.Generate(githubRelease.ConnectionId, githubRelease.Id),
@@ -0,0 +1,154 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertReleaseDeploymentsMeta)
+}
+
+var ConvertReleaseDeploymentsMeta = plugin.SubTaskMeta{
+	Name:             "Convert Release Deployments",
+	EntryPoint:       ConvertReleaseDeployments,
+	EnabledByDefault: true,
+	Description:      "Convert the published releases in tool layer table github_releases into domain layer table deployment when releasesAsDeployments is set",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{models.GithubRelease{}.TableName(), code.Ref{}.TableName()},
+	ProductTables:    []string{devops.CicdDeploymentCommit{}.TableName(), devops.CICDDeployment{}.TableName()},
+}
+
+// githubReleaseWithTagCommit is a release along with the commit of its tag cloned by gitextractor, the releases of
+// the rest api don't tell their commit
+type githubReleaseWithTagCommit struct {
+	models.GithubRelease
+	TagCommitSha *string
+}
+
+func ConvertReleaseDeployments(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, RAW_API_RELEASE_TABLE)
+	repoIdGen := didgen.NewDomainIdGenerator(&models.GithubRepo{})
+	repoId := repoIdGen.Generate(data.Options.ConnectionId, data.Options.GithubId)
+	cursor, err := db.Cursor(releaseDeploymentsClauses(data.Options, repoId)...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	releaseIdGen := didgen.NewDomainIdGenerator(&models.GithubRelease{})
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		InputRowType:       reflect.TypeOf(githubReleaseWithTagCommit{}),
+		Input:              cursor,
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			release := inputRow.(*githubReleaseWithTagCommit)
+			deploymentCommit := releaseDeploymentCommit(release, releaseIdGen.Generate(release.ConnectionId, release.Id), repoId)
+			if deploymentCommit == nil {
+				logger.Debug("the commit of release %s is unknown, it is not converted into a deployment", release.TagName)
+				return nil, nil
+			}
+			return []interface{}{
+				deploymentCommit,
+				deploymentCommit.ToDeployment(),
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+// releaseDeploymentsClauses selects the published releases of the repo to convert into deployments, along with the
+// commit of their tag. The drafts are never deployments, and neither are the prereleases unless
+// PrereleasesAsDeployments is set. Nothing is selected when ReleasesAsDeployments isn't set, so the deployments
+// converted from the releases before are removed by the converter
+func releaseDeploymentsClauses(op *GithubOptions, repoId string) []dal.Clause {
+	clauses := []dal.Clause{
+		dal.Select("rel.*, refs.commit_sha AS tag_commit_sha"),
+		dal.From("_tool_github_releases rel"),
+		dal.Join(`LEFT JOIN refs ON refs.repo_id = ? AND refs.name = CONCAT('refs/tags/', rel.tag_name)`, repoId),
+		dal.Where("rel.connection_id = ? AND rel.github_id = ?", op.ConnectionId, op.GithubId),
+		dal.Where("rel.is_draft = ? AND rel.published_at IS NOT NULL", false),
+	}
+	if !op.PrereleasesAsDeployments {
+		clauses = append(clauses, dal.Where("rel.is_prerelease = ?", false))
+	}
+	if !op.ReleasesAsDeployments {
+		clauses = append(clauses, dal.Where("1 = 0"))
+	}
+	return clauses
+}
+
+// releaseDeploymentCommit converts a published release into a successful deployment to production of the commit of
+// the release, or of its tag when the release doesn't tell it. Nil is returned when neither is known
+func releaseDeploymentCommit(release *githubReleaseWithTagCommit, id string, repoId string) *devops.CicdDeploymentCommit {
+	commitSha := release.CommitSha
+	if commitSha == "" && release.TagCommitSha != nil {
+		commitSha = *release.TagCommitSha
+	}
+	if commitSha == "" || release.PublishedAt == nil {
+		return nil
+	}
+	name := release.Name
+	if name == "" {
+		name = release.TagName
+	}
+	// the url of the release is the one of the repo followed by /releases/tag/ and the tag
+	repoUrl, _, _ := strings.Cut(release.URL, "/releases/")
+	durationSec := float64(0)
+	return &devops.CicdDeploymentCommit{
+		DomainEntity:        domainlayer.DomainEntity{Id: id},
+		CicdScopeId:         repoId,
+		CicdDeploymentId:    id,
+		Name:                name,
+		DisplayTitle:        name,
+		Url:                 release.URL,
+		Result:              devops.RESULT_SUCCESS,
+		Status:              devops.STATUS_DONE,
+		OriginalStatus:      "published",
+		Environment:         devops.PRODUCTION,
+		OriginalEnvironment: devops.PRODUCTION,
+		TaskDatesInfo: devops.TaskDatesInfo{
+			CreatedDate:  release.CreatedAt,
+			StartedDate:  release.PublishedAt,
+			FinishedDate: release.PublishedAt,
+		},
+		DurationSec: &durationSec,
+		CommitSha:   commitSha,
+		RefName:     release.TagName,
+		RepoId:      repoId,
+		RepoUrl:     repoUrl,
+	}
+}
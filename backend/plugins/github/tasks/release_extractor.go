@@ -0,0 +1,132 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ExtractReleasesMeta)
+}
+
+var ExtractReleasesMeta = plugin.SubTaskMeta{
+	Name:             "Extract Releases",
+	EntryPoint:       ExtractReleases,
+	EnabledByDefault: true,
+	Description:      "Extract raw releases data into tool layer table github_releases",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{RAW_API_RELEASE_TABLE},
+	ProductTables:    []string{models.GithubRelease{}.TableName()},
+}
+
+// commitShaPattern matches the target_commitish of a release created from a commit instead of a branch
+var commitShaPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+type githubApiRelease struct {
+	Id              int        `json:"id"`
+	NodeId          string     `json:"node_id"`
+	TagName         string     `json:"tag_name"`
+	TargetCommitish string     `json:"target_commitish"`
+	Name            string     `json:"name"`
+	Body            string     `json:"body"`
+	Draft           bool       `json:"draft"`
+	Prerelease      bool       `json:"prerelease"`
+	CreatedAt       time.Time  `json:"created_at"`
+	PublishedAt     *time.Time `json:"published_at"`
+	HtmlUrl         string     `json:"html_url"`
+	Author          *struct {
+		Login  string `json:"login"`
+		NodeId string `json:"node_id"`
+	} `json:"author"`
+}
+
+func ExtractReleases(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: GithubApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				Name:         data.Options.Name,
+			},
+			Table: RAW_API_RELEASE_TABLE,
+		},
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			release, err := extractRelease(row.Data)
+			if err != nil {
+				return nil, err
+			}
+			release.ConnectionId = data.Options.ConnectionId
+			release.GithubId = data.Options.GithubId
+			return []interface{}{release}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return extractor.Execute()
+}
+
+// extractRelease parses a release of the releases api into the model shared with the graphql api, identified by its
+// node id like there so both apis store the same rows. The rest api only returns the commit of the release when it
+// was created from a commit, the one of its tag is looked up by the conversion into deployments otherwise
+func extractRelease(body json.RawMessage) (*models.GithubRelease, errors.Error) {
+	apiRelease := &githubApiRelease{}
+	err := errors.Convert(json.Unmarshal(body, apiRelease))
+	if err != nil {
+		return nil, err
+	}
+	// the api has no update date, a release is updated when it is published
+	updatedAt := apiRelease.CreatedAt
+	publishedAt := utils.NilIfZeroTime(apiRelease.PublishedAt)
+	if publishedAt != nil {
+		updatedAt = *publishedAt
+	}
+	release := &models.GithubRelease{
+		Id:           apiRelease.NodeId,
+		DatabaseID:   apiRelease.Id,
+		CreatedAt:    apiRelease.CreatedAt,
+		UpdatedAt:    updatedAt,
+		PublishedAt:  publishedAt,
+		Description:  apiRelease.Body,
+		IsDraft:      apiRelease.Draft,
+		IsPrerelease: apiRelease.Prerelease,
+		Name:         apiRelease.Name,
+		TagName:      apiRelease.TagName,
+		URL:          apiRelease.HtmlUrl,
+	}
+	if commitShaPattern.MatchString(apiRelease.TargetCommitish) {
+		release.CommitSha = apiRelease.TargetCommitish
+	}
+	if apiRelease.Author != nil {
+		release.AuthorName = apiRelease.Author.Login
+		release.AuthorID = apiRelease.Author.NodeId
+	}
+	return release, nil
+}
@@ -0,0 +1,125 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRelease(t *testing.T) {
+	release, err := extractRelease([]byte(`{
+		"id": 1, "node_id": "MDc6UmVsZWFzZTE=", "tag_name": "v1.0.0", "target_commitish": "main",
+		"name": "v1.0.0", "body": "Description of the release", "draft": false, "prerelease": false,
+		"created_at": "2013-02-27T19:35:32Z", "published_at": "2013-02-27T19:40:32Z",
+		"html_url": "https://github.com/octocat/Hello-World/releases/tag/v1.0.0",
+		"author": {"login": "octocat", "id": 1, "node_id": "MDQ6VXNlcjE="}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "MDc6UmVsZWFzZTE=", release.Id)
+	assert.Equal(t, 1, release.DatabaseID)
+	assert.Equal(t, "v1.0.0", release.TagName)
+	assert.Equal(t, "Description of the release", release.Description)
+	assert.False(t, release.IsDraft)
+	assert.False(t, release.IsPrerelease)
+	assert.Equal(t, time.Date(2013, 2, 27, 19, 40, 32, 0, time.UTC), release.PublishedAt.UTC())
+	assert.Equal(t, *release.PublishedAt, release.UpdatedAt)
+	assert.Equal(t, "octocat", release.AuthorName)
+	assert.Equal(t, "MDQ6VXNlcjE=", release.AuthorID)
+	// released from a branch, the commit is the one of the tag
+	assert.Empty(t, release.CommitSha)
+
+	release, err = extractRelease([]byte(`{
+		"id": 2, "node_id": "MDc6UmVsZWFzZTI=", "tag_name": "v1.1.0-rc.1",
+		"target_commitish": "6dcb09b5b57875f334f61aebed695e2e4193db5e", "draft": true, "prerelease": true,
+		"created_at": "2013-03-01T10:00:00Z", "published_at": null, "author": null
+	}`))
+	assert.Nil(t, err)
+	assert.True(t, release.IsDraft)
+	assert.True(t, release.IsPrerelease)
+	assert.Nil(t, release.PublishedAt)
+	assert.Equal(t, release.CreatedAt, release.UpdatedAt)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", release.CommitSha)
+	assert.Empty(t, release.AuthorName)
+}
+
+func TestReleaseDeploymentCommit(t *testing.T) {
+	createdAt := time.Date(2013, 2, 27, 19, 35, 32, 0, time.UTC)
+	publishedAt := createdAt.Add(5 * time.Minute)
+	tagCommitSha := "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"
+	release := &githubReleaseWithTagCommit{GithubRelease: models.GithubRelease{
+		Id:          "MDc6UmVsZWFzZTE=",
+		TagName:     "v1.0.0",
+		CreatedAt:   createdAt,
+		PublishedAt: &publishedAt,
+		URL:         "https://github.com/octocat/Hello-World/releases/tag/v1.0.0",
+	}, TagCommitSha: &tagCommitSha}
+
+	deploymentCommit := releaseDeploymentCommit(release, "github:GithubRelease:1:MDc6UmVsZWFzZTE=", "github:GithubRepo:1:1296269")
+	if assert.NotNil(t, deploymentCommit) {
+		assert.Equal(t, "github:GithubRelease:1:MDc6UmVsZWFzZTE=", deploymentCommit.CicdDeploymentId)
+		assert.Equal(t, "v1.0.0", deploymentCommit.Name)
+		assert.Equal(t, tagCommitSha, deploymentCommit.CommitSha)
+		assert.Equal(t, "v1.0.0", deploymentCommit.RefName)
+		assert.Equal(t, devops.RESULT_SUCCESS, deploymentCommit.Result)
+		assert.Equal(t, devops.PRODUCTION, deploymentCommit.Environment)
+		assert.Equal(t, &publishedAt, deploymentCommit.FinishedDate)
+		assert.Equal(t, "https://github.com/octocat/Hello-World", deploymentCommit.RepoUrl)
+	}
+
+	// the commit of the release wins over the one of its tag
+	release.CommitSha = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	assert.Equal(t, release.CommitSha, releaseDeploymentCommit(release, "id", "repo").CommitSha)
+
+	// without any commit, there is nothing deployed
+	release.CommitSha = ""
+	release.TagCommitSha = nil
+	assert.Nil(t, releaseDeploymentCommit(release, "id", "repo"))
+}
+
+func TestReleaseDeploymentsClauses(t *testing.T) {
+	whereClauses := func(clauses []dal.Clause) []string {
+		wheres := []string{}
+		for _, clause := range clauses {
+			if clause.Type == dal.WhereClause {
+				wheres = append(wheres, clause.Data.(dal.DalClause).Expr)
+			}
+		}
+		return wheres
+	}
+	published := []string{
+		"rel.connection_id = ? AND rel.github_id = ?",
+		"rel.is_draft = ? AND rel.published_at IS NOT NULL",
+	}
+
+	// the drafts and the prereleases are excluded by default
+	op := &GithubOptions{ConnectionId: 1, GithubId: 1296269, ReleasesAsDeployments: true}
+	assert.Equal(t, append(published, "rel.is_prerelease = ?"), whereClauses(releaseDeploymentsClauses(op, "github:GithubRepo:1:1296269")))
+	op.PrereleasesAsDeployments = true
+	assert.Equal(t, published, whereClauses(releaseDeploymentsClauses(op, "github:GithubRepo:1:1296269")))
+
+	// nothing is converted unless enabled
+	op = &GithubOptions{ConnectionId: 1, GithubId: 1296269}
+	assert.Equal(t, append(published, "rel.is_prerelease = ?", "1 = 0"), whereClauses(releaseDeploymentsClauses(op, "github:GithubRepo:1:1296269")))
+	assert.NotNil(t, ValidateTaskOptions(&GithubOptions{ConnectionId: 1, Name: "a/b", PrereleasesAsDeployments: true}))
+}
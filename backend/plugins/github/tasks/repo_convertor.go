@@ -51,6 +51,8 @@ type GithubApiRepo struct {
 	CreatedAt   common.Iso8601Time  `json:"created_at"`
 	UpdatedAt   *common.Iso8601Time `json:"updated_at"`
 	CloneUrl    string              `json:"clone_url"`
+	// DefaultBranch is only stored on the scope, see GithubOptions.DefaultBranchOnly
+	DefaultBranch string `json:"default_branch"`
 }
 
 var ConvertRepoMeta = plugin.SubTaskMeta{
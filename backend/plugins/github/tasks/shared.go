@@ -19,6 +19,7 @@ package tasks
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
@@ -92,3 +93,44 @@ func CreateRawDataSubTaskArgs(taskCtx plugin.SubTaskContext, table string) (*api
 	}
 	return RawDataSubTaskArgs, data
 }
+
+// skipActionsDisabled tells whether the collectors of the actions api should skip the repo since CheckActionsAccess
+// found GitHub Actions disabled for it, they would only get a 404 for each of its runs
+func skipActionsDisabled(taskCtx plugin.SubTaskContext, data *GithubTaskData) bool {
+	if data.ActionsDisabled == nil {
+		return false
+	}
+	taskCtx.GetLogger().Info("%s, skipping %s", data.ActionsDisabled.Error(), taskCtx.GetName())
+	return true
+}
+
+// actionsApiPath prepends the ActionsApiPathPrefix of the connection to a path or url template of the Actions API
+func actionsApiPath(data *GithubTaskData, path string) string {
+	prefix := strings.Trim(data.ActionsApiPathPrefix, "/")
+	if prefix == "" {
+		return path
+	}
+	return prefix + "/" + path
+}
+
+// forEachJobsScope runs fn for the repo of the task, then for each of the JobsScopes with the api client of its
+// own connection
+func forEachJobsScope(
+	taskCtx plugin.SubTaskContext,
+	fn func(taskCtx plugin.SubTaskContext, data *GithubTaskData) errors.Error,
+) errors.Error {
+	data := taskCtx.GetData().(*GithubTaskData)
+	err := fn(taskCtx, data)
+	if err != nil {
+		return err
+	}
+	for _, scopeData := range data.JobsScopes {
+		taskCtx.GetLogger().Info("handling the jobs of %s of connection %d",
+			scopeData.Options.Name, scopeData.Options.ConnectionId)
+		err = fn(taskCtx, scopeData)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package tasks
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/apache/incubator-devlake/core/errors"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
@@ -35,6 +36,105 @@ type GithubOptions struct {
 	Name          string                    `json:"name"  mapstructure:"name,omitempty"`
 	FullName      string                    `json:"fullName"  mapstructure:"fullName,omitempty"`
 	ScopeConfig   *models.GithubScopeConfig `mapstructure:"scopeConfig,omitempty" json:"scopeConfig"`
+	// JobsPageSize is the number of jobs requested per page when collecting workflow run jobs, 1-100
+	JobsPageSize int `json:"jobsPageSize" mapstructure:"jobsPageSize,omitempty"`
+	// JobsMaxRetry overrides API_RETRY for the workflow run jobs requests, leave it empty to use API_RETRY
+	JobsMaxRetry *int `json:"jobsMaxRetry" mapstructure:"jobsMaxRetry,omitempty"`
+	// JobsConcurrency limits the number of parallel requests while collecting jobs, leave it empty to use
+	// the number of workers of the api client
+	JobsConcurrency int `json:"jobsConcurrency" mapstructure:"jobsConcurrency,omitempty"`
+	// JobsCreatedDateAfter limits the jobs collection to the runs created after it, i.e. "2024-01-01T00:00:00Z",
+	// leave it empty to collect the jobs of all runs
+	JobsCreatedDateAfter *time.Time `json:"jobsCreatedDateAfter" mapstructure:"jobsCreatedDateAfter,omitempty"`
+	// WorkflowNames limits the jobs collection to the runs of these workflows, matched by the name or the path of
+	// the workflow, i.e. ["CI", ".github/workflows/release.yml"]. Leave it empty to collect the jobs of all workflows
+	WorkflowNames []string `json:"workflowNames" mapstructure:"workflowNames,omitempty"`
+	// CompletedRunsOnly only collects the jobs of the completed runs, the jobs of the runs still in progress are
+	// collected by the first collection after they complete instead of on every one until then
+	CompletedRunsOnly bool `json:"completedRunsOnly" mapstructure:"completedRunsOnly,omitempty"`
+	// ExcludeActors skips the collection of the jobs of the runs triggered by these users, matched against their
+	// login where `*` matches any characters, i.e. ["*[bot]"] for dependabot and renovate. Leave it empty to collect
+	// the jobs of the runs of all users
+	ExcludeActors []string `json:"excludeActors" mapstructure:"excludeActors,omitempty"`
+	// JobConclusions only keeps the extracted jobs with one of these conclusions, i.e. ["success", "failure"],
+	// leave it empty to keep all jobs. The raw data of the other jobs is collected anyway
+	JobConclusions []string `json:"jobConclusions" mapstructure:"jobConclusions,omitempty"`
+	// JobsExtractBatchSize is the number of rows of each table saved at once by the extraction of the jobs, defaults
+	// to DEFAULT_JOBS_EXTRACT_BATCH_SIZE. A larger batch means fewer round trips to the database for the repos with
+	// hundreds of jobs per run
+	JobsExtractBatchSize int `json:"jobsExtractBatchSize" mapstructure:"jobsExtractBatchSize,omitempty"`
+	// JobLogMaxBytes is the size of the log tail kept for a failed job, defaults to 16KB and must fit in a TEXT column
+	JobLogMaxBytes int `json:"jobLogMaxBytes" mapstructure:"jobLogMaxBytes,omitempty"`
+	// CollectAllAttempts collects the jobs of all the attempts of a run instead of the latest one only
+	CollectAllAttempts bool `json:"collectAllAttempts" mapstructure:"collectAllAttempts,omitempty"`
+	// MaxErrorBodyLength is how much of the body of a failed jobs response is kept, defaults to 300
+	MaxErrorBodyLength int `json:"maxErrorBodyLength" mapstructure:"maxErrorBodyLength,omitempty"`
+	// JobsFailureLogLimit is how many failed runs are logged one by one while collecting jobs, the next ones are
+	// logged in batches of as many runs, and the final summary lists as many of them. Defaults to 20
+	JobsFailureLogLimit int `json:"jobsFailureLogLimit" mapstructure:"jobsFailureLogLimit,omitempty"`
+	// DryRun only logs how many requests collecting the jobs takes, no jobs are collected
+	DryRun bool `json:"dryRun" mapstructure:"dryRun,omitempty"`
+	// ForceFullSync discards the incremental state of the jobs collection and collects the jobs of all runs again,
+	// it is meant to be set for a single pipeline
+	ForceFullSync bool `json:"forceFullSync" mapstructure:"forceFullSync,omitempty"`
+	// IncrementalOverlapSeconds moves the start of an incremental jobs collection back, so the runs updated right
+	// before the previous collection ended are collected again, defaults to 600 and 0 disables it
+	IncrementalOverlapSeconds *int `json:"incrementalOverlapSeconds" mapstructure:"incrementalOverlapSeconds,omitempty"`
+	// RateLimitMinRemaining pauses all the requests of the task until the rate limit is reset once less requests than
+	// it are left, defaults to 50 and 0 disables it
+	RateLimitMinRemaining *int `json:"rateLimitMinRemaining" mapstructure:"rateLimitMinRemaining,omitempty"`
+	// DefaultBranchOnly only collects the jobs of the runs on the default branch of the repo, the jobs of all the
+	// runs are collected when the default branch is unknown
+	DefaultBranchOnly bool `json:"defaultBranchOnly" mapstructure:"defaultBranchOnly,omitempty"`
+	// JobsRateLimitMaxWaitSeconds caps the pause after a rate limited jobs request, defaults to 300 seconds
+	JobsRateLimitMaxWaitSeconds int `json:"jobsRateLimitMaxWaitSeconds" mapstructure:"jobsRateLimitMaxWaitSeconds,omitempty"`
+	// MaxRunsPerRun caps the number of runs whose jobs are collected by a pipeline in the RunsOrder, the next pipelines
+	// carry on with the others. Leave it empty to collect the jobs of all the runs at once
+	MaxRunsPerRun int `json:"maxRunsPerRun" mapstructure:"maxRunsPerRun,omitempty"`
+	// JobsIncrementalBy is the time of the runs an incremental collection selects the runs by, JobsIncrementalByUpdatedAt
+	// by default, or JobsIncrementalByRunStartedAt to skip the runs updated for other reasons than a new attempt
+	JobsIncrementalBy string `json:"jobsIncrementalBy" mapstructure:"jobsIncrementalBy,omitempty"`
+	// RunsOrder is the order the jobs of the runs are collected in, JobsRunsNewestFirst by default to collect the jobs
+	// of the recent runs first, or JobsRunsOldestFirst, which JobsWindowDays and MaxRunsPerRun default to
+	RunsOrder string `json:"runsOrder" mapstructure:"runsOrder,omitempty"`
+	// JobsWindowDays collects the jobs of the runs window by window of this many days of their creation, oldest
+	// first. The progress is saved after each window, so an interrupted collection only collects the jobs of its
+	// last window again. Leave it empty to collect the jobs of all the runs at once
+	JobsWindowDays int `json:"jobsWindowDays" mapstructure:"jobsWindowDays,omitempty"`
+	// RunIDs only collects the jobs of these runs of the repo, i.e. to collect them again after fixing a bug. The
+	// incremental state, the checkpoint and the options selecting the runs are ignored and left as they are
+	RunIDs []int64 `json:"runIds" mapstructure:"runIds,omitempty"`
+	// JobsTimingTopN logs the N runs whose jobs took the longest to collect along with their number of pages, to find
+	// out why a collection is slow. Leave it empty to skip tracking the timings
+	JobsTimingTopN int `json:"jobsTimingTopN" mapstructure:"jobsTimingTopN,omitempty"`
+	// ReleasesAsDeployments converts the published releases into successful deployments to production, for the teams
+	// deploying by publishing a release. The drafts are never converted, and neither are the prereleases unless
+	// PrereleasesAsDeployments is set. They are all kept in the tool table
+	ReleasesAsDeployments    bool `json:"releasesAsDeployments" mapstructure:"releasesAsDeployments,omitempty"`
+	PrereleasesAsDeployments bool `json:"prereleasesAsDeployments" mapstructure:"prereleasesAsDeployments,omitempty"`
+	// JobsMaxPagesPerRun caps the pages of jobs collected for a run, defaults to DEFAULT_JOBS_MAX_PAGES_PER_RUN. A run
+	// for which GitHub reports more pages is recorded as failed with the first pages collected, -1 doesn't cap them
+	JobsMaxPagesPerRun int `json:"jobsMaxPagesPerRun" mapstructure:"jobsMaxPagesPerRun,omitempty"`
+	// JobsMaxFailureRatio stops the collection of jobs once more than this fraction of the runs failed, i.e. 0.5,
+	// instead of going on through all the runs during an outage. Leave it empty to never stop early
+	JobsMaxFailureRatio float64 `json:"jobsMaxFailureRatio" mapstructure:"jobsMaxFailureRatio,omitempty"`
+	// JobsScopes are the repos of other connections, i.e. of a GitHub Enterprise Server next to GitHub, whose jobs
+	// are collected, extracted and converted along with the ones of the repo of the task. Their runs must have been
+	// collected already
+	JobsScopes []JobsScope `json:"jobsScopes" mapstructure:"jobsScopes,omitempty"`
+	// KeepRawOnExtract stores the payload of each job in raw_payload of _tool_github_jobs while extracting, to debug
+	// the extraction. The raw table is cleared by the next full collection, the column is not
+	KeepRawOnExtract bool `json:"keepRawOnExtract" mapstructure:"keepRawOnExtract,omitempty"`
+	// ParseJobMatrix stores the matrix values of the jobs named like `build (ubuntu-latest, 1.20)` in matrix of
+	// _tool_github_jobs, i.e. ["ubuntu-latest", "1.20"]
+	ParseJobMatrix bool `json:"parseJobMatrix" mapstructure:"parseJobMatrix,omitempty"`
+}
+
+// JobsScope is a repo of a connection whose jobs are handled along with the ones of the repo of the task
+type JobsScope struct {
+	ConnectionId uint64 `json:"connectionId" mapstructure:"connectionId"`
+	GithubId     int    `json:"githubId" mapstructure:"githubId"`
+	Name         string `json:"name" mapstructure:"name"`
 }
 
 type GithubTaskData struct {
@@ -42,6 +142,15 @@ type GithubTaskData struct {
 	ApiClient     *helper.ApiAsyncClient
 	GraphqlClient *helper.GraphqlAsyncClient
 	RegexEnricher *helper.RegexEnricher
+	// ActionsApiPathPrefix is the ActionsApiPathPrefix of the connection, see actionsApiPath
+	ActionsApiPathPrefix string
+	// JobCollectionResult is set by CollectJobs for the subtasks and the plugin running after it
+	JobCollectionResult *JobCollectionResult
+	// ActionsDisabled is set by CheckActionsAccess when GitHub Actions are disabled for the repo, the collectors of the
+	// actions api skip the repo then
+	ActionsDisabled *ActionsDisabledError
+	// JobsScopes is the task data of each of the JobsScopes of the options, with the api client of its connection
+	JobsScopes []*GithubTaskData
 }
 
 // TODO: avoid touching too many files, should be removed in the future
@@ -79,5 +188,134 @@ func ValidateTaskOptions(op *GithubOptions) errors.Error {
 	if op.ConnectionId == 0 {
 		return errors.BadInput.New("connectionId is invalid")
 	}
+	if op.JobsPageSize == 0 {
+		op.JobsPageSize = DEFAULT_JOBS_PAGE_SIZE
+	}
+	if op.JobsPageSize < 1 || op.JobsPageSize > DEFAULT_JOBS_PAGE_SIZE {
+		return errors.BadInput.New(fmt.Sprintf("jobsPageSize must be between 1 and %d, got %d", DEFAULT_JOBS_PAGE_SIZE, op.JobsPageSize))
+	}
+	if len(op.WorkflowNames) > 0 {
+		workflowNames := make([]string, 0, len(op.WorkflowNames))
+		for _, name := range op.WorkflowNames {
+			if name = strings.TrimSpace(name); name != "" {
+				workflowNames = append(workflowNames, name)
+			}
+		}
+		op.WorkflowNames = workflowNames
+	}
+	if len(op.ExcludeActors) > 0 {
+		excludeActors := make([]string, 0, len(op.ExcludeActors))
+		for _, actor := range op.ExcludeActors {
+			if actor = strings.TrimSpace(actor); actor != "" {
+				excludeActors = append(excludeActors, actor)
+			}
+		}
+		op.ExcludeActors = excludeActors
+	}
+	if op.JobsExtractBatchSize == 0 {
+		op.JobsExtractBatchSize = DEFAULT_JOBS_EXTRACT_BATCH_SIZE
+	}
+	if op.JobsExtractBatchSize < 1 || op.JobsExtractBatchSize > MAX_JOBS_EXTRACT_BATCH_SIZE {
+		return errors.BadInput.New(fmt.Sprintf("jobsExtractBatchSize must be between 1 and %d, got %d",
+			MAX_JOBS_EXTRACT_BATCH_SIZE, op.JobsExtractBatchSize))
+	}
+	if op.JobLogMaxBytes == 0 {
+		op.JobLogMaxBytes = DEFAULT_JOB_LOG_MAX_BYTES
+	}
+	if op.JobLogMaxBytes < 1 || op.JobLogMaxBytes > MAX_JOB_LOG_MAX_BYTES {
+		return errors.BadInput.New(fmt.Sprintf("jobLogMaxBytes must be between 1 and %d, got %d", MAX_JOB_LOG_MAX_BYTES, op.JobLogMaxBytes))
+	}
+	if op.JobsConcurrency < 0 {
+		return errors.BadInput.New(fmt.Sprintf("jobsConcurrency must not be negative, got %d", op.JobsConcurrency))
+	}
+	for _, scope := range op.JobsScopes {
+		if scope.ConnectionId == 0 || scope.GithubId == 0 || scope.Name == "" {
+			return errors.BadInput.New(fmt.Sprintf("connectionId, githubId and name are required by jobsScopes, got %+v", scope))
+		}
+	}
+	if op.JobsTimingTopN < 0 {
+		return errors.BadInput.New(fmt.Sprintf("jobsTimingTopN must not be negative, got %d", op.JobsTimingTopN))
+	}
+	if op.JobsMaxFailureRatio < 0 || op.JobsMaxFailureRatio > 1 {
+		return errors.BadInput.New(fmt.Sprintf("jobsMaxFailureRatio must be between 0 and 1, got %v", op.JobsMaxFailureRatio))
+	}
+	if op.MaxRunsPerRun < 0 {
+		return errors.BadInput.New(fmt.Sprintf("maxRunsPerRun must not be negative, got %d", op.MaxRunsPerRun))
+	}
+	if op.JobsWindowDays < 0 {
+		return errors.BadInput.New(fmt.Sprintf("jobsWindowDays must not be negative, got %d", op.JobsWindowDays))
+	}
+	if op.JobsWindowDays > 0 && op.MaxRunsPerRun > 0 {
+		return errors.BadInput.New("jobsWindowDays and maxRunsPerRun can't be set together")
+	}
+	if op.JobsIncrementalBy != "" && op.JobsIncrementalBy != JobsIncrementalByUpdatedAt &&
+		op.JobsIncrementalBy != JobsIncrementalByRunStartedAt {
+		return errors.BadInput.New(fmt.Sprintf("jobsIncrementalBy must be %s or %s, got %s",
+			JobsIncrementalByUpdatedAt, JobsIncrementalByRunStartedAt, op.JobsIncrementalBy))
+	}
+	if op.RunsOrder != "" && op.RunsOrder != JobsRunsOldestFirst && op.RunsOrder != JobsRunsNewestFirst {
+		return errors.BadInput.New(fmt.Sprintf("runsOrder must be %s or %s, got %s",
+			JobsRunsOldestFirst, JobsRunsNewestFirst, op.RunsOrder))
+	}
+	if op.JobsWindowDays > 0 && op.RunsOrder == JobsRunsNewestFirst {
+		// the windows are collected oldest first
+		return errors.BadInput.New(fmt.Sprintf("jobsWindowDays and runsOrder %s can't be set together", JobsRunsNewestFirst))
+	}
+	if op.MaxRunsPerRun > 0 && op.RunsOrder == JobsRunsNewestFirst {
+		// the older runs would never be reached when more runs than the cap are added between the pipelines
+		return errors.BadInput.New(fmt.Sprintf("maxRunsPerRun and runsOrder %s can't be set together", JobsRunsNewestFirst))
+	}
+	if op.RunsOrder == "" {
+		op.RunsOrder = JobsRunsNewestFirst
+		if op.JobsWindowDays > 0 || op.MaxRunsPerRun > 0 {
+			op.RunsOrder = JobsRunsOldestFirst
+		}
+	}
+	if op.JobsMaxRetry != nil && *op.JobsMaxRetry < 0 {
+		return errors.BadInput.New(fmt.Sprintf("jobsMaxRetry must not be negative, got %d", *op.JobsMaxRetry))
+	}
+	if op.IncrementalOverlapSeconds == nil {
+		overlap := DEFAULT_INCREMENTAL_OVERLAP_SECONDS
+		op.IncrementalOverlapSeconds = &overlap
+	}
+	if *op.IncrementalOverlapSeconds < 0 {
+		return errors.BadInput.New(fmt.Sprintf("incrementalOverlapSeconds must not be negative, got %d", *op.IncrementalOverlapSeconds))
+	}
+	if op.RateLimitMinRemaining == nil {
+		minRemaining := DEFAULT_RATE_LIMIT_MIN_REMAINING
+		op.RateLimitMinRemaining = &minRemaining
+	}
+	if *op.RateLimitMinRemaining < 0 {
+		return errors.BadInput.New(fmt.Sprintf("rateLimitMinRemaining must not be negative, got %d", *op.RateLimitMinRemaining))
+	}
+	if op.MaxErrorBodyLength == 0 {
+		op.MaxErrorBodyLength = DEFAULT_MAX_ERROR_BODY_LENGTH
+	}
+	if op.MaxErrorBodyLength < 0 {
+		return errors.BadInput.New(fmt.Sprintf("maxErrorBodyLength must not be negative, got %d", op.MaxErrorBodyLength))
+	}
+	if op.PrereleasesAsDeployments && !op.ReleasesAsDeployments {
+		return errors.BadInput.New("prereleasesAsDeployments requires releasesAsDeployments")
+	}
+	if op.JobsMaxPagesPerRun == 0 {
+		op.JobsMaxPagesPerRun = DEFAULT_JOBS_MAX_PAGES_PER_RUN
+	}
+	if op.JobsMaxPagesPerRun < -1 {
+		return errors.BadInput.New(fmt.Sprintf("jobsMaxPagesPerRun must be positive, or -1 to not cap the pages, got %d",
+			op.JobsMaxPagesPerRun))
+	}
+	if op.JobsFailureLogLimit == 0 {
+		op.JobsFailureLogLimit = DEFAULT_JOBS_FAILURE_LOG_LIMIT
+	}
+	if op.JobsFailureLogLimit < 0 {
+		return errors.BadInput.New(fmt.Sprintf("jobsFailureLogLimit must not be negative, got %d", op.JobsFailureLogLimit))
+	}
+	if op.JobsRateLimitMaxWaitSeconds == 0 {
+		op.JobsRateLimitMaxWaitSeconds = DEFAULT_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS
+	}
+	if op.JobsRateLimitMaxWaitSeconds < 1 || op.JobsRateLimitMaxWaitSeconds > MAX_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS {
+		return errors.BadInput.New(fmt.Sprintf("jobsRateLimitMaxWaitSeconds must be between 1 and %d, got %d",
+			MAX_JOBS_RATE_LIMIT_MAX_WAIT_SECONDS, op.JobsRateLimitMaxWaitSeconds))
+	}
 	return nil
 }
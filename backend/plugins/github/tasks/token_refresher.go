@@ -0,0 +1,89 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tasks
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// tokenRefresher sends the requests rejected with an expired installation token of a GitHub App again, once, with a
+// new token. A long pipeline outlives the token which expires after an hour. It wraps the transport of the api client,
+// so all the subtasks share it whatever their AfterResponse is
+type tokenRefresher struct {
+	next http.RoundTripper
+	// refresh mints a new token when the rejected request was sent with the current one
+	refresh func(res *http.Response) errors.Error
+	// authenticate sets the current token on the request sent again
+	authenticate func(req *http.Request) errors.Error
+	logger       log.Logger
+}
+
+// RefreshExpiredToken sends the requests of the api client rejected with a 401 again with a new token, refresh is nil
+// for the connections whose token can't be refreshed
+func RefreshExpiredToken(
+	apiClient *api.ApiAsyncClient,
+	refresh func(res *http.Response) errors.Error,
+	authenticate func(req *http.Request) errors.Error,
+	logger log.Logger,
+) {
+	if refresh == nil {
+		return
+	}
+	apiClient.WrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return &tokenRefresher{next: next, refresh: refresh, authenticate: authenticate, logger: logger}
+	})
+}
+
+func (r *tokenRefresher) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// the body was consumed, the request can't be sent again
+		return res, nil
+	}
+	if res.Request == nil {
+		res.Request = req
+	}
+	if refreshErr := r.refresh(res); refreshErr != nil {
+		r.logger.Warn(refreshErr, "failed to refresh the token after a 401 response for %s", req.URL.Path)
+		return res, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return res, nil
+		}
+	}
+	if authErr := r.authenticate(retry); authErr != nil {
+		return res, nil
+	}
+	if retry.Header.Get("Authorization") == req.Header.Get("Authorization") {
+		// the token was not the expired one, the 401 is for another reason
+		return res, nil
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+	return r.next.RoundTrip(retry)
+}
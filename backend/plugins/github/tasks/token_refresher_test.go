@@ -0,0 +1,124 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tasks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshExpiredToken(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	var mints int32
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/installations/1/access_tokens" {
+			// the first token expires right away
+			_, _ = fmt.Fprintf(w, `{"token": "token-%d"}`, atomic.AddInt32(&mints, 1))
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	conn := &models.GithubConn{}
+	conn.AuthMethod = models.AppKey
+	conn.InstallationID = 1
+	conn.AppId = "1"
+	conn.SecretKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+	mintClient := &api.ApiClient{}
+	mintClient.Setup(server.URL, nil, time.Second)
+	assert.Nil(t, conn.PrepareApiClient(mintClient))
+
+	apiClient := &api.ApiClient{}
+	apiClient.Setup(server.URL, nil, time.Second)
+	apiClient.SetAuthFunction(conn.SetupAuthentication)
+	asyncClient := &api.ApiAsyncClient{ApiClient: apiClient}
+	refresh := func(res *http.Response) errors.Error {
+		_, err := conn.RefreshInstallationToken(mintClient, res.Request.Header.Get("Authorization"))
+		return err
+	}
+	RefreshExpiredToken(asyncClient, refresh, conn.SetupAuthentication, unithelper.DummyLogger())
+
+	// the requests of any subtask rejected in parallel with the expired token refresh it once, and are sent again
+	var wg sync.WaitGroup
+	for runId := 1; runId <= 10; runId++ {
+		wg.Add(1)
+		go func(runId int) {
+			defer wg.Done()
+			res, err := asyncClient.Get(fmt.Sprintf("repos/a/b/actions/runs/%d/artifacts", runId), nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}(runId)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&mints))
+	assert.Equal(t, int32(20), atomic.LoadInt32(&requests))
+
+	// the other connections have no token to refresh
+	apiClient = &api.ApiClient{}
+	apiClient.Setup(server.URL, nil, time.Second)
+	asyncClient = &api.ApiAsyncClient{ApiClient: apiClient}
+	RefreshExpiredToken(asyncClient, nil, conn.SetupAuthentication, unithelper.DummyLogger())
+	assert.Nil(t, asyncClient.GetHttpClient().Transport)
+}
+
+func TestRefreshExpiredTokenOtherUnauthorized(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	apiClient := &api.ApiClient{}
+	apiClient.Setup(server.URL, nil, time.Second)
+	asyncClient := &api.ApiAsyncClient{ApiClient: apiClient}
+	authenticate := func(req *http.Request) errors.Error {
+		req.Header.Set("Authorization", "Bearer token-1")
+		return nil
+	}
+	apiClient.SetAuthFunction(authenticate)
+	// the token was not refreshed, i.e. it was revoked, the 401 is returned as is
+	RefreshExpiredToken(asyncClient, func(res *http.Response) errors.Error { return nil }, authenticate, unithelper.DummyLogger())
+
+	res, err := asyncClient.Get("repos/a/b/actions/runs", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
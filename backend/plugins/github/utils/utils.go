@@ -91,7 +91,8 @@ func GetPagingFromLinkHeader(link string) (PagingInfo, errors.Error) {
 		First: 1,
 	}
 	linksArray := strings.Split(link, ",")
-	pattern1 := regexp.MustCompile(`page=*[0-9]+`)
+	// the page must be a parameter on its own, per_page comes before it in the links of GitHub
+	pattern1 := regexp.MustCompile(`[?&]page=[0-9]+`)
 	pattern2 := regexp.MustCompile(`rel="*[a-z]+`)
 	if len(linksArray) < 2 {
 		return result, errors.Default.New("the link string provided is invalid. There is likely no next page of data to fetch")
@@ -102,7 +103,7 @@ func GetPagingFromLinkHeader(link string) (PagingInfo, errors.Error) {
 		loc2 := pattern2.FindIndex(content)
 		if len(loc1) >= 2 && len(loc2) >= 2 {
 			pageNumberSubstring := string(content[loc1[0]:loc1[1]])
-			pageNumberString := strings.Replace(pageNumberSubstring, `page=`, ``, 1)
+			pageNumberString := strings.Replace(pageNumberSubstring[1:], `page=`, ``, 1)
 			pageNameSubstring := string(content[loc2[0]:loc2[1]])
 			pageNameString := strings.Replace(pageNameSubstring, `rel="`, ``, 1)
 
@@ -47,6 +47,17 @@ func TestParseLinkHeader(t *testing.T) {
 	}
 	assert.Equal(t, result, pagingExpected)
 }
+func TestParseLinkHeaderWithPerPage(t *testing.T) {
+	linkHeaderFull := `<https://api.github.com/repositories/1/actions/runs/42/jobs?per_page=100&page=2>; rel="next", ` +
+		`<https://api.github.com/repositories/1/actions/runs/42/jobs?per_page=100&page=4>; rel="last"`
+	result, err := GetPagingFromLinkHeader(linkHeaderFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, result.Next, 2)
+	assert.Equal(t, result.Last, 4)
+}
+
 func TestParseLinkHeaderEmptyString(t *testing.T) {
 	fmt.Println("INFO >>> Handles empty link string")
 	var pagingExpected = PagingInfo{
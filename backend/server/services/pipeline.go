@@ -547,9 +547,10 @@ func RerunPipeline(pipelineId uint64, task *models.Task) (tasks []*models.Task,
 		// create new task
 		rerunTask, err := createTask(&models.NewTask{
 			PipelineTask: &models.PipelineTask{
-				Plugin:   t.Plugin,
-				Subtasks: t.Subtasks,
-				Options:  t.Options,
+				Plugin:     t.Plugin,
+				Subtasks:   t.Subtasks,
+				Options:    t.Options,
+				SkipOnFail: t.SkipOnFail,
 			},
 			PipelineId:  t.PipelineId,
 			PipelineRow: t.PipelineRow,
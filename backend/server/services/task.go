@@ -46,6 +46,7 @@ func createTask(newTask *models.NewTask, tx dal.Transaction) (*models.Task, erro
 		Plugin:      newTask.Plugin,
 		Subtasks:    newTask.Subtasks,
 		Options:     newTask.Options,
+		SkipOnFail:  newTask.SkipOnFail,
 		Status:      models.TASK_CREATED,
 		Message:     "",
 		PipelineId:  newTask.PipelineId,